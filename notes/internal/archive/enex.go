@@ -0,0 +1,278 @@
+package archive
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const enexTimeLayout = "20060102T150405Z"
+
+var (
+	extraBlankLines = regexp.MustCompile(`\n{3,}`)
+	spaceRuns       = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+type enexNote struct {
+	Title   string   `xml:"title"`
+	Content string   `xml:"content"`
+	Created string   `xml:"created"`
+	Updated string   `xml:"updated"`
+	Tags    []string `xml:"tag"`
+}
+
+func ReadENEX(r io.Reader) ([]models.ImportItem, error) {
+	decoder := xml.NewDecoder(r)
+
+	var items []models.ImportItem
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImport, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+
+		var source enexNote
+		if err := decoder.DecodeElement(&source, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImport, err)
+		}
+
+		item := models.ImportItem{Source: fmt.Sprintf("note[%d]", len(items))}
+		if item.Note, err = enexToNote(source); err != nil {
+			item.Error = err.Error()
+		}
+		items = append(items, item)
+	}
+
+	if items == nil {
+		return nil, fmt.Errorf("%w: в файле нет заметок", errors.ErrInvalidImport)
+	}
+
+	return items, nil
+}
+
+func enexToNote(source enexNote) (models.Note, error) {
+	content, err := ENMLToMarkdown(source.Content)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	note := models.Note{
+		Name:    strings.TrimSpace(source.Title),
+		Content: content,
+		Tags:    source.Tags,
+	}
+	if created, err := time.Parse(enexTimeLayout, source.Created); err == nil {
+		note.CreatedAt = created
+	}
+	if updated, err := time.Parse(enexTimeLayout, source.Updated); err == nil {
+		note.UpdatedAt = updated
+	}
+
+	return note, nil
+}
+
+type listState struct {
+	ordered bool
+	counter int
+}
+
+type enmlConverter struct {
+	builder strings.Builder
+	lists   []listState
+	links   []string
+	inPre   bool
+}
+
+// ENML — это XHTML с тегами en-*, поэтому его разбирает нестрогий
+// XML-декодер с HTML-сущностями; незнакомые теги просто пропускаются.
+func ENMLToMarkdown(enml string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(enml))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	converter := &enmlConverter{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: ENML: %v", errors.ErrInvalidImport, err)
+		}
+
+		switch typed := token.(type) {
+		case xml.StartElement:
+			converter.start(typed)
+		case xml.EndElement:
+			converter.end(typed)
+		case xml.CharData:
+			converter.text(string(typed))
+		}
+	}
+
+	markdown := extraBlankLines.ReplaceAllString(converter.builder.String(), "\n\n")
+	return strings.TrimSpace(markdown), nil
+}
+
+func (c *enmlConverter) start(element xml.StartElement) {
+	switch name := strings.ToLower(element.Name.Local); name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.blankLine()
+		c.write(strings.Repeat("#", int(name[1]-'0')) + " ")
+	case "p", "div", "tr":
+		c.newLine()
+	case "br":
+		c.write("\n")
+	case "b", "strong":
+		c.write("**")
+	case "i", "em":
+		c.write("_")
+	case "s", "strike", "del":
+		c.write("~~")
+	case "code":
+		if !c.inPre {
+			c.write("`")
+		}
+	case "pre":
+		c.blankLine()
+		c.write("```\n")
+		c.inPre = true
+	case "blockquote":
+		c.newLine()
+		c.write("> ")
+	case "hr":
+		c.blankLine()
+		c.write("---\n")
+	case "a":
+		c.links = append(c.links, attr(element, "href"))
+		c.write("[")
+	case "ul", "ol":
+		c.newLine()
+		c.lists = append(c.lists, listState{ordered: name == "ol"})
+	case "li":
+		c.newLine()
+		c.listMarker()
+	case "td", "th":
+		c.write("| ")
+	case "en-todo":
+		if attr(element, "checked") == "true" {
+			c.write("[x] ")
+		} else {
+			c.write("[ ] ")
+		}
+	case "en-media":
+		c.write(fmt.Sprintf("[вложение %s]", attr(element, "type")))
+	}
+}
+
+func (c *enmlConverter) end(element xml.EndElement) {
+	switch strings.ToLower(element.Name.Local) {
+	case "h1", "h2", "h3", "h4", "h5", "h6", "blockquote":
+		c.blankLine()
+	case "p", "div", "li", "tr":
+		c.newLine()
+	case "b", "strong":
+		c.write("**")
+	case "i", "em":
+		c.write("_")
+	case "s", "strike", "del":
+		c.write("~~")
+	case "code":
+		if !c.inPre {
+			c.write("`")
+		}
+	case "pre":
+		c.newLine()
+		c.write("```\n\n")
+		c.inPre = false
+	case "a":
+		href := ""
+		if len(c.links) > 0 {
+			href = c.links[len(c.links)-1]
+			c.links = c.links[:len(c.links)-1]
+		}
+		c.write("](" + href + ")")
+	case "ul", "ol":
+		if len(c.lists) > 0 {
+			c.lists = c.lists[:len(c.lists)-1]
+		}
+		c.newLine()
+	case "td", "th":
+		c.write(" ")
+	}
+}
+
+func (c *enmlConverter) text(text string) {
+	if c.inPre {
+		c.write(text)
+		return
+	}
+
+	text = spaceRuns.ReplaceAllString(text, " ")
+	if c.atLineStart() {
+		text = strings.TrimLeft(text, " ")
+	}
+	c.write(text)
+}
+
+func (c *enmlConverter) listMarker() {
+	depth := len(c.lists)
+	if depth == 0 {
+		c.write("- ")
+		return
+	}
+
+	c.write(strings.Repeat("  ", depth-1))
+	list := &c.lists[depth-1]
+	if list.ordered {
+		list.counter++
+		c.write(fmt.Sprintf("%d. ", list.counter))
+	} else {
+		c.write("- ")
+	}
+}
+
+func (c *enmlConverter) write(text string) {
+	c.builder.WriteString(text)
+}
+
+func (c *enmlConverter) atLineStart() bool {
+	current := c.builder.String()
+	return current == "" || strings.HasSuffix(current, "\n")
+}
+
+func (c *enmlConverter) newLine() {
+	if !c.atLineStart() {
+		c.write("\n")
+	}
+}
+
+func (c *enmlConverter) blankLine() {
+	c.newLine()
+	if current := c.builder.String(); current != "" && !strings.HasSuffix(current, "\n\n") {
+		c.write("\n")
+	}
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, attribute := range element.Attr {
+		if strings.EqualFold(attribute.Name.Local, name) {
+			return attribute.Value
+		}
+	}
+	return ""
+}
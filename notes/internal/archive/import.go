@@ -0,0 +1,301 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MaxImportFileSize = 10 << 20
+	// MaxImportTotalSize ограничивает суммарный распакованный размер архива:
+	// хорошо сжимаемые файлы помещаются в допустимый размер загрузки, но
+	// читаются в память целиком.
+	MaxImportTotalSize = 100 << 20
+)
+
+var exportSuffix = regexp.MustCompile(`-[0-9a-f]{24}$`)
+
+func ReadZip(r io.ReaderAt, size int64) ([]models.ImportItem, error) {
+	archiveReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImport, err)
+	}
+
+	// Число заметок и размер проверяются по заголовкам до чтения файлов;
+	// заголовкам нельзя доверять, поэтому прочитанное считается ещё раз.
+	files := make([]*zip.File, 0, len(archiveReader.File))
+	var totalSize uint64
+	for _, file := range archiveReader.File {
+		if file.FileInfo().IsDir() || !isMarkdownFile(file.Name) {
+			continue
+		}
+		files = append(files, file)
+		totalSize += min(file.UncompressedSize64, MaxImportFileSize+1)
+	}
+	if len(files) > models.MaxImportItems {
+		return nil, fmt.Errorf("%w: заметок %d, допустимо не больше %d", errors.ErrInvalidImport, len(files), models.MaxImportItems)
+	}
+	if totalSize > MaxImportTotalSize {
+		return nil, fmt.Errorf("%w: распакованный архив больше %d байт", errors.ErrImportTooLarge, MaxImportTotalSize)
+	}
+
+	items := make([]models.ImportItem, 0, len(files))
+	var readSize int
+	for _, file := range files {
+		item := models.ImportItem{Source: file.Name}
+		data, err := readZipFile(file)
+		readSize += len(data)
+		if readSize > MaxImportTotalSize {
+			return nil, fmt.Errorf("%w: распакованный архив больше %d байт", errors.ErrImportTooLarge, MaxImportTotalSize)
+		}
+		if err != nil {
+			item.Error = err.Error()
+		} else if item.Note, err = ParseMarkdown(file.Name, data); err != nil {
+			item.Error = err.Error()
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func ReadJSON(r io.Reader) ([]models.ImportItem, error) {
+	var document Document
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImport, err)
+	}
+
+	if document.Format != "" && document.Format != FormatName {
+		return nil, fmt.Errorf("%w: формат %q", errors.ErrInvalidImport, document.Format)
+	}
+	if document.Version < 1 || document.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: версия %d не поддерживается", errors.ErrInvalidImport, document.Version)
+	}
+
+	items := make([]models.ImportItem, 0, len(document.Notes))
+	for i, note := range document.Notes {
+		items = append(items, models.ImportItem{
+			Source: fmt.Sprintf("notes[%d]", i),
+			Note: models.Note{
+				Name:       note.Name,
+				Content:    note.Content,
+				Tags:       note.Tags,
+				NotebookID: note.NotebookID,
+				Pinned:     note.Pinned,
+				Archived:   note.Archived,
+				CreatedAt:  note.CreatedAt,
+				UpdatedAt:  note.UpdatedAt,
+			},
+		})
+	}
+
+	return items, nil
+}
+
+// Front matter разбирается упрощённо: поддерживаются скалярные значения,
+// JSON/flow-списки и блочные списки из строк «- значение».
+func ParseMarkdown(filename string, data []byte) (models.Note, error) {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(data, []byte("\ufeff"))), "\r\n", "\n")
+
+	var note models.Note
+	if rest, ok := strings.CutPrefix(text, frontMatterDelimiter+"\n"); ok {
+		end := strings.Index(rest, "\n"+frontMatterDelimiter)
+		if end < 0 {
+			return note, fmt.Errorf("%w: front matter не закрыт", errors.ErrInvalidImport)
+		}
+
+		if err := applyFrontMatter(&note, parseFrontMatter(rest[:end])); err != nil {
+			return note, err
+		}
+
+		text = strings.TrimPrefix(rest[end+len(frontMatterDelimiter)+1:], "\n")
+		text = strings.TrimPrefix(text, "\n")
+	}
+
+	note.Content = text
+	if strings.TrimSpace(note.Name) == "" {
+		name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+		note.Name = exportSuffix.ReplaceAllString(name, "")
+	}
+
+	return note, nil
+}
+
+func isMarkdownFile(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasPrefix(name, "__MACOSX/") {
+		return false
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".txt":
+		return true
+	default:
+		return false
+	}
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > MaxImportFileSize {
+		return nil, fmt.Errorf("%w: файл больше %d байт", errors.ErrInvalidImport, MaxImportFileSize)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImport, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, MaxImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImport, err)
+	}
+	if len(data) > MaxImportFileSize {
+		return nil, fmt.Errorf("%w: файл больше %d байт", errors.ErrInvalidImport, MaxImportFileSize)
+	}
+
+	return data, nil
+}
+
+func parseFrontMatter(block string) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	var listKey string
+	for _, line := range strings.Split(block, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && listKey != "" {
+			list, _ := fields[listKey].([]interface{})
+			fields[listKey] = append(list, unquote(item))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		listKey = ""
+		if value == "" {
+			listKey = key
+			fields[key] = []interface{}{}
+			continue
+		}
+		fields[key] = parseScalar(value)
+	}
+
+	return fields
+}
+
+func parseScalar(value string) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		return parsed
+	}
+
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		var list []interface{}
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, unquote(item))
+			}
+		}
+		return list
+	}
+
+	return unquote(value)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func applyFrontMatter(note *models.Note, fields map[string]interface{}) error {
+	for key, value := range fields {
+		var err error
+		switch key {
+		case "name", "title":
+			note.Name = fmt.Sprint(value)
+		case "tags":
+			note.Tags = stringList(value)
+		case "notebook_id":
+			note.NotebookID = fmt.Sprint(value)
+		case "created_at", "created", "date":
+			note.CreatedAt, err = parseTime(value)
+		case "updated_at", "updated":
+			note.UpdatedAt, err = parseTime(value)
+		case "pinned":
+			note.Pinned, err = parseBool(value)
+		case "archived":
+			note.Archived, err = parseBool(value)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: поле %s: %v", errors.ErrInvalidImport, key, err)
+		}
+	}
+	return nil
+}
+
+func stringList(value interface{}) []string {
+	switch typed := value.(type) {
+	case []interface{}:
+		list := make([]string, 0, len(typed))
+		for _, item := range typed {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	case string:
+		var list []string
+		for _, item := range strings.Split(typed, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func parseTime(value interface{}) (time.Time, error) {
+	raw := fmt.Sprint(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("некорректная дата %q", raw)
+}
+
+func parseBool(value interface{}) (bool, error) {
+	if typed, ok := value.(bool); ok {
+		return typed, nil
+	}
+
+	switch raw := strings.ToLower(fmt.Sprint(value)); raw {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	default:
+		return strconv.ParseBool(raw)
+	}
+}
@@ -0,0 +1,60 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"testing"
+)
+
+func buildZip(t *testing.T, count int, size int) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	content := bytes.Repeat([]byte("a"), size)
+	for i := 0; i < count; i++ {
+		file, err := writer.Create(fmt.Sprintf("note-%d.md", i))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := file.Write(content); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestReadZipLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		size  int
+		want  error
+	}{
+		{"в пределах лимитов", 3, 10, nil},
+		{"слишком много заметок", models.MaxImportItems + 1, 1, errors.ErrInvalidImport},
+		{"слишком большой распакованный размер", MaxImportTotalSize/MaxImportFileSize + 1, MaxImportFileSize, errors.ErrImportTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := buildZip(t, test.count, test.size)
+			items, err := ReadZip(bytes.NewReader(data), int64(len(data)))
+			if test.want == nil {
+				if err != nil || len(items) != test.count {
+					t.Fatalf("ReadZip: %d заметок, ошибка %v", len(items), err)
+				}
+				return
+			}
+			if !stdErrors.Is(err, test.want) {
+				t.Fatalf("ReadZip: %v, ожидалась %v", err, test.want)
+			}
+		})
+	}
+}
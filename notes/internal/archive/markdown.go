@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"notes/internal/models"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	FormatName    = "notes-export"
+	FormatVersion = 1
+
+	frontMatterDelimiter = "---"
+	maxSlugLength        = 64
+	defaultSlug          = "note"
+)
+
+type Document struct {
+	Format     string        `json:"format"`
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	AuthorID   int           `json:"author_id"`
+	Notes      []models.Note `json:"notes"`
+}
+
+func MarkdownFilename(note models.Note) string {
+	return fmt.Sprintf("%s-%s.md", slug(note.Name), note.ID)
+}
+
+// Значения front matter пишутся как JSON-строки и списки: это валидный
+// YAML, и не нужно вручную экранировать кавычки и переводы строк.
+func WriteMarkdown(w io.Writer, note models.Note) error {
+	buffered := bufio.NewWriter(w)
+
+	fmt.Fprintln(buffered, frontMatterDelimiter)
+	writeField(buffered, "id", note.ID)
+	writeField(buffered, "name", note.Name)
+	if !note.CreatedAt.IsZero() {
+		writeField(buffered, "created_at", note.CreatedAt.Format(time.RFC3339Nano))
+	}
+	if !note.UpdatedAt.IsZero() {
+		writeField(buffered, "updated_at", note.UpdatedAt.Format(time.RFC3339Nano))
+	}
+	tags := note.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	writeField(buffered, "tags", tags)
+	if note.NotebookID != "" {
+		writeField(buffered, "notebook_id", note.NotebookID)
+	}
+	if note.Pinned {
+		writeField(buffered, "pinned", true)
+	}
+	if note.Archived {
+		writeField(buffered, "archived", true)
+	}
+	fmt.Fprintln(buffered, frontMatterDelimiter)
+	fmt.Fprintln(buffered)
+	buffered.WriteString(note.Content)
+
+	return buffered.Flush()
+}
+
+func writeField(w io.Writer, key string, value interface{}) {
+	encoded, _ := json.Marshal(value)
+	fmt.Fprintf(w, "%s: %s\n", key, encoded)
+}
+
+func slug(name string) string {
+	var builder strings.Builder
+	dash := false
+	length := 0
+	for _, r := range strings.ToLower(name) {
+		if length >= maxSlugLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+			dash = false
+			length++
+			continue
+		}
+		if !dash && builder.Len() > 0 {
+			builder.WriteRune('-')
+			dash = true
+			length++
+		}
+	}
+
+	result := strings.Trim(builder.String(), "-")
+	if result == "" {
+		return defaultSlug
+	}
+	return result
+}
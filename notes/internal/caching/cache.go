@@ -0,0 +1,28 @@
+package caching
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ModeRedis  = "redis"
+	ModeMemory = "memory"
+	ModeTiered = "tiered"
+)
+
+var ErrMiss = errors.New("ключ не найден в кэше")
+
+// Cache — кэш заметок. Группа — общий префикс ключей, например всех страниц
+// списка автора: InvalidateGroups сбрасывает их разом, а Set получает группы,
+// чтобы Redis нашёл ключи без SCAN. Delete сдвигает поколение ключа:
+// значение, прочитанное из базы до удаления, SetIfGeneration уже не запишет.
+type Cache interface {
+	Get(key string) (string, error)
+	Set(key string, value string, ttl time.Duration, groups ...string) error
+	Delete(keys ...string) error
+	InvalidateGroups(groups ...string) error
+	Generation(key string) (string, error)
+	SetIfGeneration(key string, value string, ttl time.Duration, generation string) (bool, error)
+	Close() error
+}
@@ -3,22 +3,47 @@ package caching
 import (
 	"fmt"
 	"notes/internal/config"
+	"time"
 
 	"github.com/go-redis/redis"
 )
 
-func NewCaching(cfg *config.Config) (*redis.Client, error) {
+const redisDialTimeout = time.Second
+
+// NewCaching подключается к Redis. Недоступный Redis не мешает запуску:
+// клиент переподключается сам, а кэш до тех пор работает в памяти.
+func NewCaching(cfg *config.Config) (*redis.Client, *RedisHealth) {
 	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
-		Password: cfg.RedisPassword,
+		Addr:        fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+		Password:    cfg.RedisPassword,
+		DialTimeout: redisDialTimeout,
 	})
 
 	if err := client.Ping().Err(); err != nil {
-		fmt.Printf("Ошибка подключения к Redis: %v\n", err)
-		return nil, fmt.Errorf("не удалось подключиться к Redis: %w", err)
+		fmt.Printf("Ошибка подключения к Redis, сервис запускается без него: %v\n", err)
+		return client, newRedisHealth(client, false)
 	}
 
 	fmt.Println("Успешно подключено к Redis")
 
-	return client, nil
+	return client, newRedisHealth(client, true)
+}
+
+// NewCache собирает кэш по CACHE_MODE: redis — Redis с запасным кэшем в
+// памяти на время его недоступности, tiered — всегда L1 в памяти поверх
+// Redis, memory — только память процесса.
+func NewCache(cfg *config.Config, client *redis.Client, connected bool) (Cache, error) {
+	local := newLRUCache(cfg.CacheLocalSize)
+	localTTL := time.Duration(cfg.CacheLocalTTLSeconds) * time.Second
+
+	switch cfg.CacheMode {
+	case ModeMemory:
+		return local, nil
+	case ModeRedis:
+		return newTieredCache(local, newRedisCache(client), localTTL, false, connected), nil
+	case ModeTiered:
+		return newTieredCache(local, newRedisCache(client), localTTL, true, connected), nil
+	default:
+		return nil, fmt.Errorf("неизвестный режим кэша %q", cfg.CacheMode)
+	}
 }
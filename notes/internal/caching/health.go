@@ -0,0 +1,90 @@
+package caching
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var ErrRedisUnavailable = errors.New("Redis недоступен")
+
+// RedisHealth — общий признак доступности Redis для событий, очередей и
+// аренд. Пока Redis помечен недоступным, они не ходят в него вовсе, а не
+// ждут таймаута соединения на каждом запросе; фоновая проверка раз в
+// reconnectInterval возвращает признак, когда Redis снова отвечает.
+type RedisHealth struct {
+	client *redis.Client
+	up     atomic.Bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newRedisHealth(client *redis.Client, up bool) *RedisHealth {
+	health := &RedisHealth{
+		client: client,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	health.up.Store(up)
+
+	go health.monitor()
+
+	return health
+}
+
+func (h *RedisHealth) Up() bool {
+	return h.up.Load()
+}
+
+// Check возвращает ErrRedisUnavailable, если Redis помечен недоступным.
+func (h *RedisHealth) Check() error {
+	if !h.Up() {
+		return ErrRedisUnavailable
+	}
+	return nil
+}
+
+// Report помечает Redis недоступным после ошибки вызова. Ошибки сервера
+// go-redis наружу не отличает от сетевых, поэтому признак снимается при
+// любой, кроме redis.Nil; проверка соединения вернёт его через
+// reconnectInterval.
+func (h *RedisHealth) Report(err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	if h.up.CompareAndSwap(true, false) {
+		fmt.Printf("Redis недоступен, события, очереди и аренды ждут восстановления соединения: %v\n", err)
+	}
+}
+
+func (h *RedisHealth) Close() {
+	select {
+	case <-h.stop:
+	default:
+		close(h.stop)
+	}
+	<-h.done
+}
+
+func (h *RedisHealth) monitor() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(reconnectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !h.Up() && h.client.Ping().Err() == nil {
+				h.up.Store(true)
+				fmt.Println("Соединение с Redis восстановлено, события и очереди снова работают")
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
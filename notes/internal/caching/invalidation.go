@@ -0,0 +1,64 @@
+package caching
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+const invalidationChannel = "notes:cache:invalidate"
+
+// invalidation — сообщение другим репликам о записи: они удаляют указанные
+// ключи и группы из своего L1. Flush означает, что L1 нужно очистить целиком.
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	Flush  bool     `json:"flush,omitempty"`
+}
+
+func newReplicaID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("не удалось сгенерировать ID реплики: %v", err))
+	}
+	return hex.EncodeToString(buf)
+}
+
+func (c *tieredCache) publishInvalidation(keys []string, groups []string, flush bool) error {
+	if len(keys) == 0 && len(groups) == 0 && !flush {
+		return nil
+	}
+
+	message, err := json.Marshal(invalidation{Origin: c.id, Keys: keys, Groups: groups, Flush: flush})
+	if err != nil {
+		return err
+	}
+	return c.remote.client.Publish(invalidationChannel, message).Err()
+}
+
+// listenInvalidations применяет к L1 инвалидации с других реплик. Сообщения,
+// пропущенные во время разрыва подписки, не повторяются: такие записи живут
+// в L1 не дольше localTTL.
+func (c *tieredCache) listenInvalidations() {
+	defer close(c.listenerDone)
+
+	for message := range c.pubsub.Channel() {
+		var payload invalidation
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			fmt.Printf("Ошибка разбора инвалидации кэша: %v\n", err)
+			continue
+		}
+		if payload.Origin == c.id {
+			continue
+		}
+
+		if payload.Flush {
+			c.local.Flush()
+			continue
+		}
+		c.local.Delete(payload.Keys...)
+		c.local.InvalidateGroups(payload.Groups...)
+	}
+}
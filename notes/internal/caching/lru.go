@@ -0,0 +1,165 @@
+package caching
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Поколение удалённого ключа нужно только чтениям, которые уже идут; старые
+// записи о поколениях чистятся, когда их становится больше ёмкости кэша.
+const generationRetention = time.Minute
+
+// lruCache — кэш в памяти процесса с вытеснением давно не читанных ключей.
+type lruCache struct {
+	mu          sync.Mutex
+	capacity    int
+	items       map[string]*list.Element
+	order       *list.List
+	generations map[string]generation
+	counter     int64
+}
+
+type lruEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+type generation struct {
+	value   int64
+	updated time.Time
+}
+
+func newLRUCache(capacity int) *lruCache {
+	cache := &lruCache{capacity: max(capacity, 1)}
+	cache.Flush()
+	return cache
+}
+
+func (c *lruCache) Get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return "", ErrMiss
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.removeElement(element)
+		return "", ErrMiss
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, nil
+}
+
+// Группы в памяти не индексируются: InvalidateGroups ищет ключи по префиксу.
+func (c *lruCache) Set(key string, value string, ttl time.Duration, groups ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl)
+	return nil
+}
+
+func (c *lruCache) Delete(keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		if element, ok := c.items[key]; ok {
+			c.removeElement(element)
+		}
+		// Поколения берутся из общего счётчика, а не считаются по ключу:
+		// так ключ не вернётся к прежнему поколению после очистки.
+		c.counter++
+		c.generations[key] = generation{value: c.counter, updated: now}
+	}
+
+	if len(c.generations) > c.capacity {
+		for key, generation := range c.generations {
+			if now.Sub(generation.updated) > generationRetention {
+				delete(c.generations, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *lruCache) InvalidateGroups(groups ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, element := range c.items {
+		for _, group := range groups {
+			if strings.HasPrefix(key, group) {
+				c.removeElement(element)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (c *lruCache) Generation(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return strconv.FormatInt(c.generations[key].value, 10), nil
+}
+
+func (c *lruCache) SetIfGeneration(key string, value string, ttl time.Duration, generation string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if strconv.FormatInt(c.generations[key].value, 10) != generation {
+		return false, nil
+	}
+	c.set(key, value, ttl)
+	return true, nil
+}
+
+// Flush очищает кэш, сохраняя поколения: чтения, начатые до очистки, всё
+// равно не должны записать устаревшие данные.
+func (c *lruCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order = list.New()
+	if c.generations == nil {
+		c.generations = make(map[string]generation)
+	}
+}
+
+func (c *lruCache) Close() error {
+	return nil
+}
+
+func (c *lruCache) set(key string, value string, ttl time.Duration) {
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.items[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *lruCache) removeElement(element *list.Element) {
+	entry := element.Value.(*lruEntry)
+	c.order.Remove(element)
+	delete(c.items, entry.key)
+}
@@ -0,0 +1,140 @@
+package caching
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	redisKeyPrefix = "notes:cache:"
+	generationTTL  = 2 * time.Hour
+)
+
+var setIfGenerationScript = redis.NewScript(`
+local generation = redis.call("get", KEYS[2]) or "0"
+if generation == ARGV[1] then
+	redis.call("set", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0`)
+
+// redisCache хранит кэш в Redis под префиксом notes:cache:, чтобы его можно
+// было сбросить целиком, не задев очереди и события в том же Redis.
+type redisCache struct {
+	client *redis.Client
+}
+
+func newRedisCache(client *redis.Client) *redisCache {
+	return &redisCache{client: client}
+}
+
+func (c *redisCache) Get(key string) (string, error) {
+	value, err := c.client.Get(redisKeyPrefix + key).Result()
+	if err == redis.Nil {
+		return "", ErrMiss
+	}
+	return value, err
+}
+
+func (c *redisCache) Set(key string, value string, ttl time.Duration, groups ...string) error {
+	_, err := c.client.Pipelined(func(pipe redis.Pipeliner) error {
+		pipe.Set(redisKeyPrefix+key, value, ttl)
+		for _, group := range groups {
+			groupKey := groupKey(group)
+			pipe.SAdd(groupKey, redisKeyPrefix+key)
+			pipe.Expire(groupKey, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *redisCache) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(redisKeyPrefix + key)
+			pipe.Incr(generationKey(key))
+			pipe.Expire(generationKey(key), generationTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *redisCache) InvalidateGroups(groups ...string) error {
+	for _, group := range groups {
+		keys, err := c.client.SMembers(groupKey(group)).Result()
+		if err != nil {
+			return err
+		}
+		if err := c.client.Del(append(keys, groupKey(group))...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *redisCache) Generation(key string) (string, error) {
+	generation, err := c.client.Get(generationKey(key)).Result()
+	if err == redis.Nil {
+		return "0", nil
+	}
+	return generation, err
+}
+
+func (c *redisCache) SetIfGeneration(key string, value string, ttl time.Duration, generation string) (bool, error) {
+	keys := []string{redisKeyPrefix + key, generationKey(key)}
+	stored, err := setIfGenerationScript.Run(c.client, keys, generation, value, ttl.Milliseconds()).Int64()
+	return stored == 1, err
+}
+
+// Flush удаляет весь кэш, кроме поколений ключей.
+func (c *redisCache) Flush() error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(cursor, redisKeyPrefix+"*", 1000).Result()
+		if err != nil {
+			return err
+		}
+
+		var deleted []string
+		for _, key := range keys {
+			if !strings.HasSuffix(key, ":gen") {
+				deleted = append(deleted, key)
+			}
+		}
+		if len(deleted) > 0 {
+			if err := c.client.Del(deleted...).Err(); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func (c *redisCache) Ping() error {
+	return c.client.Ping().Err()
+}
+
+// Соединение принадлежит сервису: через него же работают события и очереди.
+func (c *redisCache) Close() error {
+	return nil
+}
+
+func generationKey(key string) string {
+	return redisKeyPrefix + key + ":gen"
+}
+
+func groupKey(group string) string {
+	return redisKeyPrefix + "group:" + group
+}
@@ -0,0 +1,315 @@
+package caching
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	reconnectInterval = 5 * time.Second
+	maxPending        = 10000
+)
+
+// tieredCache — Redis (L2) с кэшем в памяти процесса (L1). В режиме tiered
+// L1 работает всегда, в режиме redis — только пока Redis недоступен. Срок
+// жизни в L1 ограничен localTTL. Удаления и сбросы групп рассылаются через
+// Redis pub/sub, и другие реплики убирают те же записи из своего L1; если
+// сообщение потерялось, запись доживёт в L1 самое большее до localTTL.
+//
+// Пока Redis недоступен, удаления и сбросы групп копятся и применяются к нему
+// при восстановлении соединения, до того как чтение из Redis возобновится;
+// при переполнении Redis очищается целиком. L1 при этом тоже очищается.
+type tieredCache struct {
+	local       *lruCache
+	remote      *redisCache
+	localTTL    time.Duration
+	alwaysLocal bool
+
+	id           string
+	pubsub       *redis.PubSub
+	listenerDone chan struct{}
+
+	remoteUp      atomic.Bool
+	mu            sync.Mutex
+	pendingKeys   map[string]struct{}
+	pendingGroups map[string]struct{}
+	flushRemote   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newTieredCache(local *lruCache, remote *redisCache, localTTL time.Duration, alwaysLocal bool, remoteUp bool) *tieredCache {
+	cache := &tieredCache{
+		local:         local,
+		remote:        remote,
+		localTTL:      localTTL,
+		alwaysLocal:   alwaysLocal,
+		id:            newReplicaID(),
+		pendingKeys:   make(map[string]struct{}),
+		pendingGroups: make(map[string]struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	cache.remoteUp.Store(remoteUp)
+
+	// Без L1 слушать инвалидации незачем, но рассылаются они в любом режиме:
+	// при смене режима реплики какое-то время работают вперемешку.
+	if alwaysLocal {
+		cache.pubsub = remote.client.Subscribe(invalidationChannel)
+		cache.listenerDone = make(chan struct{})
+		go cache.listenInvalidations()
+	}
+
+	go cache.monitor()
+
+	return cache
+}
+
+func (c *tieredCache) Get(key string) (string, error) {
+	up := c.remoteUp.Load()
+	if c.alwaysLocal || !up {
+		if value, err := c.local.Get(key); err == nil || !up {
+			return value, err
+		}
+	}
+
+	value, err := c.remote.Get(key)
+	if err == ErrMiss {
+		return "", ErrMiss
+	}
+	if err != nil {
+		c.remoteFailed(err)
+		return "", ErrMiss
+	}
+
+	if c.alwaysLocal {
+		c.local.Set(key, value, c.localTTL)
+	}
+	return value, nil
+}
+
+func (c *tieredCache) Set(key string, value string, ttl time.Duration, groups ...string) error {
+	if c.alwaysLocal || !c.remoteUp.Load() {
+		c.local.Set(key, value, min(ttl, c.localTTL))
+	}
+	if !c.remoteUp.Load() {
+		return nil
+	}
+
+	if err := c.remote.Set(key, value, ttl, groups...); err != nil {
+		c.remoteFailed(err)
+	}
+	return nil
+}
+
+func (c *tieredCache) Delete(keys ...string) error {
+	c.local.Delete(keys...)
+	c.invalidateRemote(keys, nil)
+	return nil
+}
+
+func (c *tieredCache) InvalidateGroups(groups ...string) error {
+	c.local.InvalidateGroups(groups...)
+	c.invalidateRemote(nil, groups)
+	return nil
+}
+
+// Redis помечается доступным только под c.mu, поэтому инвалидация либо
+// применяется к Redis, либо попадает в очередь до того, как её разберут.
+func (c *tieredCache) invalidateRemote(keys []string, groups []string) {
+	for {
+		c.mu.Lock()
+		if !c.remoteUp.Load() {
+			for _, key := range keys {
+				c.pendingKeys[key] = struct{}{}
+			}
+			for _, group := range groups {
+				c.pendingGroups[group] = struct{}{}
+			}
+			c.checkPending()
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Unlock()
+
+		err := c.remote.Delete(keys...)
+		if err == nil {
+			err = c.remote.InvalidateGroups(groups...)
+		}
+		if err == nil {
+			err = c.publishInvalidation(keys, groups, false)
+		}
+		if err == nil {
+			return
+		}
+		c.remoteFailed(err)
+	}
+}
+
+// Поколение включает поколения обоих уровней, поэтому значение, прочитанное
+// до удаления, не запишется ни в Redis, ни в L1, даже если Redis пропал или
+// появился между Generation и SetIfGeneration.
+func (c *tieredCache) Generation(key string) (string, error) {
+	localGeneration, _ := c.local.Generation(key)
+	if !c.remoteUp.Load() {
+		return "local:" + localGeneration, nil
+	}
+
+	remoteGeneration, err := c.remote.Generation(key)
+	if err != nil {
+		c.remoteFailed(err)
+		return "local:" + localGeneration, nil
+	}
+	return remoteGeneration + ":" + localGeneration, nil
+}
+
+func (c *tieredCache) SetIfGeneration(key string, value string, ttl time.Duration, generation string) (bool, error) {
+	remoteGeneration, localGeneration, _ := strings.Cut(generation, ":")
+	up := c.remoteUp.Load()
+	if (remoteGeneration == "local") == up {
+		return false, nil
+	}
+
+	if up {
+		stored, err := c.remote.SetIfGeneration(key, value, ttl, remoteGeneration)
+		if err != nil {
+			c.remoteFailed(err)
+			return false, nil
+		}
+		if !stored || !c.alwaysLocal {
+			return stored, nil
+		}
+	}
+
+	return c.local.SetIfGeneration(key, value, min(ttl, c.localTTL), localGeneration)
+}
+
+func (c *tieredCache) Close() error {
+	select {
+	case <-c.stop:
+	default:
+		close(c.stop)
+	}
+	<-c.done
+
+	if c.pubsub != nil {
+		if err := c.pubsub.Close(); err != nil {
+			return err
+		}
+		<-c.listenerDone
+	}
+	return nil
+}
+
+func (c *tieredCache) remoteFailed(err error) {
+	if c.remoteUp.CompareAndSwap(true, false) {
+		fmt.Printf("Redis недоступен, кэш работает в памяти до восстановления соединения: %v\n", err)
+	}
+}
+
+// Вызывается под c.mu.
+func (c *tieredCache) checkPending() {
+	if len(c.pendingKeys)+len(c.pendingGroups) > maxPending {
+		c.flushRemote = true
+		c.pendingKeys = make(map[string]struct{})
+		c.pendingGroups = make(map[string]struct{})
+	}
+}
+
+func (c *tieredCache) monitor() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(reconnectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !c.remoteUp.Load() {
+				c.reconnect()
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *tieredCache) reconnect() {
+	if err := c.remote.Ping(); err != nil {
+		return
+	}
+
+	if err := c.replayPending(); err != nil {
+		fmt.Printf("Не удалось применить отложенные инвалидации к Redis: %v\n", err)
+		return
+	}
+
+	fmt.Println("Соединение с Redis восстановлено")
+}
+
+// Отложенные инвалидации применяются пачками, пока очередь не опустеет, и
+// только затем Redis снова становится источником данных. Новые удаления во
+// время применения попадают в очередь и применяются следующей пачкой.
+func (c *tieredCache) replayPending() error {
+	for {
+		c.mu.Lock()
+		keys, groups, flush := c.pendingKeys, c.pendingGroups, c.flushRemote
+		if len(keys) == 0 && len(groups) == 0 && !flush {
+			c.local.Flush()
+			c.remoteUp.Store(true)
+			c.mu.Unlock()
+			return nil
+		}
+		c.pendingKeys = make(map[string]struct{})
+		c.pendingGroups = make(map[string]struct{})
+		c.flushRemote = false
+		c.mu.Unlock()
+
+		err := c.applyPending(keys, groups, flush)
+		if err != nil {
+			c.mu.Lock()
+			for key := range keys {
+				c.pendingKeys[key] = struct{}{}
+			}
+			for group := range groups {
+				c.pendingGroups[group] = struct{}{}
+			}
+			c.flushRemote = c.flushRemote || flush
+			c.checkPending()
+			c.mu.Unlock()
+			return err
+		}
+	}
+}
+
+func (c *tieredCache) applyPending(keys map[string]struct{}, groups map[string]struct{}, flush bool) error {
+	if flush {
+		if err := c.remote.Flush(); err != nil {
+			return err
+		}
+		return c.publishInvalidation(nil, nil, true)
+	}
+
+	deleted := make([]string, 0, len(keys))
+	for key := range keys {
+		deleted = append(deleted, key)
+	}
+	if err := c.remote.Delete(deleted...); err != nil {
+		return err
+	}
+
+	invalidated := make([]string, 0, len(groups))
+	for group := range groups {
+		invalidated = append(invalidated, group)
+	}
+	if err := c.remote.InvalidateGroups(invalidated...); err != nil {
+		return err
+	}
+	return c.publishInvalidation(deleted, invalidated, false)
+}
@@ -0,0 +1,210 @@
+package collab
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"sync"
+	"time"
+)
+
+const (
+	idleTimeout    = time.Minute
+	persistTimeout = 10 * time.Second
+)
+
+type Store interface {
+	GetByID(ctx context.Context, id string) (*models.Note, error)
+	Update(ctx context.Context, note models.Note) (*models.Note, error)
+}
+
+// Hub держит открытые сессии совместного редактирования и периодически
+// сохраняет их документы через Store. Сессия живёт, пока к заметке подключён
+// хотя бы один клиент, и ещё idleTimeout после ухода последнего, чтобы
+// переподключившиеся клиенты могли догнать документ без полной загрузки.
+type Hub struct {
+	store        Store
+	saveInterval time.Duration
+	historyLimit int
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+	closed   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewHub(store Store, saveInterval time.Duration, historyLimit int) *Hub {
+	if saveInterval <= 0 {
+		saveInterval = 5 * time.Second
+	}
+	if historyLimit <= 0 {
+		historyLimit = 500
+	}
+
+	hub := &Hub{
+		store:        store,
+		saveInterval: saveInterval,
+		historyLimit: historyLimit,
+		sessions:     make(map[string]*Session),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go hub.run()
+
+	return hub
+}
+
+// Join подключает клиента к сессии заметки. Если клиент передал сессию и
+// ревизию, которые ещё есть в истории, он получит только пропущенные
+// операции, иначе — весь документ.
+func (h *Hub) Join(note models.Note, userID int, canWrite bool, sessionID string, clientID string, revision int) (*Session, *Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, errors.ErrCollabClosed
+	}
+
+	session := h.sessions[note.ID]
+	if session == nil {
+		id, err := newID()
+		if err != nil {
+			return nil, nil, err
+		}
+		session = newSession(h, id, note)
+		h.sessions[note.ID] = session
+	}
+
+	session.mu.Lock()
+	reuse := clientID != "" && sessionID == session.id && !session.hasClient(clientID)
+	session.mu.Unlock()
+	if !reuse {
+		var err error
+		if clientID, err = newID(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client := &Client{
+		id:       clientID,
+		userID:   userID,
+		canWrite: canWrite,
+		send:     make(chan ServerMessage, clientBuffer),
+	}
+	session.join(client, sessionID, revision)
+
+	return session, client, nil
+}
+
+func (h *Hub) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	close(h.stop)
+	<-h.done
+}
+
+func (h *Hub) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.saveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			for _, session := range h.snapshot() {
+				h.persist(session)
+				session.terminate(errors.ErrCollabClosed)
+			}
+			return
+		case <-ticker.C:
+			for _, session := range h.snapshot() {
+				h.persist(session)
+			}
+			h.evictIdle()
+		}
+	}
+}
+
+func (h *Hub) snapshot() []*Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions := make([]*Session, 0, len(h.sessions))
+	for _, session := range h.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+func (h *Hub) persist(session *Session) {
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	stored, err := h.store.GetByID(ctx, session.noteID)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrNoteNotFound) {
+			h.remove(session)
+			session.terminate(err)
+			return
+		}
+		fmt.Printf("Ошибка чтения заметки %s для совместного редактирования: %v\n", session.noteID, err)
+		return
+	}
+
+	content, revision, ok := session.prepareSave(*stored)
+	if !ok {
+		return
+	}
+
+	stored.Content = content
+	updated, err := h.store.Update(ctx, *stored)
+	if err != nil {
+		fmt.Printf("Ошибка сохранения заметки %s из сессии редактирования: %v\n", session.noteID, err)
+		return
+	}
+
+	session.markSaved(content, revision, updated.Version)
+}
+
+func (h *Hub) remove(session *Session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sessions[session.noteID] == session {
+		delete(h.sessions, session.noteID)
+	}
+}
+
+func (h *Hub) evictIdle() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for noteID, session := range h.sessions {
+		if session.expired(now, idleTimeout) {
+			delete(h.sessions, noteID)
+		}
+	}
+}
+
+func newID() (string, error) {
+	buffer := make([]byte, 8)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,56 @@
+package collab
+
+const (
+	MessageOperation = "operation"
+	MessageCursor    = "cursor"
+	MessageAck       = "ack"
+	MessageSnapshot  = "snapshot"
+	MessageCatchUp   = "catchup"
+	MessagePresence  = "presence"
+	MessageError     = "error"
+)
+
+type ClientMessage struct {
+	Type      string    `json:"type"`
+	Revision  int       `json:"revision"`
+	Operation Operation `json:"operation"`
+	Cursor    *Cursor   `json:"cursor"`
+}
+
+type Cursor struct {
+	Position     int `json:"position"`
+	SelectionEnd int `json:"selection_end"`
+}
+
+type Presence struct {
+	ClientID string  `json:"client_id"`
+	UserID   int     `json:"user_id"`
+	CanWrite bool    `json:"can_write"`
+	Cursor   *Cursor `json:"cursor,omitempty"`
+}
+
+type HistoryEntry struct {
+	ClientID  string    `json:"client_id,omitempty"`
+	Operation Operation `json:"operation"`
+}
+
+type ServerMessage struct {
+	Type       string         `json:"type"`
+	Session    string         `json:"session,omitempty"`
+	ClientID   string         `json:"client_id,omitempty"`
+	Revision   int            `json:"revision"`
+	Content    *string        `json:"content,omitempty"`
+	Operation  Operation      `json:"operation,omitempty"`
+	Operations []HistoryEntry `json:"operations,omitempty"`
+	Clients    []Presence     `json:"clients,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Details    string         `json:"details,omitempty"`
+}
+
+func ErrorMessage(message string, err error) ServerMessage {
+	return ServerMessage{
+		Type:    MessageError,
+		Error:   message,
+		Details: err.Error(),
+	}
+}
@@ -0,0 +1,293 @@
+package collab
+
+import (
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+	"unicode/utf16"
+)
+
+// Operation — текстовая операция в формате ot.js: положительное число
+// пропускает символы, строка вставляет текст, отрицательное число удаляет.
+// Длины считаются в UTF-16 единицах, как в строках JavaScript.
+type Operation []Component
+
+type Component struct {
+	Retain int
+	Insert string
+	Delete int
+}
+
+func (c Component) isRetain() bool { return c.Retain > 0 }
+func (c Component) isInsert() bool { return c.Insert != "" }
+func (c Component) isDelete() bool { return c.Delete > 0 }
+
+func (o Operation) Retain(n int) Operation {
+	if n <= 0 {
+		return o
+	}
+	if last := len(o) - 1; last >= 0 && o[last].isRetain() {
+		o[last].Retain += n
+		return o
+	}
+	return append(o, Component{Retain: n})
+}
+
+// Вставка всегда ставится перед соседним удалением, чтобы у одинаковых
+// правок была одна каноническая форма.
+func (o Operation) Insert(text string) Operation {
+	if text == "" {
+		return o
+	}
+
+	last := len(o) - 1
+	if last >= 0 && o[last].isInsert() {
+		o[last].Insert += text
+		return o
+	}
+	if last >= 0 && o[last].isDelete() {
+		if last > 0 && o[last-1].isInsert() {
+			o[last-1].Insert += text
+			return o
+		}
+		o = append(o, o[last])
+		o[last] = Component{Insert: text}
+		return o
+	}
+	return append(o, Component{Insert: text})
+}
+
+func (o Operation) Delete(n int) Operation {
+	if n <= 0 {
+		return o
+	}
+	if last := len(o) - 1; last >= 0 && o[last].isDelete() {
+		o[last].Delete += n
+		return o
+	}
+	return append(o, Component{Delete: n})
+}
+
+func (o Operation) BaseLength() int {
+	length := 0
+	for _, component := range o {
+		length += component.Retain + component.Delete
+	}
+	return length
+}
+
+func (o Operation) TargetLength() int {
+	length := 0
+	for _, component := range o {
+		length += component.Retain + textLength(component.Insert)
+	}
+	return length
+}
+
+func (o Operation) IsNoop() bool {
+	return len(o) == 0 || (len(o) == 1 && o[0].isRetain())
+}
+
+func (o Operation) Apply(document []uint16) ([]uint16, error) {
+	if o.BaseLength() != len(document) {
+		return nil, fmt.Errorf("%w: длина документа %d, операция рассчитана на %d", errors.ErrInvalidOperation, len(document), o.BaseLength())
+	}
+
+	result := make([]uint16, 0, o.TargetLength())
+	position := 0
+	for _, component := range o {
+		switch {
+		case component.isRetain():
+			result = append(result, document[position:position+component.Retain]...)
+			position += component.Retain
+		case component.isInsert():
+			result = append(result, utf16.Encode([]rune(component.Insert))...)
+		case component.isDelete():
+			position += component.Delete
+		}
+	}
+
+	return result, nil
+}
+
+// Transform возвращает пару операций a' и b', для которых
+// apply(apply(doc, a), b') == apply(apply(doc, b), a'). При одновременной
+// вставке в одно место первой считается вставка a.
+func Transform(a, b Operation) (Operation, Operation, error) {
+	if a.BaseLength() != b.BaseLength() {
+		return nil, nil, fmt.Errorf("%w: операции рассчитаны на разные версии документа", errors.ErrInvalidOperation)
+	}
+
+	var aPrime, bPrime Operation
+	i, j := 0, 0
+	var first, second Component
+	hasFirst, hasSecond := false, false
+
+	next := func(operation Operation, index *int, component *Component, ok *bool) {
+		if *index < len(operation) {
+			*component = operation[*index]
+			*index++
+			*ok = true
+			return
+		}
+		*ok = false
+	}
+	next(a, &i, &first, &hasFirst)
+	next(b, &j, &second, &hasSecond)
+
+	for hasFirst || hasSecond {
+		if hasFirst && first.isInsert() {
+			aPrime = aPrime.Insert(first.Insert)
+			bPrime = bPrime.Retain(textLength(first.Insert))
+			next(a, &i, &first, &hasFirst)
+			continue
+		}
+		if hasSecond && second.isInsert() {
+			aPrime = aPrime.Retain(textLength(second.Insert))
+			bPrime = bPrime.Insert(second.Insert)
+			next(b, &j, &second, &hasSecond)
+			continue
+		}
+		if !hasFirst || !hasSecond {
+			return nil, nil, fmt.Errorf("%w: операции рассчитаны на разные версии документа", errors.ErrInvalidOperation)
+		}
+
+		length := min(first.Retain+first.Delete, second.Retain+second.Delete)
+		switch {
+		case first.isRetain() && second.isRetain():
+			aPrime = aPrime.Retain(length)
+			bPrime = bPrime.Retain(length)
+		case first.isDelete() && second.isRetain():
+			aPrime = aPrime.Delete(length)
+		case first.isRetain() && second.isDelete():
+			bPrime = bPrime.Delete(length)
+		}
+
+		if shrink(&first, length) {
+			next(a, &i, &first, &hasFirst)
+		}
+		if shrink(&second, length) {
+			next(b, &j, &second, &hasSecond)
+		}
+	}
+
+	return aPrime, bPrime, nil
+}
+
+// TransformIndex сдвигает позицию курсора с учётом применённой операции.
+func TransformIndex(index int, operation Operation) int {
+	newIndex := index
+	for _, component := range operation {
+		switch {
+		case component.isRetain():
+			index -= component.Retain
+		case component.isInsert():
+			newIndex += textLength(component.Insert)
+		case component.isDelete():
+			newIndex -= min(index, component.Delete)
+			index -= component.Delete
+		}
+		if index < 0 {
+			break
+		}
+	}
+	return newIndex
+}
+
+// Diff строит операцию, превращающую from в to, заменой одного участка
+// между общими началом и концом.
+func Diff(from, to []uint16) Operation {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	if prefix > 0 && isHighSurrogate(from[prefix-1]) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+	if suffix > 0 && isLowSurrogate(to[len(to)-suffix]) {
+		suffix--
+	}
+
+	var operation Operation
+	operation = operation.Retain(prefix)
+	operation = operation.Insert(string(utf16.Decode(to[prefix : len(to)-suffix])))
+	operation = operation.Delete(len(from) - prefix - suffix)
+	return operation.Retain(suffix)
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	values := make([]any, 0, len(o))
+	for _, component := range o {
+		switch {
+		case component.isRetain():
+			values = append(values, component.Retain)
+		case component.isInsert():
+			values = append(values, component.Insert)
+		case component.isDelete():
+			values = append(values, -component.Delete)
+		}
+	}
+	return json.Marshal(values)
+}
+
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var values []json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidOperation, err)
+	}
+
+	var operation Operation
+	for _, value := range values {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			if text == "" {
+				return fmt.Errorf("%w: пустая вставка", errors.ErrInvalidOperation)
+			}
+			operation = operation.Insert(text)
+			continue
+		}
+
+		var count int
+		if err := json.Unmarshal(value, &count); err != nil || count == 0 {
+			return fmt.Errorf("%w: %s", errors.ErrInvalidOperation, value)
+		}
+		if count > 0 {
+			operation = operation.Retain(count)
+		} else {
+			operation = operation.Delete(-count)
+		}
+	}
+
+	*o = operation
+	return nil
+}
+
+func shrink(component *Component, length int) bool {
+	if component.isRetain() {
+		component.Retain -= length
+		return component.Retain == 0
+	}
+	component.Delete -= length
+	return component.Delete == 0
+}
+
+func textLength(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
+
+func isHighSurrogate(unit uint16) bool {
+	return unit >= 0xd800 && unit < 0xdc00
+}
+
+func isLowSurrogate(unit uint16) bool {
+	return unit >= 0xdc00 && unit < 0xe000
+}
@@ -0,0 +1,212 @@
+package collab
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"math/rand"
+	"notes/internal/errors"
+	"slices"
+	"testing"
+	"unicode/utf16"
+)
+
+func encode(text string) []uint16 {
+	return utf16.Encode([]rune(text))
+}
+
+func decodeOperation(t *testing.T, raw string) Operation {
+	t.Helper()
+
+	var operation Operation
+	if err := json.Unmarshal([]byte(raw), &operation); err != nil {
+		t.Fatalf("операция %s: %v", raw, err)
+	}
+	return operation
+}
+
+func apply(t *testing.T, operation Operation, document []uint16) []uint16 {
+	t.Helper()
+
+	result, err := operation.Apply(document)
+	if err != nil {
+		t.Fatalf("Apply(%v): %v", operation, err)
+	}
+	return result
+}
+
+// randomOperation строит случайную операцию над документом заданной длины.
+func randomOperation(random *rand.Rand, length int) Operation {
+	var operation Operation
+	for position := 0; position < length; {
+		n := 1 + random.Intn(length-position)
+		switch random.Intn(3) {
+		case 0:
+			operation = operation.Retain(n)
+			position += n
+		case 1:
+			operation = operation.Delete(n)
+			position += n
+		default:
+			operation = operation.Insert(string(rune('a' + random.Intn(3))))
+		}
+	}
+	if random.Intn(2) == 0 {
+		operation = operation.Insert("ж")
+	}
+	return operation
+}
+
+func TestOperationJSON(t *testing.T) {
+	operation := decodeOperation(t, `[2, "аб", -1, 3]`)
+	if operation.BaseLength() != 6 || operation.TargetLength() != 7 {
+		t.Fatalf("длины %d и %d, ожидались 6 и 7", operation.BaseLength(), operation.TargetLength())
+	}
+
+	data, err := json.Marshal(operation)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `[2,"аб",-1,3]` {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	for _, raw := range []string{`[0]`, `[""]`, `[true]`, `{}`} {
+		var operation Operation
+		if err := json.Unmarshal([]byte(raw), &operation); !stdErrors.Is(err, errors.ErrInvalidOperation) {
+			t.Fatalf("Unmarshal(%s): %v, ожидалась ErrInvalidOperation", raw, err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name, document, operation, want string
+	}{
+		{"вставка", "ac", `[1, "b", 1]`, "abc"},
+		{"удаление", "abc", `[1, -1, 1]`, "ac"},
+		{"замена", "abc", `["x", -3]`, "x"},
+		{"суррогатная пара", "a😀b", `[3, "!", 1]`, "a😀!b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := apply(t, decodeOperation(t, test.operation), encode(test.document))
+			if string(utf16.Decode(got)) != test.want {
+				t.Fatalf("результат %q, ожидалось %q", string(utf16.Decode(got)), test.want)
+			}
+		})
+	}
+
+	if _, err := decodeOperation(t, `[5]`).Apply(encode("abc")); !stdErrors.Is(err, errors.ErrInvalidOperation) {
+		t.Fatalf("Apply к документу другой длины: %v, ожидалась ErrInvalidOperation", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name, document, a, b, want string
+	}{
+		{"вставки в разные места", "abc", `["x", 3]`, `[3, "y"]`, "xabcy"},
+		{"вставки в одно место", "abc", `[1, "x", 2]`, `[1, "y", 2]`, "axybc"},
+		{"вставка в удалённый участок", "abcd", `[2, "x", 2]`, `[1, -2, 1]`, "axd"},
+		{"одинаковые удаления", "abcd", `[1, -2, 1]`, `[1, -2, 1]`, "ad"},
+		{"пересекающиеся удаления", "abcdef", `[1, -3, 2]`, `[2, -3, 1]`, "af"},
+		{"удаление и замена", "abc", `[-3]`, `["x", 3]`, "x"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document := encode(test.document)
+			a, b := decodeOperation(t, test.a), decodeOperation(t, test.b)
+
+			aPrime, bPrime, err := Transform(a, b)
+			if err != nil {
+				t.Fatalf("Transform: %v", err)
+			}
+			left := apply(t, bPrime, apply(t, a, document))
+			right := apply(t, aPrime, apply(t, b, document))
+			if !slices.Equal(left, right) {
+				t.Fatalf("документы разошлись: %q и %q", string(utf16.Decode(left)), string(utf16.Decode(right)))
+			}
+			if got := string(utf16.Decode(left)); got != test.want {
+				t.Fatalf("результат %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+
+	if _, _, err := Transform(decodeOperation(t, `[2]`), decodeOperation(t, `[3]`)); !stdErrors.Is(err, errors.ErrInvalidOperation) {
+		t.Fatalf("Transform операций разной длины: %v, ожидалась ErrInvalidOperation", err)
+	}
+}
+
+func TestTransformConverges(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		document := encode(string([]rune("абвгдеёжзи")[:random.Intn(10)]))
+		a := randomOperation(random, len(document))
+		b := randomOperation(random, len(document))
+
+		aPrime, bPrime, err := Transform(a, b)
+		if err != nil {
+			t.Fatalf("Transform(%v, %v): %v", a, b, err)
+		}
+		left := apply(t, bPrime, apply(t, a, document))
+		right := apply(t, aPrime, apply(t, b, document))
+		if !slices.Equal(left, right) {
+			t.Fatalf("Transform(%v, %v): документы разошлись", a, b)
+		}
+	}
+}
+
+func TestTransformIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		index     int
+		operation string
+		want      int
+	}{
+		{"вставка до курсора", 2, `["xy", 4]`, 4},
+		{"вставка после курсора", 2, `[3, "xy", 1]`, 2},
+		{"вставка в позицию курсора", 2, `[2, "xy", 2]`, 4},
+		{"удаление до курсора", 3, `[-2, 2]`, 1},
+		{"удаление вокруг курсора", 2, `[1, -2, 1]`, 1},
+		{"удаление после курсора", 1, `[2, -2]`, 1},
+		{"курсор в конце", 4, `[4, "!"]`, 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := TransformIndex(test.index, decodeOperation(t, test.operation)); got != test.want {
+				t.Fatalf("TransformIndex = %d, ожидалось %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name, from, to, want string
+	}{
+		{"без изменений", "abc", "abc", `[3]`},
+		{"вставка", "ac", "abc", `[1,"b",1]`},
+		{"удаление", "abc", "ac", `[1,-1,1]`},
+		{"замена", "abcd", "axyd", `[1,"xy",-2,1]`},
+		{"из пустого", "", "abc", `["abc"]`},
+		{"в пустой", "abc", "", `[-3]`},
+		{"повтор символа", "aa", "aaa", `[2,"a"]`},
+		{"общий старший суррогат", "😀", "😁", `["😁",-2]`},
+		{"общий младший суррогат", "😀", "🙀", `["🙀",-2]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operation := Diff(encode(test.from), encode(test.to))
+			data, err := json.Marshal(operation)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != test.want {
+				t.Fatalf("Diff = %s, ожидалось %s", data, test.want)
+			}
+			if got := apply(t, operation, encode(test.from)); string(utf16.Decode(got)) != test.to {
+				t.Fatalf("Diff применяется в %q, ожидалось %q", string(utf16.Decode(got)), test.to)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package collab
+
+import (
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+const clientBuffer = 64
+
+type Client struct {
+	id       string
+	userID   int
+	canWrite bool
+	cursor   *Cursor
+	send     chan ServerMessage
+	closed   bool
+}
+
+func (c *Client) Messages() <-chan ServerMessage {
+	return c.send
+}
+
+// Клиента, который не успевает читать, отключаем: после переподключения он
+// догонит документ по своей ревизии. Вызывается под замком сессии.
+func (c *Client) deliver(message ServerMessage) {
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+		c.close()
+	}
+}
+
+func (c *Client) close() {
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// Session хранит документ одной заметки, пока его редактируют: текущее
+// содержимое, последние операции для догоняющих клиентов и то, что уже
+// записано в базу.
+type Session struct {
+	hub    *Hub
+	noteID string
+	id     string
+
+	mu        sync.Mutex
+	document  []uint16
+	revision  int
+	base      int
+	history   []HistoryEntry
+	clients   map[*Client]struct{}
+	dirty     bool
+	idleSince time.Time
+
+	saved         []uint16
+	savedRevision int
+	savedVersion  int
+}
+
+func newSession(hub *Hub, id string, note models.Note) *Session {
+	document := utf16.Encode([]rune(note.Content))
+	return &Session{
+		hub:          hub,
+		noteID:       note.ID,
+		id:           id,
+		document:     document,
+		clients:      make(map[*Client]struct{}),
+		saved:        document,
+		savedVersion: note.Version,
+	}
+}
+
+func (s *Session) Receive(client *Client, data []byte) {
+	var message ClientMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		s.reject(client, errors.MsgInvalidMessage, err)
+		return
+	}
+
+	switch message.Type {
+	case MessageOperation:
+		s.applyOperation(client, message.Revision, message.Operation)
+	case MessageCursor:
+		s.updateCursor(client, message.Cursor)
+	default:
+		s.reject(client, errors.MsgInvalidMessage, fmt.Errorf("неизвестный тип сообщения %q", message.Type))
+	}
+}
+
+func (s *Session) Leave(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.clients[client]; !ok {
+		return
+	}
+	s.removeLocked(client)
+	s.broadcastPresenceLocked(nil)
+}
+
+func (s *Session) join(client *Client, sessionID string, revision int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sessionID == s.id && revision >= s.base && revision <= s.revision {
+		client.deliver(ServerMessage{
+			Type:       MessageCatchUp,
+			Session:    s.id,
+			ClientID:   client.id,
+			Revision:   s.revision,
+			Operations: append([]HistoryEntry(nil), s.history[revision-s.base:]...),
+		})
+	} else {
+		content := string(utf16.Decode(s.document))
+		client.deliver(ServerMessage{
+			Type:     MessageSnapshot,
+			Session:  s.id,
+			ClientID: client.id,
+			Revision: s.revision,
+			Content:  &content,
+		})
+	}
+
+	s.clients[client] = struct{}{}
+	s.idleSince = time.Time{}
+	s.broadcastPresenceLocked(nil)
+}
+
+func (s *Session) hasClient(clientID string) bool {
+	for client := range s.clients {
+		if client.id == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) applyOperation(client *Client, revision int, operation Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !client.canWrite {
+		client.deliver(ErrorMessage(errors.MsgReadOnlySession, errors.ErrReadOnlySession))
+		return
+	}
+	if revision < s.base || revision > s.revision {
+		client.deliver(ErrorMessage(errors.MsgStaleRevision, fmt.Errorf("%w: %d", errors.ErrStaleRevision, revision)))
+		return
+	}
+
+	var err error
+	for _, entry := range s.history[revision-s.base:] {
+		if operation, _, err = Transform(operation, entry.Operation); err != nil {
+			client.deliver(ErrorMessage(errors.MsgInvalidOperation, err))
+			return
+		}
+	}
+
+	document, err := operation.Apply(s.document)
+	if err != nil {
+		client.deliver(ErrorMessage(errors.MsgInvalidOperation, err))
+		return
+	}
+
+	s.commitLocked(client.id, operation, document)
+	client.deliver(ServerMessage{Type: MessageAck, Revision: s.revision})
+}
+
+func (s *Session) commitLocked(clientID string, operation Operation, document []uint16) {
+	s.document = document
+	s.revision++
+	s.history = append(s.history, HistoryEntry{ClientID: clientID, Operation: operation})
+	if overflow := len(s.history) - s.hub.historyLimit; overflow > 0 {
+		s.history = s.history[overflow:]
+		s.base += overflow
+	}
+	s.dirty = true
+
+	for client := range s.clients {
+		if client.cursor != nil {
+			client.cursor.Position = TransformIndex(client.cursor.Position, operation)
+			client.cursor.SelectionEnd = TransformIndex(client.cursor.SelectionEnd, operation)
+		}
+	}
+
+	message := ServerMessage{
+		Type:      MessageOperation,
+		ClientID:  clientID,
+		Revision:  s.revision,
+		Operation: operation,
+	}
+	for client := range s.clients {
+		if client.id != clientID {
+			s.deliverLocked(client, message)
+		}
+	}
+}
+
+func (s *Session) updateCursor(client *Client, cursor *Cursor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cursor != nil {
+		cursor = &Cursor{
+			Position:     clamp(cursor.Position, len(s.document)),
+			SelectionEnd: clamp(cursor.SelectionEnd, len(s.document)),
+		}
+	}
+	client.cursor = cursor
+	s.broadcastPresenceLocked(client)
+}
+
+func (s *Session) broadcastPresenceLocked(except *Client) {
+	clients := make([]Presence, 0, len(s.clients))
+	for client := range s.clients {
+		presence := Presence{
+			ClientID: client.id,
+			UserID:   client.userID,
+			CanWrite: client.canWrite,
+		}
+		if client.cursor != nil {
+			cursor := *client.cursor
+			presence.Cursor = &cursor
+		}
+		clients = append(clients, presence)
+	}
+
+	message := ServerMessage{Type: MessagePresence, Revision: s.revision, Clients: clients}
+	for client := range s.clients {
+		if client != except {
+			s.deliverLocked(client, message)
+		}
+	}
+}
+
+func (s *Session) deliverLocked(client *Client, message ServerMessage) {
+	client.deliver(message)
+	if client.closed {
+		s.removeLocked(client)
+	}
+}
+
+func (s *Session) removeLocked(client *Client) {
+	delete(s.clients, client)
+	client.close()
+	if len(s.clients) == 0 {
+		s.idleSince = time.Now()
+	}
+}
+
+func (s *Session) reject(client *Client, message string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client.deliver(ErrorMessage(message, err))
+}
+
+// prepareSave подмешивает в сессию правки, сделанные в обход неё (например,
+// через PUT /notes/note/:id), и возвращает документ, если его нужно записать.
+func (s *Session) prepareSave(stored models.Note) (string, int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored.Version != s.savedVersion {
+		s.mergeExternalLocked(stored)
+	}
+	if !s.dirty {
+		return "", 0, false
+	}
+
+	content := string(utf16.Decode(s.document))
+	if content == stored.Content {
+		s.saved = s.document
+		s.savedRevision = s.revision
+		s.dirty = false
+		return "", 0, false
+	}
+
+	return content, s.revision, true
+}
+
+func (s *Session) mergeExternalLocked(stored models.Note) {
+	external := utf16.Encode([]rune(stored.Content))
+	operation := Diff(s.saved, external)
+	from := s.savedRevision
+
+	s.saved = external
+	s.savedVersion = stored.Version
+	if operation.IsNoop() {
+		return
+	}
+	s.savedRevision = -1
+
+	if from < s.base {
+		fmt.Printf("Изменения заметки %s вне сессии нельзя совместить, они будут перезаписаны\n", s.noteID)
+		s.dirty = true
+		return
+	}
+
+	var err error
+	for _, entry := range s.history[from-s.base:] {
+		if operation, _, err = Transform(operation, entry.Operation); err != nil {
+			fmt.Printf("Ошибка совмещения изменений заметки %s: %v\n", s.noteID, err)
+			s.dirty = true
+			return
+		}
+	}
+
+	document, err := operation.Apply(s.document)
+	if err != nil {
+		fmt.Printf("Ошибка совмещения изменений заметки %s: %v\n", s.noteID, err)
+		s.dirty = true
+		return
+	}
+	s.commitLocked("", operation, document)
+}
+
+func (s *Session) markSaved(content string, revision int, version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saved = utf16.Encode([]rune(content))
+	s.savedRevision = revision
+	s.savedVersion = version
+	s.dirty = s.revision != revision
+}
+
+func (s *Session) expired(now time.Time, idleTimeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.clients) == 0 && !s.dirty && !s.idleSince.IsZero() && now.Sub(s.idleSince) >= idleTimeout
+}
+
+func (s *Session) terminate(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		client.deliver(ErrorMessage(errors.MsgCollabClosed, err))
+		delete(s.clients, client)
+		client.close()
+	}
+}
+
+func clamp(value int, limit int) int {
+	return max(0, min(value, limit))
+}
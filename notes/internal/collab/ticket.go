@@ -0,0 +1,62 @@
+package collab
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"notes/internal/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TicketTTL — время жизни билета: его получают по REST прямо перед
+// открытием WebSocket, поэтому хватает нескольких секунд.
+const TicketTTL = 30 * time.Second
+
+// NewTicket выдаёт билет "<user_id>.<истекает, unix>.<подпись>" на подключение
+// к одной заметке. Билет подписан секретом сервиса и не хранится, поэтому
+// подходит для любой реплики. В отличие от JWT в адресе, попавший в логи
+// прокси билет бесполезен уже через TicketTTL и только для этой заметки.
+func NewTicket(secret string, noteID string, userID int, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(TicketTTL).Truncate(time.Second)
+	payload := strconv.Itoa(userID) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + signTicket(secret, noteID, payload), expiresAt
+}
+
+// VerifyTicket проверяет подпись и срок билета и возвращает пользователя.
+func VerifyTicket(secret string, ticket string, noteID string, now time.Time) (int, error) {
+	parts := strings.Split(ticket, ".")
+	if len(parts) != 3 {
+		return 0, errors.ErrInvalidTicket
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signTicket(secret, noteID, payload))) {
+		return 0, errors.ErrInvalidTicket
+	}
+
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrInvalidTicket, err)
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrInvalidTicket, err)
+	}
+	if !now.Before(time.Unix(expiresAt, 0)) {
+		return 0, fmt.Errorf("%w: срок истёк", errors.ErrInvalidTicket)
+	}
+
+	return userID, nil
+}
+
+func signTicket(secret string, noteID string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("collab."))
+	mac.Write([]byte(noteID))
+	mac.Write([]byte("."))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,43 @@
+package collab
+
+import (
+	stdErrors "errors"
+	"notes/internal/errors"
+	"testing"
+	"time"
+)
+
+func TestTicket(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	ticket, expiresAt := NewTicket("секрет", "note-1", 42, now)
+	if !expiresAt.Equal(now.Add(TicketTTL)) {
+		t.Fatalf("билет истекает %v, ожидалось %v", expiresAt, now.Add(TicketTTL))
+	}
+
+	userID, err := VerifyTicket("секрет", ticket, "note-1", now.Add(TicketTTL-time.Second))
+	if err != nil {
+		t.Fatalf("VerifyTicket: %v", err)
+	}
+	if userID != 42 {
+		t.Fatalf("пользователь %d, ожидался 42", userID)
+	}
+
+	tests := []struct {
+		name, secret, ticket, noteID string
+		now                          time.Time
+	}{
+		{"истёк", "секрет", ticket, "note-1", expiresAt},
+		{"другая заметка", "секрет", ticket, "note-2", now},
+		{"другой секрет", "другой", ticket, "note-1", now},
+		{"подменён пользователь", "секрет", "1" + ticket, "note-1", now},
+		{"пустой", "секрет", "", "note-1", now},
+		{"не билет", "секрет", "a.b", "note-1", now},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := VerifyTicket(test.secret, test.ticket, test.noteID, test.now); !stdErrors.Is(err, errors.ErrInvalidTicket) {
+				t.Fatalf("VerifyTicket: %v, ожидалась ErrInvalidTicket", err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"notes/internal/errors"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -20,6 +21,48 @@ type Config struct {
 	RedisHost     string
 	RedisPort     string
 	RedisPassword string
+
+	StorageBackend string
+	PostgresDSN    string
+
+	CacheMode            string
+	CacheLocalSize       int
+	CacheLocalTTLSeconds int
+
+	DBRevisionsCollection  string
+	DBLinksCollection      string
+	DBNotebooksCollection  string
+	DBImportJobsCollection string
+	DBInboxCollection      string
+	DBWebhooksCollection   string
+	DBDeliveriesCollection string
+	RevisionsLimit         int
+
+	TrashRetentionHours       int
+	TrashPurgeIntervalMinutes int
+
+	RequireIfMatch bool
+
+	AttachmentsBucket      string
+	AttachmentMaxSizeMB    int
+	AttachmentsUserQuotaMB int
+
+	ImportMaxSizeMB int
+	ImportSyncLimit int
+
+	EventsReplayLimit int
+
+	CollabSaveIntervalSeconds int
+	CollabHistoryLimit        int
+	CollabAllowedOrigins      []string
+
+	ReminderPollIntervalSeconds int
+	ReminderNotifiers           []string
+	ReminderWebhookURL          string
+
+	WebhookPollIntervalSeconds  int
+	WebhookMaxAttempts          int
+	WebhookDisableAfterFailures int
 }
 
 func NewConfig() *Config {
@@ -116,6 +159,253 @@ func NewConfig() *Config {
 		fmt.Println("Не удалось получить DB_COLLECTION из переменной окружения")
 	}
 
+	storageBackend := "mongo"
+	if envValue, err := getEnv("STORAGE_BACKEND"); err == nil {
+		storageBackend = envValue
+	} else {
+		fmt.Println("Не удалось получить STORAGE_BACKEND из переменной окружения, используется mongo")
+	}
+
+	var postgresDSN string
+	if storageBackend == "postgres" {
+		postgresDSN = newPostgresDSN()
+	}
+
+	cacheMode := "redis"
+	if envValue, err := getEnv("CACHE_MODE"); err == nil {
+		cacheMode = envValue
+	} else {
+		fmt.Println("Не удалось получить CACHE_MODE из переменной окружения, используется redis")
+	}
+
+	cacheLocalSize := 10000
+	if envValue, err := getEnv("CACHE_LOCAL_SIZE"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			cacheLocalSize = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить CACHE_LOCAL_SIZE из переменной окружения, используется 10000 записей")
+	}
+
+	cacheLocalTTL := 30
+	if envValue, err := getEnv("CACHE_LOCAL_TTL_SECONDS"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			cacheLocalTTL = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить CACHE_LOCAL_TTL_SECONDS из переменной окружения, используется 30 секунд")
+	}
+
+	dbRevisionsCollection := "note_revisions"
+	if envValue, err := getEnv("DB_REVISIONS_COLLECTION"); err == nil {
+		dbRevisionsCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_REVISIONS_COLLECTION из переменной окружения, используется note_revisions")
+	}
+
+	dbLinksCollection := "note_links"
+	if envValue, err := getEnv("DB_LINKS_COLLECTION"); err == nil {
+		dbLinksCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_LINKS_COLLECTION из переменной окружения, используется note_links")
+	}
+
+	dbNotebooksCollection := "notebooks"
+	if envValue, err := getEnv("DB_NOTEBOOKS_COLLECTION"); err == nil {
+		dbNotebooksCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_NOTEBOOKS_COLLECTION из переменной окружения, используется notebooks")
+	}
+
+	dbImportJobsCollection := "import_jobs"
+	if envValue, err := getEnv("DB_IMPORT_JOBS_COLLECTION"); err == nil {
+		dbImportJobsCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_IMPORT_JOBS_COLLECTION из переменной окружения, используется import_jobs")
+	}
+
+	dbInboxCollection := "reminder_inbox"
+	if envValue, err := getEnv("DB_INBOX_COLLECTION"); err == nil {
+		dbInboxCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_INBOX_COLLECTION из переменной окружения, используется reminder_inbox")
+	}
+
+	dbWebhooksCollection := "webhooks"
+	if envValue, err := getEnv("DB_WEBHOOKS_COLLECTION"); err == nil {
+		dbWebhooksCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_WEBHOOKS_COLLECTION из переменной окружения, используется webhooks")
+	}
+
+	dbDeliveriesCollection := "webhook_deliveries"
+	if envValue, err := getEnv("DB_WEBHOOK_DELIVERIES_COLLECTION"); err == nil {
+		dbDeliveriesCollection = envValue
+	} else {
+		fmt.Println("Не удалось получить DB_WEBHOOK_DELIVERIES_COLLECTION из переменной окружения, используется webhook_deliveries")
+	}
+
+	revisionsLimit := 50
+	if envValue, err := getEnv("NOTE_REVISIONS_LIMIT"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			revisionsLimit = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить NOTE_REVISIONS_LIMIT из переменной окружения, используется 50 ревизий")
+	}
+
+	trashRetentionHours := 720
+	if envValue, err := getEnv("TRASH_RETENTION_HOURS"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			trashRetentionHours = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить TRASH_RETENTION_HOURS из переменной окружения, используется 720 часов")
+	}
+
+	trashPurgeInterval := 60
+	if envValue, err := getEnv("TRASH_PURGE_INTERVAL_MINUTES"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			trashPurgeInterval = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить TRASH_PURGE_INTERVAL_MINUTES из переменной окружения, используется 60 минут")
+	}
+
+	requireIfMatch := false
+	if envValue, err := getEnv("REQUIRE_IF_MATCH"); err == nil {
+		if parsed, parseErr := strconv.ParseBool(envValue); parseErr == nil {
+			requireIfMatch = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить REQUIRE_IF_MATCH из переменной окружения, заголовок If-Match необязателен")
+	}
+
+	attachmentsBucket := "attachments"
+	if envValue, err := getEnv("ATTACHMENTS_BUCKET"); err == nil {
+		attachmentsBucket = envValue
+	} else {
+		fmt.Println("Не удалось получить ATTACHMENTS_BUCKET из переменной окружения, используется attachments")
+	}
+
+	attachmentMaxSize := 20
+	if envValue, err := getEnv("ATTACHMENT_MAX_SIZE_MB"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			attachmentMaxSize = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить ATTACHMENT_MAX_SIZE_MB из переменной окружения, используется 20 МБ")
+	}
+
+	attachmentsQuota := 100
+	if envValue, err := getEnv("ATTACHMENTS_USER_QUOTA_MB"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			attachmentsQuota = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить ATTACHMENTS_USER_QUOTA_MB из переменной окружения, используется 100 МБ")
+	}
+
+	importMaxSize := 50
+	if envValue, err := getEnv("IMPORT_MAX_SIZE_MB"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			importMaxSize = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить IMPORT_MAX_SIZE_MB из переменной окружения, используется 50 МБ")
+	}
+
+	importSyncLimit := 100
+	if envValue, err := getEnv("IMPORT_SYNC_LIMIT"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			importSyncLimit = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить IMPORT_SYNC_LIMIT из переменной окружения, используется 100 заметок")
+	}
+
+	eventsReplayLimit := 100
+	if envValue, err := getEnv("EVENTS_REPLAY_LIMIT"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			eventsReplayLimit = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить EVENTS_REPLAY_LIMIT из переменной окружения, используется 100 событий")
+	}
+
+	collabSaveInterval := 5
+	if envValue, err := getEnv("COLLAB_SAVE_INTERVAL_SECONDS"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			collabSaveInterval = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить COLLAB_SAVE_INTERVAL_SECONDS из переменной окружения, используется 5 секунд")
+	}
+
+	collabHistoryLimit := 500
+	if envValue, err := getEnv("COLLAB_HISTORY_LIMIT"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			collabHistoryLimit = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить COLLAB_HISTORY_LIMIT из переменной окружения, используется 500 операций")
+	}
+
+	var collabAllowedOrigins []string
+	if envValue, err := getEnv("COLLAB_ALLOWED_ORIGINS"); err == nil {
+		collabAllowedOrigins = strings.Split(envValue, ",")
+	} else {
+		fmt.Println("Не удалось получить COLLAB_ALLOWED_ORIGINS из переменной окружения, WebSocket принимается только со своего Origin")
+	}
+
+	reminderPollInterval := 30
+	if envValue, err := getEnv("REMINDER_POLL_INTERVAL_SECONDS"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			reminderPollInterval = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить REMINDER_POLL_INTERVAL_SECONDS из переменной окружения, используется 30 секунд")
+	}
+
+	reminderNotifiers := []string{"log", "inbox"}
+	if envValue, err := getEnv("REMINDER_NOTIFIERS"); err == nil {
+		reminderNotifiers = strings.Split(envValue, ",")
+	} else {
+		fmt.Println("Не удалось получить REMINDER_NOTIFIERS из переменной окружения, используется log,inbox")
+	}
+
+	reminderWebhookURL, err := getEnv("REMINDER_WEBHOOK_URL")
+	if err != nil {
+		fmt.Println("Не удалось получить REMINDER_WEBHOOK_URL из переменной окружения, webhook для напоминаний отключён")
+	}
+
+	webhookPollInterval := 2
+	if envValue, err := getEnv("WEBHOOK_POLL_INTERVAL_SECONDS"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			webhookPollInterval = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить WEBHOOK_POLL_INTERVAL_SECONDS из переменной окружения, используется 2 секунды")
+	}
+
+	webhookMaxAttempts := 8
+	if envValue, err := getEnv("WEBHOOK_MAX_ATTEMPTS"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			webhookMaxAttempts = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить WEBHOOK_MAX_ATTEMPTS из переменной окружения, используется 8 попыток")
+	}
+
+	webhookDisableAfter := 20
+	if envValue, err := getEnv("WEBHOOK_DISABLE_AFTER_FAILURES"); err == nil {
+		if parsed, parseErr := strconv.Atoi(envValue); parseErr == nil {
+			webhookDisableAfter = parsed
+		}
+	} else {
+		fmt.Println("Не удалось получить WEBHOOK_DISABLE_AFTER_FAILURES из переменной окружения, используется 20 ошибок подряд")
+	}
+
 	return &Config{
 		Port:          port,
 		Host:          host,
@@ -129,7 +419,83 @@ func NewConfig() *Config {
 		RedisPassword: redisPassword,
 		DB_NAME:       dbName,
 		DB_COLLECTION: dbCollection,
+
+		StorageBackend: storageBackend,
+		PostgresDSN:    postgresDSN,
+
+		CacheMode:            cacheMode,
+		CacheLocalSize:       cacheLocalSize,
+		CacheLocalTTLSeconds: cacheLocalTTL,
+
+		DBRevisionsCollection:  dbRevisionsCollection,
+		DBLinksCollection:      dbLinksCollection,
+		DBNotebooksCollection:  dbNotebooksCollection,
+		DBImportJobsCollection: dbImportJobsCollection,
+		DBInboxCollection:      dbInboxCollection,
+		DBWebhooksCollection:   dbWebhooksCollection,
+		DBDeliveriesCollection: dbDeliveriesCollection,
+		RevisionsLimit:         revisionsLimit,
+
+		TrashRetentionHours:       trashRetentionHours,
+		TrashPurgeIntervalMinutes: trashPurgeInterval,
+
+		RequireIfMatch: requireIfMatch,
+
+		AttachmentsBucket:      attachmentsBucket,
+		AttachmentMaxSizeMB:    attachmentMaxSize,
+		AttachmentsUserQuotaMB: attachmentsQuota,
+
+		ImportMaxSizeMB: importMaxSize,
+		ImportSyncLimit: importSyncLimit,
+
+		EventsReplayLimit: eventsReplayLimit,
+
+		CollabSaveIntervalSeconds: collabSaveInterval,
+		CollabHistoryLimit:        collabHistoryLimit,
+		CollabAllowedOrigins:      collabAllowedOrigins,
+
+		ReminderPollIntervalSeconds: reminderPollInterval,
+		ReminderNotifiers:           reminderNotifiers,
+		ReminderWebhookURL:          reminderWebhookURL,
+
+		WebhookPollIntervalSeconds:  webhookPollInterval,
+		WebhookMaxAttempts:          webhookMaxAttempts,
+		WebhookDisableAfterFailures: webhookDisableAfter,
+	}
+}
+
+// Postgres нужен только хранилищу postgres, поэтому переменные читаются,
+// только когда оно выбрано.
+func newPostgresDSN() string {
+	host, err := getEnv("POSTGRES_HOST")
+	if err != nil {
+		fmt.Println("Не удалось получить POSTGRES_HOST из переменной окружения")
+	}
+	port, err := getEnv("POSTGRES_PORT")
+	if err != nil {
+		fmt.Println("Не удалось получить POSTGRES_PORT из переменной окружения")
 	}
+	user, err := getEnv("POSTGRES_USER")
+	if err != nil {
+		fmt.Println("Не удалось получить POSTGRES_USER из переменной окружения")
+	}
+	password, err := getEnv("POSTGRES_PASSWORD")
+	if err != nil {
+		fmt.Println("Не удалось получить POSTGRES_PASSWORD из переменной окружения")
+	}
+	name, err := getEnv("POSTGRES_DB")
+	if err != nil {
+		fmt.Println("Не удалось получить POSTGRES_DB из переменной окружения")
+	}
+
+	sslMode := "disable"
+	if envValue, err := getEnv("POSTGRES_USE_SSL"); err == nil {
+		sslMode = envValue
+	} else {
+		fmt.Println("Не удалось получить POSTGRES_USE_SSL из переменной окружения, используется disable")
+	}
+
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", user, password, host, port, name, sslMode)
 }
 
 func getEnv(key string) (string, error) {
@@ -0,0 +1,34 @@
+-- Заметки. ID — ObjectID в hex, как и в Mongo, чтобы API и курсоры страниц
+-- не зависели от хранилища.
+CREATE TABLE notes (
+    id          text COLLATE "C" PRIMARY KEY,
+    author_id   integer     NOT NULL,
+    name        text        NOT NULL DEFAULT '',
+    content     text        NOT NULL DEFAULT '',
+    tags        text[]      NOT NULL DEFAULT '{}',
+    notebook_id text        NOT NULL DEFAULT '',
+    pinned      boolean     NOT NULL DEFAULT false,
+    archived    boolean     NOT NULL DEFAULT false,
+    shares      jsonb       NOT NULL DEFAULT '[]',
+    remind_at   timestamptz,
+    due_at      timestamptz,
+    recurrence  text        NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL,
+    updated_at  timestamptz NOT NULL,
+    version     integer     NOT NULL DEFAULT 1,
+    deleted_at  timestamptz,
+    -- Веса повторяют текстовый индекс Mongo: название в 10 раз важнее
+    -- содержимого (A = 1.0, D = 0.1 в ts_rank).
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', name), 'A') ||
+        setweight(to_tsvector('russian', content), 'D')
+    ) STORED
+);
+
+CREATE INDEX notes_author_created_idx ON notes (author_id, pinned, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX notes_author_name_idx ON notes (author_id, pinned, (name COLLATE "C"), id) WHERE deleted_at IS NULL;
+CREATE INDEX notes_author_updated_idx ON notes (author_id, pinned, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX notes_tags_idx ON notes USING gin (tags);
+CREATE INDEX notes_shares_idx ON notes USING gin (shares jsonb_path_ops);
+CREATE INDEX notes_search_idx ON notes USING gin (search_vector);
+CREATE INDEX notes_deleted_idx ON notes (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,51 @@
+-- Ревизии, публичные ссылки, вложения и блокноты.
+CREATE TABLE note_revisions (
+    note_id    text COLLATE "C" NOT NULL,
+    version    integer     NOT NULL,
+    name       text        NOT NULL DEFAULT '',
+    content    text        NOT NULL DEFAULT '',
+    tags       text[]      NOT NULL DEFAULT '{}',
+    author_id  integer     NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (note_id, version)
+);
+
+CREATE TABLE note_links (
+    token         text PRIMARY KEY,
+    note_id       text COLLATE "C" NOT NULL,
+    author_id     integer     NOT NULL,
+    password_hash text        NOT NULL DEFAULT '',
+    protected     boolean     NOT NULL DEFAULT false,
+    expires_at    timestamptz,
+    views         bigint      NOT NULL DEFAULT 0,
+    created_at    timestamptz NOT NULL
+);
+
+CREATE INDEX note_links_note_idx ON note_links (note_id, created_at DESC);
+
+-- Содержимое вложения хранится в bytea: размер ограничен ATTACHMENT_MAX_SIZE_MB.
+CREATE TABLE note_attachments (
+    id           text COLLATE "C" PRIMARY KEY,
+    note_id      text COLLATE "C" NOT NULL,
+    author_id    integer     NOT NULL,
+    filename     text        NOT NULL,
+    content_type text        NOT NULL DEFAULT '',
+    size         bigint      NOT NULL,
+    data         bytea       NOT NULL,
+    uploaded_at  timestamptz NOT NULL
+);
+
+CREATE INDEX note_attachments_note_idx ON note_attachments (note_id, uploaded_at);
+CREATE INDEX note_attachments_author_idx ON note_attachments (author_id);
+
+CREATE TABLE notebooks (
+    id         text COLLATE "C" PRIMARY KEY,
+    author_id  integer     NOT NULL,
+    name       text        NOT NULL,
+    parent_id  text COLLATE "C" NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL
+);
+
+CREATE INDEX notebooks_author_idx ON notebooks (author_id, parent_id);
+CREATE INDEX notes_notebook_idx ON notes (author_id, notebook_id);
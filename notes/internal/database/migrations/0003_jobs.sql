@@ -0,0 +1,57 @@
+-- Задачи импорта, входящие напоминания, webhook, журнал и очередь доставок.
+-- Сроки хранения, которые в Mongo задают TTL-индексы, здесь проверяются при
+-- чтении, а устаревшие строки удаляются при записи.
+CREATE TABLE import_jobs (
+    id         text COLLATE "C" PRIMARY KEY,
+    author_id  integer     NOT NULL,
+    job        jsonb       NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX import_jobs_created_idx ON import_jobs (created_at);
+
+CREATE TABLE reminder_inbox (
+    id         text COLLATE "C" PRIMARY KEY,
+    user_id    integer     NOT NULL,
+    reminder   jsonb       NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX reminder_inbox_user_idx ON reminder_inbox (user_id, created_at DESC);
+CREATE INDEX notes_remind_at_idx ON notes (remind_at) WHERE remind_at IS NOT NULL AND deleted_at IS NULL;
+
+CREATE TABLE webhooks (
+    id                   text COLLATE "C" PRIMARY KEY,
+    author_id            integer     NOT NULL,
+    url                  text        NOT NULL,
+    events               text[]      NOT NULL DEFAULT '{}',
+    secret               text        NOT NULL,
+    active               boolean     NOT NULL DEFAULT true,
+    consecutive_failures integer     NOT NULL DEFAULT 0,
+    last_delivery_at     timestamptz,
+    disabled_at          timestamptz,
+    created_at           timestamptz NOT NULL,
+    updated_at           timestamptz NOT NULL
+);
+
+CREATE INDEX webhooks_author_idx ON webhooks (author_id, active);
+
+CREATE TABLE webhook_deliveries (
+    id          text COLLATE "C" PRIMARY KEY,
+    webhook_id  text COLLATE "C" NOT NULL,
+    delivery    jsonb       NOT NULL,
+    created_at  timestamptz NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at DESC);
+CREATE INDEX webhook_deliveries_created_idx ON webhook_deliveries (created_at);
+
+-- Очередь доставок: задача видна, когда visible_at наступило; выданная
+-- задача откладывается на время аренды, как в очереди Redis.
+CREATE TABLE webhook_jobs (
+    id         text COLLATE "C" PRIMARY KEY,
+    job        jsonb       NOT NULL,
+    visible_at timestamptz NOT NULL
+);
+
+CREATE INDEX webhook_jobs_visible_idx ON webhook_jobs (visible_at);
@@ -0,0 +1,3 @@
+-- Забранное планировщиком напоминание считается доставляемым до
+-- reminder_claimed_until; remind_at сдвигается только после доставки.
+ALTER TABLE notes ADD COLUMN reminder_claimed_until timestamptz;
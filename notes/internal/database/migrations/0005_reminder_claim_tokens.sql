@@ -0,0 +1,3 @@
+-- Токен забора напоминания: CompleteReminder сдвигает remind_at, только
+-- если пометку о доставке не перехватила другая реплика.
+ALTER TABLE notes ADD COLUMN reminder_claim_token text;
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Общий ключ advisory-блокировки: реплики, стартующие одновременно, применяют
+// миграции по очереди.
+const migrationLockKey = 7_263_101
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+func NewPostgres(cfg *config.Config) (*pgxpool.Pool, error) {
+	if cfg.PostgresDSN == "" {
+		return nil, fmt.Errorf("%w", errors.ErrEmptyDSN)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DBTimeout)*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.PostgresDSN)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseConnection, err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseConnection, err)
+	}
+
+	if err := Migrate(ctx, pool); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// Migrate применяет по порядку миграции из migrations/, которых ещё нет в
+// schema_migrations. Каждая миграция выполняется в своей транзакции вместе
+// с записью о ней, поэтому упавшая миграция не остаётся применённой наполовину.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrMigration, err)
+	}
+
+	_, err = pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    integer PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrMigration, err)
+	}
+
+	for _, migration := range migrations {
+		applied, err := applyMigration(ctx, pool, migration)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", errors.ErrMigration, migration.name, err)
+		}
+		if applied {
+			fmt.Println("Применена миграция", migration.name)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, pool *pgxpool.Pool, migration migration) (bool, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockKey); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", migration.version).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	// Простой протокол запросов позволяет выполнить несколько команд за раз.
+	if _, err := tx.Exec(ctx, migration.sql, pgx.QueryExecModeSimpleProtocol); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.version, migration.name)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// Имя файла миграции — <версия>_<описание>.sql, версии идут по возрастанию.
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("некорректное имя миграции %s", entry.Name())
+		}
+
+		sql, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{
+			version: version,
+			name:    strings.TrimSuffix(entry.Name(), ".sql"),
+			sql:     string(sql),
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int {
+		return a.version - b.version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("повторяется версия миграции %d", migrations[i].version)
+		}
+	}
+
+	return migrations, nil
+}
@@ -0,0 +1,221 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type Kind int
+
+const (
+	Equal Kind = iota
+	Insert
+	Delete
+)
+
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// maxEditCost ограничивает длину поиска кратчайшего пути в одном отрезке:
+// если правок больше, отрезок целиком выводится как удаление и вставка. Так
+// время на совсем разных текстах остаётся O((N+M)·maxEditCost), а память —
+// O(N+M).
+const maxEditCost = 1000
+
+// Lines строит построчный diff алгоритмом Майерса в линейной памяти: вместо
+// истории всех шагов ищется средняя змейка кратчайшего пути, и задача
+// делится по ней надвое.
+func Lines(a, b []string) []Line {
+	d := &differ{a: a, b: b, lines: make([]Line, 0, len(a)+len(b))}
+	d.compare(0, len(a), 0, len(b))
+	return d.lines
+}
+
+type differ struct {
+	a, b  []string
+	lines []Line
+}
+
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.lines = append(d.lines, Line{Kind: Equal, Text: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+
+	suffix := 0
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	if aLo < aHi && bLo < bHi {
+		if x, y, ok := d.middleSnake(aLo, aHi, bLo, bHi); ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+		} else {
+			d.replace(aLo, aHi, bLo, bHi)
+		}
+	} else {
+		d.replace(aLo, aHi, bLo, bHi)
+	}
+
+	for _, text := range d.a[aHi : aHi+suffix] {
+		d.lines = append(d.lines, Line{Kind: Equal, Text: text})
+	}
+}
+
+func (d *differ) replace(aLo, aHi, bLo, bHi int) {
+	for _, text := range d.a[aLo:aHi] {
+		d.lines = append(d.lines, Line{Kind: Delete, Text: text})
+	}
+	for _, text := range d.b[bLo:bHi] {
+		d.lines = append(d.lines, Line{Kind: Insert, Text: text})
+	}
+}
+
+// middleSnake идёт кратчайшим путём одновременно с начала и с конца отрезка
+// и возвращает точку, где пути встретились. forward[k] — самый дальний x на
+// диагонали k = x - y от начала, backward[k] — то же от конца. ok = false,
+// если правок больше maxEditCost.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+
+	maxD := min((n+m+1)/2, maxEditCost)
+	offset := maxD + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for step := 0; step <= maxD; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			if reverse := delta - k; odd && reverse >= -(step-1) && reverse <= step-1 && x+backward[offset+reverse] >= n {
+				return aLo + x, bLo + y, true
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			if straight := delta - k; !odd && straight >= -step && straight <= step && forward[offset+straight]+x >= n {
+				forwardX := forward[offset+straight]
+				return aLo + forwardX, bLo + forwardX - straight, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+func Unified(fromLabel, toLabel, from, to string) string {
+	lines := Lines(splitLines(from), splitLines(to))
+
+	fromPos := make([]int, len(lines)+1)
+	toPos := make([]int, len(lines)+1)
+	for i, line := range lines {
+		fromPos[i+1], toPos[i+1] = fromPos[i], toPos[i]
+		if line.Kind != Insert {
+			fromPos[i+1]++
+		}
+		if line.Kind != Delete {
+			toPos[i+1]++
+		}
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Kind == Equal {
+			continue
+		}
+
+		start := max(0, i-contextLines)
+		end := i
+		for end < len(lines) {
+			if lines[end].Kind != Equal {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(lines) && lines[next].Kind == Equal {
+				next++
+			}
+			if next == len(lines) || next-end > 2*contextLines {
+				end = min(len(lines), end+contextLines)
+				break
+			}
+			end = next
+		}
+
+		if builder.Len() == 0 {
+			fmt.Fprintf(&builder, "--- %s\n+++ %s\n", fromLabel, toLabel)
+		}
+		fmt.Fprintf(&builder, "@@ -%s +%s @@\n",
+			hunkRange(fromPos[start], fromPos[end]-fromPos[start]),
+			hunkRange(toPos[start], toPos[end]-toPos[start]),
+		)
+		for _, line := range lines[start:end] {
+			switch line.Kind {
+			case Insert:
+				builder.WriteString("+")
+			case Delete:
+				builder.WriteString("-")
+			default:
+				builder.WriteString(" ")
+			}
+			builder.WriteString(line.Text)
+			builder.WriteString("\n")
+		}
+
+		i = end - 1
+	}
+
+	return builder.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// apply восстанавливает обе стороны по списку строк diff.
+func apply(lines []Line) (from, to []string) {
+	for _, line := range lines {
+		if line.Kind != Insert {
+			from = append(from, line.Text)
+		}
+		if line.Kind != Delete {
+			to = append(to, line.Text)
+		}
+	}
+	return from, to
+}
+
+func editCost(lines []Line) int {
+	cost := 0
+	for _, line := range lines {
+		if line.Kind != Equal {
+			cost++
+		}
+	}
+	return cost
+}
+
+// lcsCost считает минимальное число правок через наибольшую общую
+// подпоследовательность.
+func lcsCost(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return len(a) + len(b) - 2*table[0][0]
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []Line
+	}{
+		{"одинаковые", []string{"a", "b"}, []string{"a", "b"}, []Line{{Equal, "a"}, {Equal, "b"}}},
+		{"пустые", nil, nil, []Line{}},
+		{"из пустого", nil, []string{"a"}, []Line{{Insert, "a"}}},
+		{"в пустой", []string{"a"}, nil, []Line{{Delete, "a"}}},
+		{"вставка", []string{"a", "c"}, []string{"a", "b", "c"}, []Line{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}}},
+		{"удаление", []string{"a", "b", "c"}, []string{"a", "c"}, []Line{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}}},
+		{"замена", []string{"a", "b", "c"}, []string{"a", "x", "c"}, []Line{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Lines(test.a, test.b); !slices.Equal(got, test.want) {
+				t.Fatalf("Lines = %v, ожидалось %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLinesMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d"}
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(12))
+		for i := range lines {
+			lines[i] = alphabet[random.Intn(len(alphabet))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+		lines := Lines(a, b)
+
+		from, to := apply(lines)
+		if !slices.Equal(from, a) || !slices.Equal(to, b) {
+			t.Fatalf("Lines(%v, %v) восстанавливает %v и %v", a, b, from, to)
+		}
+		if got, want := editCost(lines), lcsCost(a, b); got != want {
+			t.Fatalf("Lines(%v, %v): %d правок, минимально %d", a, b, got, want)
+		}
+	}
+}
+
+func TestLinesEditCostCap(t *testing.T) {
+	a := make([]string, 20000)
+	b := make([]string, 20000)
+	for i := range a {
+		a[i] = "a" + strconv.Itoa(i)
+		b[i] = "b" + strconv.Itoa(i)
+	}
+	a[10000], b[10000] = "общая", "общая"
+
+	lines := Lines(a, b)
+	from, to := apply(lines)
+	if !slices.Equal(from, a) || !slices.Equal(to, b) {
+		t.Fatal("diff совсем разных текстов не восстанавливает исходные строки")
+	}
+}
+
+func TestUnified(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	to := "1\n2\nтри\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n"
+
+	want := "--- note@1\n+++ note@2\n" +
+		"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+три\n 4\n 5\n 6\n" +
+		"@@ -14,3 +14,4 @@\n 14\n 15\n 16\n+17\n"
+	if got := Unified("note@1", "note@2", from, to); got != want {
+		t.Fatalf("Unified =\n%s\nожидалось\n%s", got, want)
+	}
+
+	if got := Unified("note@1", "note@2", from, from); got != "" {
+		t.Fatalf("Unified одинаковых текстов = %q, ожидалась пустая строка", got)
+	}
+}
@@ -10,6 +10,56 @@ var (
 	ErrNoteCreation      = errors.New("ошибка создания заметки")
 	ErrNoteUpdate        = errors.New("ошибка обновления заметки")
 	ErrNoteDeletion      = errors.New("ошибка удаления заметки")
+	ErrInvalidTags       = errors.New("некорректные теги заметки")
+	ErrRevisionNotFound  = errors.New("ревизия заметки не найдена")
+	ErrInvalidRevision   = errors.New("некорректный номер ревизии")
+	ErrRevisionSave      = errors.New("ошибка сохранения ревизии")
+	ErrInvalidShare      = errors.New("некорректные параметры доступа к заметке")
+	ErrLinkNotFound      = errors.New("публичная ссылка не найдена")
+	ErrLinkExpired       = errors.New("срок действия публичной ссылки истёк")
+	ErrLinkPassword      = errors.New("неверный пароль публичной ссылки")
+	ErrInvalidLink       = errors.New("некорректные параметры публичной ссылки")
+	ErrLinkCreation      = errors.New("ошибка создания публичной ссылки")
+	ErrVersionConflict   = errors.New("версия заметки изменилась")
+	ErrInvalidPatch      = errors.New("некорректный патч заметки")
+	ErrPatchTestFailed   = errors.New("проверка test в JSON Patch не пройдена")
+	ErrReadOnlyField     = errors.New("поле заметки недоступно для изменения")
+	ErrDedicatedField    = errors.New("поле заметки меняется отдельным запросом")
+	ErrUnsupportedPatch  = errors.New("неподдерживаемый тип патча")
+
+	ErrAttachmentNotFound = errors.New("вложение не найдено")
+	ErrAttachmentTooLarge = errors.New("вложение превышает допустимый размер")
+	ErrAttachmentQuota    = errors.New("превышена квота на вложения")
+	ErrAttachmentUpload   = errors.New("ошибка загрузки вложения")
+
+	ErrNotebookNotFound = errors.New("блокнот не найден")
+	ErrInvalidNotebook  = errors.New("некорректные данные блокнота")
+
+	ErrNoteForbidden = errors.New("нет доступа к заметке")
+	ErrInvalidBatch  = errors.New("некорректная пакетная операция")
+	ErrBatchAborted  = errors.New("пакет отменён, изменения откачены")
+
+	ErrTransactionsUnsupported = errors.New("MongoDB запущена без replica set, транзакции недоступны")
+
+	ErrInvalidImport     = errors.New("некорректный файл импорта")
+	ErrImportJobNotFound = errors.New("задача импорта не найдена")
+	ErrImportTooLarge    = errors.New("файл импорта превышает допустимый размер")
+	ErrImportInterrupted = errors.New("импорт прерван остановкой сервера")
+
+	ErrInvalidOperation = errors.New("некорректная операция редактирования")
+	ErrStaleRevision    = errors.New("ревизия документа устарела")
+	ErrReadOnlySession  = errors.New("нет прав на редактирование заметки")
+	ErrCollabClosed     = errors.New("сессия совместного редактирования завершена")
+	ErrInvalidTicket    = errors.New("неверный или истекший билет подключения")
+	ErrForbiddenOrigin  = errors.New("подключение с этого Origin запрещено")
+
+	ErrInvalidReminder = errors.New("некорректные параметры напоминания")
+	ErrPinArchived     = errors.New("архивную заметку нельзя закрепить")
+
+	ErrWebhookNotFound = errors.New("webhook не найден")
+	ErrInvalidWebhook  = errors.New("некорректные параметры webhook")
+	ErrWebhookLimit    = errors.New("превышено число webhook")
+	ErrWebhookCreation = errors.New("ошибка создания webhook")
 
 	ErrMissingAuthHeader = errors.New("отсутствует заголовок Authorization")
 	ErrInvalidAuthFormat = errors.New("неверный формат токена")
@@ -25,6 +75,7 @@ var (
 	ErrDatabaseOperation  = errors.New("ошибка операции с базой данных")
 	ErrDatabaseClose      = errors.New("ошибка закрытия соединения с базой данных")
 	ErrDatabaseNotInit    = errors.New("база данных не инициализирована")
+	ErrMigration          = errors.New("ошибка миграции базы данных")
 	ErrCacheConnection    = errors.New("ошибка подключения к кэшу")
 	ErrCacheClose         = errors.New("ошибка закрытия соединения с кэшем")
 	ErrCacheSet           = errors.New("ошибка записи в кэш")
@@ -38,6 +89,8 @@ var (
 
 	ErrServiceCreation = errors.New("ошибка создания сервиса")
 	ErrInvalidData     = errors.New("неверный формат данных")
+	ErrInvalidQuery    = errors.New("неверные параметры запроса")
+	ErrInvalidCursor   = errors.New("некорректный курсор страницы")
 )
 
 const (
@@ -48,6 +101,66 @@ const (
 	MsgNoteCreation      = "Ошибка создания заметки"
 	MsgNoteUpdate        = "Ошибка обновления заметки"
 	MsgNoteDeletion      = "Ошибка удаления заметки"
+	MsgInvalidTags       = "Некорректные теги заметки"
+	MsgRevisionNotFound  = "Ревизия заметки не найдена"
+	MsgInvalidRevision   = "Некорректный номер ревизии"
+	MsgNoteRestore       = "Ошибка восстановления заметки"
+	MsgInvalidShare      = "Некорректные параметры доступа к заметке"
+	MsgNoteShare         = "Ошибка изменения доступа к заметке"
+	MsgLinkNotFound      = "Публичная ссылка не найдена"
+	MsgLinkExpired       = "Срок действия публичной ссылки истёк"
+	MsgLinkPassword      = "Неверный пароль публичной ссылки"
+	MsgInvalidLink       = "Некорректные параметры публичной ссылки"
+	MsgLinkCreation      = "Ошибка создания публичной ссылки"
+	MsgVersionConflict   = "Заметка была изменена, версия не совпадает"
+	MsgIfMatchRequired   = "Требуется заголовок If-Match"
+	MsgInvalidPatch      = "Некорректный патч заметки"
+	MsgPatchTestFailed   = "Проверка test в JSON Patch не пройдена"
+	MsgReadOnlyField     = "Поле заметки недоступно для изменения"
+	MsgDedicatedField    = "Поле заметки меняется отдельным запросом"
+	MsgUnsupportedPatch  = "Неподдерживаемый тип патча"
+
+	MsgAttachmentNotFound = "Вложение не найдено"
+	MsgAttachmentTooLarge = "Вложение превышает допустимый размер"
+	MsgAttachmentQuota    = "Превышена квота на вложения"
+	MsgAttachmentUpload   = "Ошибка загрузки вложения"
+	MsgAttachmentMissing  = "Файл не передан в поле file"
+
+	MsgNotebookNotFound = "Блокнот не найден"
+	MsgInvalidNotebook  = "Некорректные данные блокнота"
+	MsgNotebookCreation = "Ошибка создания блокнота"
+	MsgNotebookUpdate   = "Ошибка обновления блокнота"
+	MsgNotebookDeletion = "Ошибка удаления блокнота"
+
+	MsgNoteForbidden   = "Нет доступа к заметке"
+	MsgInvalidBatch    = "Некорректная пакетная операция"
+	MsgBatchAborted    = "Пакет отменён, изменения откачены"
+	MsgBatchRolledBack = "Операция откачена вместе с пакетом"
+
+	MsgTransactionsUnsupported = "Атомарные пакеты недоступны: MongoDB запущена без replica set, повторите запрос с \"atomic\": false"
+
+	MsgInvalidImport     = "Некорректный файл импорта"
+	MsgImportJobNotFound = "Задача импорта не найдена"
+	MsgImportTooLarge    = "Файл импорта превышает допустимый размер"
+
+	MsgEventsStream = "Ошибка подписки на события заметок"
+
+	MsgInvalidOperation = "Некорректная операция редактирования"
+	MsgStaleRevision    = "Ревизия документа устарела, переподключитесь"
+	MsgReadOnlySession  = "Нет прав на редактирование заметки"
+	MsgInvalidMessage   = "Некорректное сообщение"
+	MsgCollabClosed     = "Сессия совместного редактирования завершена"
+	MsgInvalidTicket    = "Неверный или истекший билет подключения"
+
+	MsgInvalidReminder = "Некорректные параметры напоминания"
+	MsgPinArchived     = "Архивную заметку нельзя закрепить, сначала верните её из архива"
+
+	MsgWebhookNotFound = "Webhook не найден"
+	MsgInvalidWebhook  = "Некорректные параметры webhook"
+	MsgWebhookLimit    = "Превышено число webhook"
+	MsgWebhookCreation = "Ошибка создания webhook"
+	MsgWebhookUpdate   = "Ошибка обновления webhook"
+	MsgWebhookDeletion = "Ошибка удаления webhook"
 
 	MsgMissingAuthHeader = "Отсутствует заголовок Authorization"
 	MsgInvalidAuthFormat = "Неверный формат токена"
@@ -71,10 +184,67 @@ const (
 
 	MsgServiceCreation = "Ошибка создания сервиса"
 	MsgInvalidData     = "Неверный формат данных"
+	MsgInvalidQuery    = "Неверные параметры запроса"
+	MsgInvalidCursor   = "Некорректный курсор страницы"
 
 	MsgNoteCreated = "Заметка успешно создана"
 	MsgNoteUpdated = "Заметка успешно обновлена"
 	MsgNoteDeleted = "Заметка успешно удалена"
 	MsgNoteFound   = "Заметка найдена"
 	MsgNotesFound  = "Заметки получены"
+	MsgSearchDone  = "Поиск выполнен"
+	MsgTagsFound   = "Теги получены"
+
+	MsgRevisionsFound = "Ревизии получены"
+	MsgRevisionFound  = "Ревизия найдена"
+	MsgDiffCompleted  = "Сравнение ревизий выполнено"
+	MsgNoteRestored   = "Заметка восстановлена из ревизии"
+
+	MsgNoteTrashed           = "Заметка перемещена в корзину"
+	MsgTrashFound            = "Корзина получена"
+	MsgNoteRestoredFromTrash = "Заметка восстановлена из корзины"
+	MsgNoteDeletedForever    = "Заметка удалена окончательно"
+
+	MsgNoteShared   = "Доступ к заметке предоставлен"
+	MsgNoteUnshared = "Доступ к заметке отозван"
+	MsgSharedFound  = "Общие заметки получены"
+
+	MsgLinkCreated = "Публичная ссылка создана"
+	MsgLinksFound  = "Публичные ссылки получены"
+	MsgLinkRevoked = "Публичная ссылка отозвана"
+
+	MsgAttachmentUploaded = "Вложение загружено"
+	MsgAttachmentsFound   = "Вложения получены"
+	MsgAttachmentDeleted  = "Вложение удалено"
+
+	MsgNotebookCreated = "Блокнот создан"
+	MsgNotebooksFound  = "Блокноты получены"
+	MsgNotebookFound   = "Блокнот найден"
+	MsgNotebookUpdated = "Блокнот обновлён"
+	MsgNotebookDeleted = "Блокнот удалён"
+	MsgNoteMoved       = "Заметка перемещена"
+
+	MsgNotePinned     = "Заметка закреплена"
+	MsgNoteUnpinned   = "Заметка откреплена"
+	MsgNoteArchived   = "Заметка перемещена в архив"
+	MsgNoteUnarchived = "Заметка возвращена из архива"
+	MsgArchiveFound   = "Архив получен"
+
+	MsgBatchDone = "Пакет операций выполнен"
+
+	MsgImportDone    = "Импорт выполнен"
+	MsgImportStarted = "Импорт запущен в фоне"
+	MsgImportFound   = "Задача импорта получена"
+
+	MsgReminderSet     = "Напоминание установлено"
+	MsgReminderCleared = "Напоминание удалено"
+	MsgRemindersFound  = "Напоминания получены"
+	MsgInboxFound      = "Уведомления получены"
+
+	MsgWebhookCreated  = "Webhook создан"
+	MsgWebhooksFound   = "Webhook получены"
+	MsgWebhookFound    = "Webhook найден"
+	MsgWebhookUpdated  = "Webhook обновлён"
+	MsgWebhookDeleted  = "Webhook удалён"
+	MsgDeliveriesFound = "Журнал доставок получен"
 )
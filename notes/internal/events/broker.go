@@ -0,0 +1,222 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"notes/internal/caching"
+	"notes/internal/models"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	channelName      = "notes:events"
+	sequenceKey      = "notes:events:seq"
+	replayTTL        = 24 * time.Hour
+	subscriberBuffer = 64
+)
+
+type envelope struct {
+	Audience []int            `json:"audience"`
+	Event    models.NoteEvent `json:"event"`
+}
+
+// Broker рассылает события изменений заметок через Redis pub/sub, чтобы их
+// получали подписчики на всех репликах, и хранит последние события каждого
+// пользователя для возобновления по Last-Event-ID. Пока Redis помечен
+// недоступным, Publish и Replay сразу возвращают ошибку, не задерживая
+// запросы. Брокер без Redis (NewLocalBroker) делает то же в пределах одного
+// процесса.
+type Broker struct {
+	client      *redis.Client
+	health      *caching.RedisHealth
+	pubsub      *redis.PubSub
+	replayLimit int64
+
+	mu          sync.RWMutex
+	subscribers map[int]map[*Subscription]struct{}
+
+	localMu  sync.Mutex
+	sequence int64
+	replay   map[int][]models.NoteEvent
+}
+
+type Subscription struct {
+	broker *Broker
+	userID int
+	events chan models.NoteEvent
+	once   sync.Once
+}
+
+func NewBroker(client *redis.Client, health *caching.RedisHealth, replayLimit int) *Broker {
+	broker := &Broker{
+		client:      client,
+		health:      health,
+		pubsub:      client.Subscribe(channelName),
+		replayLimit: int64(replayLimit),
+		subscribers: make(map[int]map[*Subscription]struct{}),
+	}
+
+	go broker.run()
+
+	return broker
+}
+
+// Publish присваивает событию номер и рассылает его; возвращает событие с
+// номером.
+func (b *Broker) Publish(event models.NoteEvent, audience []int) (models.NoteEvent, error) {
+	if b.client == nil {
+		return b.publishLocal(event, audience), nil
+	}
+	if err := b.health.Check(); err != nil {
+		return event, fmt.Errorf("не удалось опубликовать событие: %w", err)
+	}
+
+	id, err := b.client.Incr(sequenceKey).Result()
+	if err != nil {
+		b.health.Report(err)
+		return event, fmt.Errorf("не удалось получить номер события: %w", err)
+	}
+	event.ID = id
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return event, err
+	}
+
+	_, err = b.client.Pipelined(func(pipe redis.Pipeliner) error {
+		for _, userID := range audience {
+			key := replayKey(userID)
+			pipe.ZAdd(key, redis.Z{Score: float64(id), Member: eventJSON})
+			pipe.ZRemRangeByRank(key, 0, -b.replayLimit-1)
+			pipe.Expire(key, replayTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		b.health.Report(err)
+		return event, fmt.Errorf("не удалось сохранить событие %d: %w", id, err)
+	}
+
+	message, err := json.Marshal(envelope{Audience: audience, Event: event})
+	if err != nil {
+		return event, err
+	}
+
+	err = b.client.Publish(channelName, message).Err()
+	b.health.Report(err)
+	return event, err
+}
+
+func (b *Broker) Replay(userID int, afterID int64) ([]models.NoteEvent, error) {
+	if b.client == nil {
+		return b.replayLocal(userID, afterID), nil
+	}
+	if err := b.health.Check(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать буфер событий: %w", err)
+	}
+
+	values, err := b.client.ZRangeByScore(replayKey(userID), redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(afterID, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		b.health.Report(err)
+		return nil, fmt.Errorf("не удалось прочитать буфер событий: %w", err)
+	}
+
+	events := make([]models.NoteEvent, 0, len(values))
+	for _, value := range values {
+		var event models.NoteEvent
+		if err := json.Unmarshal([]byte(value), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (b *Broker) Subscribe(userID int) *Subscription {
+	subscription := &Subscription{
+		broker: b,
+		userID: userID,
+		events: make(chan models.NoteEvent, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[userID][subscription] = struct{}{}
+	b.mu.Unlock()
+
+	return subscription
+}
+
+func (b *Broker) Close() error {
+	if b.pubsub == nil {
+		return nil
+	}
+	return b.pubsub.Close()
+}
+
+func (b *Broker) run() {
+	for message := range b.pubsub.Channel() {
+		var payload envelope
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			fmt.Printf("Ошибка разбора события заметки: %v\n", err)
+			continue
+		}
+
+		for _, userID := range payload.Audience {
+			b.deliver(userID, payload.Event.VisibleTo(userID))
+		}
+	}
+}
+
+// Медленного подписчика отключаем, а не блокируем рассылку: клиент
+// переподключится и догонит пропущенное по Last-Event-ID.
+func (b *Broker) deliver(userID int, event models.NoteEvent) {
+	b.mu.RLock()
+	var slow []*Subscription
+	for subscription := range b.subscribers[userID] {
+		select {
+		case subscription.events <- event:
+		default:
+			slow = append(slow, subscription)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, subscription := range slow {
+		subscription.Close()
+	}
+}
+
+func (b *Broker) unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	delete(b.subscribers[subscription.userID], subscription)
+	if len(b.subscribers[subscription.userID]) == 0 {
+		delete(b.subscribers, subscription.userID)
+	}
+	b.mu.Unlock()
+}
+
+func (s *Subscription) Events() <-chan models.NoteEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.unsubscribe(s)
+		close(s.events)
+	})
+}
+
+func replayKey(userID int) string {
+	return fmt.Sprintf("notes:events:user:%d", userID)
+}
@@ -0,0 +1,46 @@
+package events
+
+import "notes/internal/models"
+
+func NewLocalBroker(replayLimit int) *Broker {
+	return &Broker{
+		replayLimit: int64(replayLimit),
+		subscribers: make(map[int]map[*Subscription]struct{}),
+		replay:      make(map[int][]models.NoteEvent),
+	}
+}
+
+func (b *Broker) publishLocal(event models.NoteEvent, audience []int) models.NoteEvent {
+	b.localMu.Lock()
+	b.sequence++
+	event.ID = b.sequence
+	for _, userID := range audience {
+		events := append(b.replay[userID], event)
+		if b.replayLimit > 0 && int64(len(events)) > b.replayLimit {
+			events = events[int64(len(events))-b.replayLimit:]
+		}
+		b.replay[userID] = events
+	}
+	b.localMu.Unlock()
+
+	for _, userID := range audience {
+		b.deliver(userID, event.VisibleTo(userID))
+	}
+
+	return event
+}
+
+// Буфер хранит события без урезания под получателя, как и в Redis; Replay
+// отдаёт их как есть, а урезает обработчик SSE.
+func (b *Broker) replayLocal(userID int, afterID int64) []models.NoteEvent {
+	b.localMu.Lock()
+	defer b.localMu.Unlock()
+
+	events := make([]models.NoteEvent, 0)
+	for _, event := range b.replay[userID] {
+		if event.ID > afterID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"notes/internal/errors"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	attachmentFormField      = "file"
+	attachmentMultipartSlack = 1 << 20
+	defaultAttachmentType    = "application/octet-stream"
+)
+
+// inlineAttachmentTypes браузер может показать на месте. Тип задаёт тот, кто
+// загрузил файл, поэтому всё остальное, в том числе text/html и
+// image/svg+xml, отдаётся только на скачивание.
+var inlineAttachmentTypes = map[string]struct{}{
+	"image/png":  {},
+	"image/jpeg": {},
+	"image/gif":  {},
+	"image/webp": {},
+	"text/plain": {},
+}
+
+func (h *Handler) UploadAttachment(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessWrite)
+	if !ok {
+		return
+	}
+
+	maxBody := int64(h.cfg.AttachmentMaxSizeMB)<<20 + attachmentMultipartSlack
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeAttachmentError(c, err)
+			return
+		}
+
+		if part.FormName() != attachmentFormField || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		contentType := part.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(part.FileName()))
+		}
+		if contentType == "" {
+			contentType = defaultAttachmentType
+		}
+
+		attachment, err := h.service.UploadAttachment(ctx, *note, filepath.Base(part.FileName()), contentType, part)
+		part.Close()
+		if err != nil {
+			writeAttachmentError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":    errors.MsgAttachmentUploaded,
+			"attachment": attachment,
+		})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": errors.MsgAttachmentMissing,
+	})
+}
+
+func (h *Handler) GetAttachments(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessRead)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.GetAttachments(ctx, note.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     errors.MsgAttachmentsFound,
+		"attachments": attachments,
+		"count":       len(attachments),
+	})
+}
+
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessRead)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.OpenAttachment(ctx, note.ID, c.Param("attachment_id"))
+	if err != nil {
+		writeAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := mime.FormatMediaType(attachmentDisposition(attachment.ContentType), map[string]string{"filename": attachment.Filename})
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", disposition)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Header("ETag", fmt.Sprintf("%q", attachment.ID))
+	http.ServeContent(c.Writer, c.Request, attachment.Filename, attachment.UploadedAt, content)
+}
+
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessWrite)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(ctx, note.ID, c.Param("attachment_id")); err != nil {
+		writeAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgAttachmentDeleted,
+	})
+}
+
+func attachmentDisposition(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "attachment"
+	}
+	if _, ok := inlineAttachmentTypes[mediaType]; ok {
+		return "inline"
+	}
+	return "attachment"
+}
+
+func writeAttachmentError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case stdErrors.Is(err, errors.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgAttachmentNotFound,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrAttachmentTooLarge), stdErrors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   errors.MsgAttachmentTooLarge,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrAttachmentQuota):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   errors.MsgAttachmentQuota,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrAttachmentUpload):
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgAttachmentUpload,
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import "testing"
+
+func TestAttachmentDisposition(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"image/png", "inline"},
+		{"IMAGE/JPEG", "inline"},
+		{"text/plain; charset=utf-8", "inline"},
+		{"text/html", "attachment"},
+		{"image/svg+xml", "attachment"},
+		{"application/pdf", "attachment"},
+		{"application/octet-stream", "attachment"},
+		{"не тип", "attachment"},
+	}
+	for _, test := range tests {
+		if got := attachmentDisposition(test.contentType); got != test.want {
+			t.Errorf("attachmentDisposition(%q) = %q, ожидалось %q", test.contentType, got, test.want)
+		}
+	}
+}
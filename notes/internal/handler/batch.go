@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) BatchNotes(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var request models.BatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if h.cfg.RequireIfMatch {
+		for i, operation := range request.Operations {
+			if operation.Op != models.BatchOpCreate && operation.Version == 0 {
+				c.JSON(http.StatusPreconditionRequired, gin.H{
+					"error": errors.MsgIfMatchRequired,
+					"index": i,
+				})
+				return
+			}
+		}
+	}
+
+	ctx := context.Background()
+	results, err := h.service.Batch(ctx, authorID, request)
+	if err != nil && !stdErrors.Is(err, errors.ErrBatchAborted) {
+		if stdErrors.Is(err, errors.ErrInvalidBatch) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidBatch,
+				"details": err.Error(),
+			})
+			return
+		}
+		if stdErrors.Is(err, errors.ErrTransactionsUnsupported) {
+			c.JSON(http.StatusNotImplemented, gin.H{
+				"error": errors.MsgTransactionsUnsupported,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response := make([]gin.H, 0, len(results))
+	failed := 0
+	abortStatus := http.StatusOK
+	for _, result := range results {
+		status, message := batchResultStatus(result)
+		item := gin.H{
+			"index":  result.Index,
+			"op":     result.Op,
+			"status": status,
+		}
+		if result.ID != "" {
+			item["id"] = result.ID
+		}
+
+		switch {
+		case result.Err != nil:
+			failed++
+			abortStatus = status
+			item["error"] = message
+			item["details"] = result.Err.Error()
+		case err != nil:
+			item["status"] = http.StatusFailedDependency
+			item["error"] = errors.MsgBatchRolledBack
+		case result.Note != nil:
+			item["note"] = result.Note
+		}
+
+		response = append(response, item)
+	}
+
+	if err != nil {
+		c.JSON(abortStatus, gin.H{
+			"error":   errors.MsgBatchAborted,
+			"details": err.Error(),
+			"results": response,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgBatchDone,
+		"results":   response,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
+
+func batchResultStatus(result models.BatchResult) (int, string) {
+	err := result.Err
+	switch {
+	case err == nil && result.Op == models.BatchOpCreate:
+		return http.StatusCreated, ""
+	case err == nil:
+		return http.StatusOK, ""
+	case stdErrors.Is(err, errors.ErrInvalidBatch):
+		return http.StatusBadRequest, errors.MsgInvalidBatch
+	case stdErrors.Is(err, errors.ErrInvalidTags):
+		return http.StatusBadRequest, errors.MsgInvalidTags
+	case stdErrors.Is(err, errors.ErrInvalidReminder):
+		return http.StatusBadRequest, errors.MsgInvalidReminder
+	case stdErrors.Is(err, errors.ErrInvalidNoteID):
+		return http.StatusBadRequest, errors.MsgInvalidNoteID
+	case stdErrors.Is(err, errors.ErrNoteNotFound):
+		return http.StatusNotFound, errors.MsgNoteNotFound
+	case stdErrors.Is(err, errors.ErrNotebookNotFound):
+		return http.StatusNotFound, errors.MsgNotebookNotFound
+	case stdErrors.Is(err, errors.ErrNoteForbidden):
+		return http.StatusForbidden, errors.MsgNoteForbidden
+	case stdErrors.Is(err, errors.ErrVersionConflict):
+		return http.StatusPreconditionFailed, errors.MsgVersionConflict
+	}
+
+	switch result.Op {
+	case models.BatchOpCreate:
+		return http.StatusInternalServerError, errors.MsgNoteCreation
+	case models.BatchOpDelete:
+		return http.StatusInternalServerError, errors.MsgNoteDeletion
+	default:
+		return http.StatusInternalServerError, errors.MsgNoteUpdate
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"notes/internal/collab"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const collabMaxMessageSize = 1 << 20
+
+// CreateCollabTicket выдаёт короткоживущий билет для открытия WebSocket:
+// браузер не может передать заголовок Authorization при подключении, а
+// JWT в адресе оседал бы в логах прокси на сутки действия токена.
+func (h *Handler) CreateCollabTicket(c *gin.Context) {
+	userID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, userID, accessRead)
+	if !ok {
+		return
+	}
+
+	ticket, expiresAt := collab.NewTicket(h.cfg.JWTSecretKey, note.ID, userID, time.Now())
+	c.JSON(http.StatusCreated, gin.H{
+		"ticket":     ticket,
+		"expires_at": expiresAt,
+	})
+}
+
+func (h *Handler) CollabNote(c *gin.Context) {
+	userID, err := collab.VerifyTicket(h.cfg.JWTSecretKey, c.Query("ticket"), c.Param("id"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgInvalidTicket,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	revision := -1
+	if rawRevision := c.Query("revision"); rawRevision != "" {
+		revision, err = strconv.Atoi(rawRevision)
+		if err != nil || revision < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidQuery,
+				"details": "revision=" + rawRevision,
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, userID, accessRead)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Query("session")
+	clientID := c.Query("client_id")
+	server := websocket.Server{
+		Handshake: func(_ *websocket.Config, request *http.Request) error {
+			if !h.collabOriginAllowed(request) {
+				return errors.ErrForbiddenOrigin
+			}
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			h.serveCollab(conn, *note, userID, sessionID, clientID, revision)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// collabOriginAllowed защищает от cross-site WebSocket hijacking: браузер
+// всегда присылает Origin, и чужие страницы не должны подключаться от имени
+// пользователя. Запрос без Origin приходит не из браузера и пропускается.
+func (h *Handler) collabOriginAllowed(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, request.Host) {
+		return true
+	}
+	for _, allowed := range h.cfg.CollabAllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(strings.TrimSpace(allowed), "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) serveCollab(conn *websocket.Conn, note models.Note, userID int, sessionID string, clientID string, revision int) {
+	defer conn.Close()
+	conn.MaxPayloadBytes = collabMaxMessageSize
+
+	session, client, err := h.collab.Join(note, userID, note.CanWrite(userID), sessionID, clientID, revision)
+	if err != nil {
+		websocket.JSON.Send(conn, collab.ErrorMessage(errors.MsgCollabClosed, err))
+		return
+	}
+	defer session.Leave(client)
+
+	go func() {
+		for message := range client.Messages() {
+			if err := websocket.JSON.Send(conn, message); err != nil {
+				conn.Close()
+			}
+		}
+		conn.Close()
+	}()
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+		session.Receive(client, data)
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"notes/internal/config"
+	"testing"
+)
+
+func TestCollabOriginAllowed(t *testing.T) {
+	h := &Handler{cfg: &config.Config{CollabAllowedOrigins: []string{"https://app.example.com/", " http://localhost:3000"}}}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"https://notes.example.com", true},
+		{"https://NOTES.example.com", true},
+		{"https://app.example.com", true},
+		{"http://localhost:3000", true},
+		{"https://evil.example.com", false},
+		{"http://app.example.com", false},
+		{"null", false},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", "https://notes.example.com/notes/note/1/collab", nil)
+		if test.origin != "" {
+			request.Header.Set("Origin", test.origin)
+		}
+		if got := h.collabOriginAllowed(request); got != test.want {
+			t.Errorf("collabOriginAllowed(%q) = %v, ожидалось %v", test.origin, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func noteETag(note *models.Note) string {
+	return fmt.Sprintf("\"%d\"", note.Version)
+}
+
+func setNoteETag(c *gin.Context, note *models.Note) {
+	if note != nil && note.Version > 0 {
+		c.Header("ETag", noteETag(note))
+	}
+}
+
+func (h *Handler) expectedVersion(ctx context.Context, c *gin.Context, id string, userID int) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if h.cfg.RequireIfMatch {
+			c.JSON(http.StatusPreconditionRequired, gin.H{
+				"error": errors.MsgIfMatchRequired,
+			})
+			return 0, false
+		}
+		return 0, true
+	}
+
+	if header == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), "\""))
+	if err != nil || version <= 0 {
+		h.writeVersionConflict(ctx, c, id, userID)
+		return 0, false
+	}
+
+	return version, true
+}
+
+func notModified(c *gin.Context, note *models.Note) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	return header != "" && strings.TrimPrefix(header, "W/") == noteETag(note)
+}
+
+// writeVersionConflict отдаёт текущую версию заметки так же, как GET:
+// редактор, не являющийся владельцем, не видит список доступа и блокнот.
+func (h *Handler) writeVersionConflict(ctx context.Context, c *gin.Context, id string, userID int) {
+	current, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgNoteNotFound,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	visible := current.VisibleTo(userID)
+	setNoteETag(c, &visible)
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": errors.MsgVersionConflict,
+		"note":  visible,
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const eventsHeartbeatInterval = 25 * time.Second
+
+func (h *Handler) StreamEvents(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidQuery,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Подписываемся до чтения буфера, чтобы не потерять события между
+	// replay и живым потоком; дубликаты отсекаются по номерам отправленных
+	// из буфера событий. Номера выдаёт INCR, но реплики могут опубликовать
+	// их не по порядку, поэтому живые события по максимуму не фильтруются.
+	subscription := h.service.SubscribeEvents(authorID)
+	defer subscription.Close()
+
+	var replay []models.NoteEvent
+	if lastEventID > 0 {
+		replay, err = h.service.ReplayEvents(context.Background(), authorID, lastEventID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   errors.MsgEventsStream,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	replayed := make(map[int64]struct{}, len(replay))
+	for _, event := range replay {
+		if err := writeEvent(c, event.VisibleTo(authorID)); err != nil {
+			return
+		}
+		replayed[event.ID] = struct{}{}
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if _, ok := replayed[event.ID]; ok {
+				delete(replayed, event.ID)
+				continue
+			}
+			if err := writeEvent(c, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func parseLastEventID(c *gin.Context) (int64, error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("Last-Event-ID=%s", raw)
+	}
+	return id, nil
+}
+
+func writeEvent(c *gin.Context, event models.NoteEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
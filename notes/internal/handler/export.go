@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"notes/internal/archive"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportFormatZip  = "zip"
+	exportFormatJSON = "json"
+)
+
+func (h *Handler) ExportNotes(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", exportFormatZip)
+	if format != exportFormatZip && format != exportFormatJSON {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidQuery,
+			"details": fmt.Sprintf("format=%s", format),
+		})
+		return
+	}
+
+	exportedAt := time.Now().UTC().Truncate(time.Millisecond)
+	filename := fmt.Sprintf("notes-%d-%s.%s", authorID, exportedAt.Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// После первого байта статус уже отправлен, поэтому ошибку посреди
+	// выгрузки можно только залогировать и оборвать архив.
+	ctx := context.Background()
+	if format == exportFormatZip {
+		c.Header("Content-Type", "application/zip")
+		c.Status(http.StatusOK)
+		err = h.exportZip(ctx, c.Writer, authorID)
+	} else {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = h.exportJSON(ctx, c.Writer, authorID, exportedAt)
+	}
+
+	if err != nil {
+		fmt.Printf("Ошибка экспорта заметок пользователя с ID %d: %v\n", authorID, err)
+		c.Abort()
+	}
+}
+
+func (h *Handler) exportZip(ctx context.Context, w io.Writer, authorID int) error {
+	archiveWriter := zip.NewWriter(w)
+
+	err := h.service.ExportNotes(ctx, authorID, func(note models.Note) error {
+		header := &zip.FileHeader{
+			Name:     archive.MarkdownFilename(note),
+			Method:   zip.Deflate,
+			Modified: note.UpdatedAt,
+		}
+		file, err := archiveWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		return archive.WriteMarkdown(file, note)
+	})
+	if err != nil {
+		return err
+	}
+
+	return archiveWriter.Close()
+}
+
+func (h *Handler) exportJSON(ctx context.Context, w io.Writer, authorID int, exportedAt time.Time) error {
+	exportedAtJSON, _ := json.Marshal(exportedAt)
+	_, err := fmt.Fprintf(w, `{"format":%q,"version":%d,"exported_at":%s,"author_id":%d,"notes":[`,
+		archive.FormatName, archive.FormatVersion, exportedAtJSON, authorID)
+	if err != nil {
+		return err
+	}
+
+	first := true
+	err = h.service.ExportNotes(ctx, authorID, func(note models.Note) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		noteJSON, err := json.Marshal(note)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(noteJSON)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}")
+	return err
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"context"
+	stdErrors "errors"
+	"fmt"
 	jwtmanager "jwt_manager"
 	"net/http"
+	"notes/internal/collab"
 	"notes/internal/config"
 	"notes/internal/errors"
 	"notes/internal/models"
 	"notes/internal/service"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +21,7 @@ type Handler struct {
 	cfg        *config.Config
 	jwtManager *jwtmanager.JWTManager
 	service    service.Service
+	collab     *collab.Hub
 }
 
 func NewHandler(cfg *config.Config, service service.Service) *Handler {
@@ -30,9 +36,14 @@ func NewHandler(cfg *config.Config, service service.Service) *Handler {
 		cfg:        cfg,
 		jwtManager: jwtManager,
 		service:    service,
+		collab:     collab.NewHub(service, time.Duration(cfg.CollabSaveIntervalSeconds)*time.Second, cfg.CollabHistoryLimit),
 	}
 }
 
+func (h *Handler) Close() {
+	h.collab.Close()
+}
+
 func (h *Handler) CreateNote(c *gin.Context) {
 	authorID, err := h.extractAuthorID(c)
 	if err != nil {
@@ -54,9 +65,31 @@ func (h *Handler) CreateNote(c *gin.Context) {
 
 	note.AuthorID = authorID
 
+	if note.Tags, err = models.NormalizeTags(note.Tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidTags,
+			"details": err.Error(),
+		})
+		return
+	}
+
 	ctx := context.Background()
 	createdNote, err := h.service.Create(ctx, note)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrInvalidReminder) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidReminder,
+				"details": err.Error(),
+			})
+			return
+		}
+		if stdErrors.Is(err, errors.ErrNotebookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   errors.MsgNotebookNotFound,
+				"details": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   errors.MsgNoteCreation,
 			"details": err.Error(),
@@ -64,6 +97,7 @@ func (h *Handler) CreateNote(c *gin.Context) {
 		return
 	}
 
+	setNoteETag(c, createdNote)
 	c.JSON(http.StatusCreated, gin.H{
 		"message": errors.MsgNoteCreated,
 		"note":    createdNote,
@@ -98,11 +132,19 @@ func (h *Handler) GetNoteByID(c *gin.Context) {
 		return
 	}
 
-	if note.AuthorID != authorID {
+	if !note.CanRead(authorID) {
 		c.JSON(http.StatusForbidden, gin.H{})
 		return
 	}
 
+	*note = note.VisibleTo(authorID)
+
+	setNoteETag(c, note)
+	if notModified(c, note) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": errors.MsgNoteFound,
 		"note":    note,
@@ -137,11 +179,16 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 		return
 	}
 
-	if existingNote.AuthorID != authorID {
+	if !existingNote.CanWrite(authorID) {
 		c.JSON(http.StatusForbidden, gin.H{})
 		return
 	}
 
+	version, ok := h.expectedVersion(ctx, c, id, authorID)
+	if !ok {
+		return
+	}
+
 	var note models.Note
 	if err := c.ShouldBindJSON(&note); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -152,10 +199,23 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 	}
 
 	note.ID = id
-	note.AuthorID = authorID
+	note.AuthorID = existingNote.AuthorID
+	note.Version = version
+
+	if note.Tags, err = models.NormalizeTags(note.Tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidTags,
+			"details": err.Error(),
+		})
+		return
+	}
 
 	updatedNote, err := h.service.Update(ctx, note)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			h.writeVersionConflict(ctx, c, id, authorID)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   errors.MsgNoteUpdate,
 			"details": err.Error(),
@@ -163,6 +223,8 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 		return
 	}
 
+	*updatedNote = updatedNote.VisibleTo(authorID)
+	setNoteETag(c, updatedNote)
 	c.JSON(http.StatusOK, gin.H{
 		"message": errors.MsgNoteUpdated,
 		"note":    updatedNote,
@@ -197,13 +259,22 @@ func (h *Handler) DeleteNote(c *gin.Context) {
 		return
 	}
 
-	if existingNote.AuthorID != authorID {
+	if !existingNote.IsOwner(authorID) {
 		c.JSON(http.StatusForbidden, gin.H{})
 		return
 	}
 
-	err = h.service.Delete(ctx, id)
+	version, ok := h.expectedVersion(ctx, c, id, authorID)
+	if !ok {
+		return
+	}
+
+	err = h.service.Delete(ctx, id, version)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			h.writeVersionConflict(ctx, c, id, authorID)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   errors.MsgNoteDeletion,
 			"details": err.Error(),
@@ -212,11 +283,15 @@ func (h *Handler) DeleteNote(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": errors.MsgNoteDeleted,
+		"message": errors.MsgNoteTrashed,
 	})
 }
 
 func (h *Handler) GetAllNotes(c *gin.Context) {
+	h.listNotes(c, false, errors.MsgNotesFound)
+}
+
+func (h *Handler) listNotes(c *gin.Context, archived bool, message string) {
 	authorID, err := h.extractAuthorID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
@@ -226,9 +301,33 @@ func (h *Handler) GetAllNotes(c *gin.Context) {
 		return
 	}
 
+	query, err := parseNoteQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidQuery,
+			"details": err.Error(),
+		})
+		return
+	}
+	query.Archived = archived
+
 	ctx := context.Background()
-	notes, err := h.service.GetAll(ctx, authorID)
+	page, err := h.service.GetAll(ctx, authorID, query)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrInvalidQuery) || stdErrors.Is(err, errors.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidQuery,
+				"details": err.Error(),
+			})
+			return
+		}
+		if stdErrors.Is(err, errors.ErrNotebookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   errors.MsgNotebookNotFound,
+				"details": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   errors.MsgDatabaseOperation,
 			"details": err.Error(),
@@ -237,10 +336,11 @@ func (h *Handler) GetAllNotes(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":   errors.MsgNotesFound,
-		"notes":     notes,
-		"count":     len(notes),
-		"author_id": authorID,
+		"message":     message,
+		"notes":       page.Notes,
+		"count":       len(page.Notes),
+		"next_cursor": page.NextCursor,
+		"author_id":   authorID,
 	})
 }
 
@@ -251,3 +351,81 @@ func (h *Handler) GetJWTMiddleware() gin.HandlerFunc {
 func (h *Handler) extractAuthorID(c *gin.Context) (int, error) {
 	return jwtmanager.GetCurrentUserID(c)
 }
+
+type noteAccess int
+
+const (
+	accessRead noteAccess = iota
+	accessWrite
+	accessOwner
+)
+
+func (h *Handler) getAccessibleNote(ctx context.Context, c *gin.Context, userID int, access noteAccess) (*models.Note, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errors.MsgInvalidNoteID,
+		})
+		return nil, false
+	}
+
+	note, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgNoteNotFound,
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	allowed := note.IsOwner(userID)
+	switch access {
+	case accessRead:
+		allowed = note.CanRead(userID)
+	case accessWrite:
+		allowed = note.CanWrite(userID)
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{})
+		return nil, false
+	}
+
+	return note, true
+}
+
+func parseNoteQuery(c *gin.Context) (models.NoteQuery, error) {
+	query := models.NoteQuery{
+		Cursor:  c.Query("cursor"),
+		SortBy:  c.Query("sort"),
+		Order:   c.Query("order"),
+		Name:    c.Query("name"),
+		TagMode: c.Query("tag_mode"),
+
+		NotebookID: c.Query("notebook"),
+	}
+
+	if rawSubnotebooks := c.Query("subnotebooks"); rawSubnotebooks != "" {
+		includeSubnotebooks, err := strconv.ParseBool(rawSubnotebooks)
+		if err != nil {
+			return query, fmt.Errorf("%w: subnotebooks=%s", errors.ErrInvalidQuery, rawSubnotebooks)
+		}
+		query.IncludeSubnotebooks = includeSubnotebooks
+	}
+
+	tags, err := models.NormalizeTags(c.QueryArray("tag"))
+	if err != nil {
+		return query, fmt.Errorf("%w: %v", errors.ErrInvalidQuery, err)
+	}
+	query.Tags = tags
+
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return query, fmt.Errorf("%w: limit=%s", errors.ErrInvalidQuery, rawLimit)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
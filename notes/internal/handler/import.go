@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"notes/internal/archive"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const importFormField = "file"
+
+func (h *Handler) ImportNotes(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	dryRun := false
+	if rawDryRun := c.Query("dry_run"); rawDryRun != "" {
+		if dryRun, err = strconv.ParseBool(rawDryRun); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidQuery,
+				"details": fmt.Sprintf("dry_run=%s", rawDryRun),
+			})
+			return
+		}
+	}
+
+	maxSize := int64(h.cfg.ImportMaxSizeMB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+attachmentMultipartSlack)
+
+	filename, data, err := readImportUpload(c, maxSize)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stdErrors.As(err, &maxBytesErr) || stdErrors.Is(err, errors.ErrImportTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   errors.MsgImportTooLarge,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidImport,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	format := importFormat(c, filename)
+	if !models.ValidImportFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidImport,
+			"details": fmt.Sprintf("неизвестный формат %q, укажите format=zip|json|enex", format),
+		})
+		return
+	}
+
+	var items []models.ImportItem
+	switch format {
+	case models.ImportFormatZip:
+		items, err = archive.ReadZip(bytes.NewReader(data), int64(len(data)))
+	case models.ImportFormatJSON:
+		items, err = archive.ReadJSON(bytes.NewReader(data))
+	case models.ImportFormatENEX:
+		items, err = archive.ReadENEX(bytes.NewReader(data))
+	}
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrImportTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   errors.MsgImportTooLarge,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidImport,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	job, err := h.service.ImportNotes(ctx, authorID, models.ImportRequest{
+		Format: format,
+		DryRun: dryRun,
+		Items:  items,
+	})
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrInvalidImport) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidImport,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !job.Finished() {
+		c.Header("Location", fmt.Sprintf("/notes/import/%s", job.ID))
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": errors.MsgImportStarted,
+			"job":     job,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgImportDone,
+		"job":     job,
+	})
+}
+
+func (h *Handler) GetImportJob(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	job, err := h.service.GetImportJob(ctx, c.Param("id"))
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   errors.MsgImportJobNotFound,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if job.AuthorID != authorID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": errors.MsgImportJobNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgImportFound,
+		"job":     job,
+	})
+}
+
+func readImportUpload(c *gin.Context, maxSize int64) (string, []byte, error) {
+	var filename string
+	var content io.Reader = c.Request.Body
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader, err := c.Request.MultipartReader()
+		if err != nil {
+			return "", nil, err
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return "", nil, fmt.Errorf("%w: файл не передан в поле %s", errors.ErrInvalidImport, importFormField)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			if part.FormName() == importFormField {
+				filename = part.FileName()
+				content = part
+				break
+			}
+			part.Close()
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, maxSize+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return "", nil, fmt.Errorf("%w: допустимо не больше %d байт", errors.ErrImportTooLarge, maxSize)
+	}
+
+	return filename, data, nil
+}
+
+func importFormat(c *gin.Context, filename string) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		return format
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".zip":
+		return models.ImportFormatZip
+	case ".json":
+		return models.ImportFormatJSON
+	case ".enex":
+		return models.ImportFormatENEX
+	}
+
+	switch c.ContentType() {
+	case "application/zip", "application/x-zip-compressed":
+		return models.ImportFormatZip
+	case "application/json":
+		return models.ImportFormatJSON
+	case "application/enex+xml", "application/xml", "text/xml":
+		return models.ImportFormatENEX
+	}
+
+	return ""
+}
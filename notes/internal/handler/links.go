@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const linkPasswordHeader = "X-Link-Password"
+
+func (h *Handler) CreateLink(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var request models.CreateLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidData,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	link, err := h.service.CreateLink(ctx, *note, request)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrInvalidLink) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidLink,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgLinkCreation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": errors.MsgLinkCreated,
+		"link":    link,
+	})
+}
+
+func (h *Handler) GetLinks(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	links, err := h.service.GetLinks(ctx, note.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgLinksFound,
+		"links":   links,
+		"count":   len(links),
+	})
+}
+
+func (h *Handler) RevokeLink(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeLink(ctx, note.ID, c.Param("token")); err != nil {
+		if stdErrors.Is(err, errors.ErrLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   errors.MsgLinkNotFound,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgLinkRevoked,
+	})
+}
+
+func (h *Handler) OpenPublicLink(c *gin.Context) {
+	ctx := context.Background()
+	note, link, err := h.service.OpenLink(ctx, c.Param("token"), c.GetHeader(linkPasswordHeader))
+	if err != nil {
+		switch {
+		case stdErrors.Is(err, errors.ErrLinkNotFound), stdErrors.Is(err, errors.ErrNoteNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": errors.MsgLinkNotFound,
+			})
+		case stdErrors.Is(err, errors.ErrLinkExpired):
+			c.JSON(http.StatusGone, gin.H{
+				"error": errors.MsgLinkExpired,
+			})
+		case stdErrors.Is(err, errors.ErrLinkPassword):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": errors.MsgLinkPassword,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   errors.MsgDatabaseOperation,
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	response := gin.H{
+		"message": errors.MsgNoteFound,
+		"note":    models.PublicNoteFromNote(*note),
+		"views":   link.Views,
+	}
+	if !link.ExpiresAt.IsZero() {
+		response["expires_at"] = link.ExpiresAt
+	}
+
+	c.JSON(http.StatusOK, response)
+}
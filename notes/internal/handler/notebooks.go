@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreateNotebook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var notebook models.Notebook
+	if err := c.ShouldBindJSON(&notebook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	notebook.ID = ""
+	notebook.AuthorID = authorID
+
+	ctx := context.Background()
+	createdNotebook, err := h.service.CreateNotebook(ctx, notebook)
+	if err != nil {
+		writeNotebookError(c, err, errors.MsgNotebookCreation)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  errors.MsgNotebookCreated,
+		"notebook": createdNotebook,
+	})
+}
+
+func (h *Handler) GetNotebooks(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	notebooks, err := h.service.GetNotebooks(ctx, authorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgNotebooksFound,
+		"notebooks": notebooks,
+		"count":     len(notebooks),
+	})
+}
+
+func (h *Handler) GetNotebook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	notebook, ok := h.getOwnedNotebook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  errors.MsgNotebookFound,
+		"notebook": notebook,
+	})
+}
+
+func (h *Handler) UpdateNotebook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	existingNotebook, ok := h.getOwnedNotebook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	var notebook models.Notebook
+	if err := c.ShouldBindJSON(&notebook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	notebook.ID = existingNotebook.ID
+	notebook.AuthorID = existingNotebook.AuthorID
+
+	updatedNotebook, err := h.service.UpdateNotebook(ctx, notebook)
+	if err != nil {
+		writeNotebookError(c, err, errors.MsgNotebookUpdate)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  errors.MsgNotebookUpdated,
+		"notebook": updatedNotebook,
+	})
+}
+
+func (h *Handler) DeleteNotebook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	notebook, ok := h.getOwnedNotebook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	mode := c.DefaultQuery("mode", models.NotebookDeleteReparent)
+	if err := h.service.DeleteNotebook(ctx, *notebook, mode); err != nil {
+		writeNotebookError(c, err, errors.MsgNotebookDeletion)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNotebookDeleted,
+		"mode":    mode,
+	})
+}
+
+func (h *Handler) MoveNote(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var request models.MoveNoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	version, ok := h.expectedVersion(ctx, c, note.ID, authorID)
+	if !ok {
+		return
+	}
+
+	movedNote, err := h.service.MoveNote(ctx, note.ID, request.NotebookID, version)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			h.writeVersionConflict(ctx, c, note.ID, authorID)
+			return
+		}
+		writeNotebookError(c, err, errors.MsgNoteUpdate)
+		return
+	}
+
+	setNoteETag(c, movedNote)
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteMoved,
+		"note":    movedNote,
+	})
+}
+
+func (h *Handler) getOwnedNotebook(ctx context.Context, c *gin.Context, userID int) (*models.Notebook, bool) {
+	notebook, err := h.service.GetNotebook(ctx, c.Param("id"))
+	if err != nil {
+		writeNotebookError(c, err, errors.MsgDatabaseOperation)
+		return nil, false
+	}
+
+	if notebook.AuthorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{})
+		return nil, false
+	}
+
+	return notebook, true
+}
+
+func writeNotebookError(c *gin.Context, err error, message string) {
+	switch {
+	case stdErrors.Is(err, errors.ErrNotebookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgNotebookNotFound,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrInvalidNotebook):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidNotebook,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrNoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgNoteNotFound,
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
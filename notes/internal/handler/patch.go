@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"notes/internal/patch"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) PatchNote(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	existingNote, ok := h.getAccessibleNote(ctx, c, authorID, accessWrite)
+	if !ok {
+		return
+	}
+
+	version, ok := h.expectedVersion(ctx, c, existingNote.ID, authorID)
+	if !ok {
+		return
+	}
+	if version == 0 {
+		version = existingNote.Version
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	patched, err := applyNotePatch(c.ContentType(), *existingNote, body)
+	if err != nil {
+		switch {
+		case stdErrors.Is(err, errors.ErrReadOnlyField):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   errors.MsgReadOnlyField,
+				"details": err.Error(),
+			})
+		case stdErrors.Is(err, errors.ErrDedicatedField):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   errors.MsgDedicatedField,
+				"details": err.Error(),
+			})
+		case stdErrors.Is(err, errors.ErrPatchTestFailed):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   errors.MsgPatchTestFailed,
+				"details": err.Error(),
+			})
+		case stdErrors.Is(err, errors.ErrUnsupportedPatch):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"error":   errors.MsgUnsupportedPatch,
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidPatch,
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	note := models.Note{
+		ID:       existingNote.ID,
+		AuthorID: existingNote.AuthorID,
+		Version:  version,
+	}
+	patched.ApplyTo(&note)
+
+	if note.Tags, err = models.NormalizeTags(note.Tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidTags,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updatedNote, err := h.service.Update(ctx, note)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			h.writeVersionConflict(ctx, c, existingNote.ID, authorID)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteUpdate,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	*updatedNote = updatedNote.VisibleTo(authorID)
+	setNoteETag(c, updatedNote)
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteUpdated,
+		"note":    updatedNote,
+	})
+}
+
+func applyNotePatch(contentType string, note models.Note, body []byte) (*models.NotePatchDocument, error) {
+	target, err := json.Marshal(models.PatchDocumentFromNote(note))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+
+	var result []byte
+	switch contentType {
+	case patch.MergePatchContentType, "application/json", "":
+		result, err = applyMergePatch(target, body)
+	case patch.JSONPatchContentType:
+		result, err = applyJSONPatch(target, body)
+	default:
+		err = fmt.Errorf("%w: %s", errors.ErrUnsupportedPatch, contentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.DisallowUnknownFields()
+
+	var patched models.NotePatchDocument
+	if err := decoder.Decode(&patched); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+
+	return &patched, nil
+}
+
+func applyMergePatch(target, body []byte) ([]byte, error) {
+	fields, err := patch.MergePatchFields(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEditableFields(fields); err != nil {
+		return nil, err
+	}
+	return patch.MergePatch(target, body)
+}
+
+func applyJSONPatch(target, body []byte) ([]byte, error) {
+	operations, err := patch.DecodeOperations(body)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := patch.OperationFields(operations)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEditableFields(fields); err != nil {
+		return nil, err
+	}
+	return patch.ApplyJSONPatch(target, operations)
+}
+
+func checkEditableFields(fields []string) error {
+	for _, field := range fields {
+		if route, ok := models.DedicatedNoteFieldRoute(field); ok {
+			return fmt.Errorf("%w: %s, используйте %s", errors.ErrDedicatedField, field, route)
+		}
+		if !models.IsEditableNoteField(field) {
+			return fmt.Errorf("%w: %s", errors.ErrReadOnlyField, field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	stdErrors "errors"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"notes/internal/patch"
+	"slices"
+	"testing"
+)
+
+func TestApplyNotePatch(t *testing.T) {
+	note := models.Note{Name: "План", Content: "купить хлеб", Tags: []string{"дом"}}
+
+	patched, err := applyNotePatch(patch.MergePatchContentType, note, []byte(`{"content":"купить молоко","tags":null}`))
+	if err != nil {
+		t.Fatalf("merge patch: %v", err)
+	}
+	if patched.Name != "План" || patched.Content != "купить молоко" || patched.Tags != nil {
+		t.Fatalf("после merge patch %+v", patched)
+	}
+
+	patched, err = applyNotePatch(patch.JSONPatchContentType, note, []byte(`[{"op":"add","path":"/tags/-","value":"покупки"}]`))
+	if err != nil {
+		t.Fatalf("JSON Patch: %v", err)
+	}
+	if !slices.Equal(patched.Tags, []string{"дом", "покупки"}) {
+		t.Fatalf("теги после JSON Patch %v", patched.Tags)
+	}
+}
+
+func TestApplyNotePatchErrors(t *testing.T) {
+	note := models.Note{Name: "План"}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        error
+	}{
+		{"служебное поле", patch.MergePatchContentType, `{"version":3}`, errors.ErrReadOnlyField},
+		{"закрепление", patch.MergePatchContentType, `{"pinned":true}`, errors.ErrDedicatedField},
+		{"блокнот", patch.JSONPatchContentType, `[{"op":"replace","path":"/notebook_id","value":"x"}]`, errors.ErrDedicatedField},
+		{"напоминание", patch.JSONPatchContentType, `[{"op":"add","path":"/remind_at","value":"2030-01-01T00:00:00Z"}]`, errors.ErrDedicatedField},
+		{"неизвестный тип", "text/plain", `{}`, errors.ErrUnsupportedPatch},
+		{"test не пройден", patch.JSONPatchContentType, `[{"op":"test","path":"/name","value":"Другое"}]`, errors.ErrPatchTestFailed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := applyNotePatch(test.contentType, note, []byte(test.body)); !stdErrors.Is(err, test.want) {
+				t.Fatalf("applyNotePatch: %v, ожидалась %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRemindersLimit = 20
+
+func (h *Handler) SetReminder(c *gin.Context) {
+	var request models.ReminderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if request.RemindAt.IsZero() && request.DueAt.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidReminder,
+			"details": "нужно указать remind_at или due_at",
+		})
+		return
+	}
+
+	if err := models.ValidateReminder(models.Note{RemindAt: request.RemindAt, Recurrence: request.Recurrence}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidReminder,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.updateNoteState(c, func(ctx context.Context, noteID string, version int) (*models.Note, error) {
+		return h.service.SetReminder(ctx, noteID, request, version)
+	}, errors.MsgReminderSet)
+}
+
+func (h *Handler) ClearReminder(c *gin.Context) {
+	h.updateNoteState(c, func(ctx context.Context, noteID string, version int) (*models.Note, error) {
+		return h.service.SetReminder(ctx, noteID, models.ReminderRequest{}, version)
+	}, errors.MsgReminderCleared)
+}
+
+func (h *Handler) GetUpcomingReminders(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	limit, err := parseRemindersLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidQuery,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var until time.Time
+	if rawUntil := c.Query("until"); rawUntil != "" {
+		until, err = time.Parse(time.RFC3339, rawUntil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidQuery,
+				"details": fmt.Sprintf("until=%s", rawUntil),
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	reminders, err := h.service.GetUpcomingReminders(ctx, authorID, until, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgRemindersFound,
+		"reminders": reminders,
+		"count":     len(reminders),
+	})
+}
+
+func (h *Handler) GetInbox(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	limit, err := parseRemindersLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidQuery,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	items, err := h.service.GetInbox(ctx, authorID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgInboxFound,
+		"items":   items,
+		"count":   len(items),
+	})
+}
+
+func parseRemindersLimit(c *gin.Context) (int, error) {
+	rawLimit := c.Query("limit")
+	if rawLimit == "" {
+		return defaultRemindersLimit, nil
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%w: limit=%s", errors.ErrInvalidQuery, rawLimit)
+	}
+	return min(limit, models.MaxUpcomingReminders), nil
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"notes/internal/diff"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetRevisions(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessRead)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service.GetRevisions(ctx, note.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         errors.MsgRevisionsFound,
+		"revisions":       revisions,
+		"count":           len(revisions),
+		"current_version": note.Version,
+	})
+}
+
+func (h *Handler) GetRevision(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	version, err := parseRevision(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidRevision,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessRead)
+	if !ok {
+		return
+	}
+
+	revision, err := h.service.GetRevision(ctx, note.ID, version)
+	if err != nil {
+		h.writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  errors.MsgRevisionFound,
+		"revision": revision,
+	})
+}
+
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessRead)
+	if !ok {
+		return
+	}
+
+	to := note.Version
+	if rawTo := c.Query("to"); rawTo != "" {
+		if to, err = parseRevision(rawTo); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidRevision,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	from := to - 1
+	if rawFrom := c.Query("from"); rawFrom != "" {
+		if from, err = parseRevision(rawFrom); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidRevision,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	// У первой версии нет предыдущей: по умолчанию она сравнивается с пустой
+	// заметкой.
+	fromRevision := &models.NoteRevision{}
+	if from > 0 {
+		if fromRevision, err = h.service.GetRevision(ctx, note.ID, from); err != nil {
+			h.writeRevisionError(c, err)
+			return
+		}
+	}
+
+	toRevision, err := h.service.GetRevision(ctx, note.ID, to)
+	if err != nil {
+		h.writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgDiffCompleted,
+		"from":      from,
+		"to":        to,
+		"from_name": fromRevision.Name,
+		"to_name":   toRevision.Name,
+		"diff": diff.Unified(
+			fmt.Sprintf("%s@%d", note.ID, from),
+			fmt.Sprintf("%s@%d", note.ID, to),
+			fromRevision.Content,
+			toRevision.Content,
+		),
+	})
+}
+
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	version, err := parseRevision(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidRevision,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessWrite)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.expectedVersion(ctx, c, note.ID, authorID)
+	if !ok {
+		return
+	}
+
+	restoredNote, err := h.service.RestoreRevision(ctx, note.ID, version, expectedVersion)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrRevisionNotFound) || stdErrors.Is(err, errors.ErrInvalidRevision) {
+			h.writeRevisionError(c, err)
+			return
+		}
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			h.writeVersionConflict(ctx, c, note.ID, authorID)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteRestore,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	*restoredNote = restoredNote.VisibleTo(authorID)
+	setNoteETag(c, restoredNote)
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteRestored,
+		"note":    restoredNote,
+	})
+}
+
+func (h *Handler) writeRevisionError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrInvalidRevision):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidRevision,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgRevisionNotFound,
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+	}
+}
+
+func parseRevision(raw string) (int, error) {
+	version, err := strconv.Atoi(raw)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("%w: %s", errors.ErrInvalidRevision, raw)
+	}
+	return version, nil
+}
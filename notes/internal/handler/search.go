@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) SearchNotes(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	query := models.SearchQuery{Text: c.Query("q")}
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidQuery,
+				"details": "limit=" + rawLimit,
+			})
+			return
+		}
+		query.Limit = limit
+	}
+
+	ctx := context.Background()
+	results, err := h.service.Search(ctx, authorID, query)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidQuery,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgSearchDone,
+		"results":   results,
+		"count":     len(results),
+		"query":     query.Text,
+		"author_id": authorID,
+	})
+}
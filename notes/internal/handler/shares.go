@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ShareNote(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var share models.NoteShare
+	if err := c.ShouldBindJSON(&share); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !share.Valid() || share.UserID == authorID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errors.MsgInvalidShare,
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	sharedNote, err := h.service.ShareNote(ctx, note.ID, share)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrInvalidShare) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   errors.MsgInvalidShare,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteShare,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteShared,
+		"note":    sharedNote,
+	})
+}
+
+func (h *Handler) UnshareNote(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Query("user_id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errors.MsgInvalidShare,
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	unsharedNote, err := h.service.UnshareNote(ctx, note.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteShare,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteUnshared,
+		"note":    unsharedNote,
+	})
+}
+
+func (h *Handler) GetSharedNotes(c *gin.Context) {
+	userID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	notes, err := h.service.GetShared(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for i := range notes {
+		notes[i] = notes[i].VisibleTo(userID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgSharedFound,
+		"notes":   notes,
+		"count":   len(notes),
+		"user_id": userID,
+	})
+}
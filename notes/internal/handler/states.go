@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type noteStateUpdate func(ctx context.Context, noteID string, version int) (*models.Note, error)
+
+func (h *Handler) PinNote(c *gin.Context) {
+	h.updateNoteState(c, func(ctx context.Context, noteID string, version int) (*models.Note, error) {
+		return h.service.SetPinned(ctx, noteID, true, version)
+	}, errors.MsgNotePinned)
+}
+
+func (h *Handler) UnpinNote(c *gin.Context) {
+	h.updateNoteState(c, func(ctx context.Context, noteID string, version int) (*models.Note, error) {
+		return h.service.SetPinned(ctx, noteID, false, version)
+	}, errors.MsgNoteUnpinned)
+}
+
+func (h *Handler) ArchiveNote(c *gin.Context) {
+	h.updateNoteState(c, func(ctx context.Context, noteID string, version int) (*models.Note, error) {
+		return h.service.SetArchived(ctx, noteID, true, version)
+	}, errors.MsgNoteArchived)
+}
+
+func (h *Handler) UnarchiveNote(c *gin.Context) {
+	h.updateNoteState(c, func(ctx context.Context, noteID string, version int) (*models.Note, error) {
+		return h.service.SetArchived(ctx, noteID, false, version)
+	}, errors.MsgNoteUnarchived)
+}
+
+func (h *Handler) GetArchive(c *gin.Context) {
+	h.listNotes(c, true, errors.MsgArchiveFound)
+}
+
+func (h *Handler) updateNoteState(c *gin.Context, update noteStateUpdate, message string) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getAccessibleNote(ctx, c, authorID, accessOwner)
+	if !ok {
+		return
+	}
+
+	version, ok := h.expectedVersion(ctx, c, note.ID, authorID)
+	if !ok {
+		return
+	}
+
+	updatedNote, err := update(ctx, note.ID, version)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			h.writeVersionConflict(ctx, c, note.ID, authorID)
+			return
+		}
+		if stdErrors.Is(err, errors.ErrNoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   errors.MsgNoteNotFound,
+				"details": err.Error(),
+			})
+			return
+		}
+		if stdErrors.Is(err, errors.ErrPinArchived) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   errors.MsgPinArchived,
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteUpdate,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	setNoteETag(c, updatedNote)
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"note":    updatedNote,
+	})
+}
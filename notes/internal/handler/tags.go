@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"notes/internal/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetTags(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	tags, err := h.service.GetTags(ctx, authorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgTagsFound,
+		"tags":      tags,
+		"count":     len(tags),
+		"author_id": authorID,
+	})
+}
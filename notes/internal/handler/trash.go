@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetTrash(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	notes, err := h.service.GetTrash(ctx, authorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   errors.MsgTrashFound,
+		"notes":     notes,
+		"count":     len(notes),
+		"author_id": authorID,
+	})
+}
+
+func (h *Handler) RestoreFromTrash(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getOwnedTrashedNote(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	restoredNote, err := h.service.RestoreFromTrash(ctx, note.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteRestore,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteRestoredFromTrash,
+		"note":    restoredNote,
+	})
+}
+
+func (h *Handler) DeleteFromTrash(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	note, ok := h.getOwnedTrashedNote(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePermanently(ctx, note.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgNoteDeletion,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgNoteDeletedForever,
+	})
+}
+
+func (h *Handler) getOwnedTrashedNote(ctx context.Context, c *gin.Context, authorID int) (*models.Note, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errors.MsgInvalidNoteID,
+		})
+		return nil, false
+	}
+
+	note, err := h.service.GetTrashedByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgNoteNotFound,
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	if note.AuthorID != authorID {
+		c.JSON(http.StatusForbidden, gin.H{})
+		return nil, false
+	}
+
+	return note, true
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultDeliveriesLimit = 20
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var request models.WebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	webhook, err := h.service.CreateWebhook(ctx, models.Webhook{
+		AuthorID: authorID,
+		URL:      request.URL,
+		Events:   request.Events,
+	})
+	if err != nil {
+		writeWebhookError(c, err, errors.MsgWebhookCreation)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": errors.MsgWebhookCreated,
+		"webhook": webhook,
+	})
+}
+
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	webhooks, err := h.service.GetWebhooks(ctx, authorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  errors.MsgWebhooksFound,
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	webhook, ok := h.getOwnedWebhook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgWebhookFound,
+		"webhook": webhook,
+	})
+}
+
+// Незаданные в запросе поля остаются прежними.
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	webhook, ok := h.getOwnedWebhook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	var request models.WebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidData,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if request.URL != "" {
+		webhook.URL = request.URL
+	}
+	if request.Events != nil {
+		webhook.Events = request.Events
+	}
+	if request.Active != nil {
+		webhook.Active = *request.Active
+	}
+
+	updatedWebhook, err := h.service.UpdateWebhook(ctx, *webhook)
+	if err != nil {
+		writeWebhookError(c, err, errors.MsgWebhookUpdate)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgWebhookUpdated,
+		"webhook": updatedWebhook,
+	})
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	webhook, ok := h.getOwnedWebhook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(ctx, webhook.ID); err != nil {
+		writeWebhookError(c, err, errors.MsgWebhookDeletion)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": errors.MsgWebhookDeleted,
+	})
+}
+
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	authorID, err := h.extractAuthorID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   errors.MsgMissingUserID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	limit, err := parseDeliveriesLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidQuery,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	webhook, ok := h.getOwnedWebhook(ctx, c, authorID)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.service.GetWebhookDeliveries(ctx, webhook.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   errors.MsgDatabaseOperation,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    errors.MsgDeliveriesFound,
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+func (h *Handler) getOwnedWebhook(ctx context.Context, c *gin.Context, userID int) (*models.Webhook, bool) {
+	webhook, err := h.service.GetWebhook(ctx, c.Param("id"))
+	if err != nil {
+		writeWebhookError(c, err, errors.MsgDatabaseOperation)
+		return nil, false
+	}
+
+	// Чужой webhook не отличаем от несуществующего.
+	if webhook.AuthorID != userID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": errors.MsgWebhookNotFound,
+		})
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+func writeWebhookError(c *gin.Context, err error, message string) {
+	switch {
+	case stdErrors.Is(err, errors.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   errors.MsgWebhookNotFound,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   errors.MsgInvalidWebhook,
+			"details": err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrWebhookLimit):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   errors.MsgWebhookLimit,
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+func parseDeliveriesLimit(c *gin.Context) (int, error) {
+	rawLimit := c.Query("limit")
+	if rawLimit == "" {
+		return defaultDeliveriesLimit, nil
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%w: limit=%s", errors.ErrInvalidQuery, rawLimit)
+	}
+	return min(limit, models.MaxWebhookDeliveries), nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	ID          string    `json:"id"`
+	NoteID      string    `json:"note_id"`
+	AuthorID    int       `json:"author_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
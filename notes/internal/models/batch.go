@@ -0,0 +1,40 @@
+package models
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+
+	MaxBatchOperations = 500
+)
+
+type BatchOperation struct {
+	Op      string `json:"op"`
+	ID      string `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Note    Note   `json:"note"`
+}
+
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchResult struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	ID    string `json:"id,omitempty"`
+	Note  *Note  `json:"note,omitempty"`
+	Err   error  `json:"-"`
+}
+
+func (o BatchOperation) Valid() bool {
+	switch o.Op {
+	case BatchOpCreate:
+		return true
+	case BatchOpUpdate, BatchOpDelete:
+		return o.ID != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	NoteEventCreated = "note.created"
+	NoteEventUpdated = "note.updated"
+	NoteEventDeleted = "note.deleted"
+)
+
+type NoteEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	NoteID     string    `json:"note_id"`
+	AuthorID   int       `json:"author_id"`
+	Note       *Note     `json:"note,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e NoteEvent) VisibleTo(userID int) NoteEvent {
+	if e.Note == nil || e.AuthorID == userID {
+		return e
+	}
+
+	note := e.Note.VisibleTo(userID)
+	e.Note = &note
+	return e
+}
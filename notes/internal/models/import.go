@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+const (
+	ImportFormatZip  = "zip"
+	ImportFormatJSON = "json"
+	ImportFormatENEX = "enex"
+
+	ImportStatusPending = "pending"
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusFailed  = "failed"
+
+	ImportItemCreated     = "created"
+	ImportItemWouldCreate = "would_create"
+	ImportItemDuplicate   = "duplicate"
+	ImportItemFailed      = "failed"
+
+	MaxImportItems = 10000
+)
+
+type ImportItem struct {
+	Source string
+	Note   Note
+	Error  string
+}
+
+type ImportRequest struct {
+	Format string
+	DryRun bool
+	Items  []ImportItem
+}
+
+type ImportItemReport struct {
+	Index       int    `json:"index" bson:"index"`
+	Source      string `json:"source" bson:"source"`
+	Name        string `json:"name,omitempty" bson:"name,omitempty"`
+	Status      string `json:"status" bson:"status"`
+	NoteID      string `json:"note_id,omitempty" bson:"note_id,omitempty"`
+	DuplicateOf string `json:"duplicate_of,omitempty" bson:"duplicate_of,omitempty"`
+	Error       string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+type ImportJob struct {
+	ID         string             `json:"id" bson:"id,omitempty"`
+	AuthorID   int                `json:"author_id" bson:"author_id"`
+	Format     string             `json:"format" bson:"format"`
+	DryRun     bool               `json:"dry_run" bson:"dry_run"`
+	Status     string             `json:"status" bson:"status"`
+	Total      int                `json:"total" bson:"total"`
+	Processed  int                `json:"processed" bson:"processed"`
+	Created    int                `json:"created" bson:"created"`
+	Duplicates int                `json:"duplicates" bson:"duplicates"`
+	Failed     int                `json:"failed" bson:"failed"`
+	Items      []ImportItemReport `json:"items,omitempty" bson:"items,omitempty"`
+	Error      string             `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at,omitzero" bson:"updated_at,omitempty"`
+	FinishedAt time.Time          `json:"finished_at,omitzero" bson:"finished_at,omitempty"`
+}
+
+func ValidImportFormat(format string) bool {
+	return format == ImportFormatZip || format == ImportFormatJSON || format == ImportFormatENEX
+}
+
+func (j ImportJob) Finished() bool {
+	return j.Status == ImportStatusDone || j.Status == ImportStatusFailed
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type ShareLink struct {
+	Token        string    `json:"token" bson:"token"`
+	NoteID       string    `json:"note_id" bson:"note_id"`
+	AuthorID     int       `json:"author_id" bson:"author_id"`
+	PasswordHash string    `json:"-" bson:"password_hash,omitempty"`
+	Protected    bool      `json:"protected" bson:"protected"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero" bson:"expires_at,omitempty"`
+	Views        int64     `json:"views" bson:"views"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
+
+type CreateLinkRequest struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Password  string    `json:"password"`
+}
+
+type PublicNote struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+func (l ShareLink) Expired(now time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !now.Before(l.ExpiresAt)
+}
+
+func PublicNoteFromNote(note Note) PublicNote {
+	return PublicNote{
+		ID:        note.ID,
+		Name:      note.Name,
+		Content:   note.Content,
+		Tags:      note.Tags,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+	}
+}
@@ -1,8 +1,22 @@
 package models
 
+import "time"
+
 type Note struct {
-	ID       string `json:"id,omitempty" bson:"id,omitempty" `
-	Name     string `json:"name,omitempty" bson:"name,omitempty"`
-	Content  string `json:"content,omitempty" bson:"content,omitempty"`
-	AuthorID int    `json:"author_id,omitempty" bson:"author_id,omitempty"`
+	ID         string      `json:"id,omitempty" bson:"id,omitempty" `
+	Name       string      `json:"name,omitempty" bson:"name,omitempty"`
+	Content    string      `json:"content,omitempty" bson:"content,omitempty"`
+	AuthorID   int         `json:"author_id,omitempty" bson:"author_id,omitempty"`
+	Tags       []string    `json:"tags,omitempty" bson:"tags,omitempty"`
+	NotebookID string      `json:"notebook_id,omitempty" bson:"notebook_id,omitempty"`
+	Pinned     bool        `json:"pinned,omitempty" bson:"pinned,omitempty"`
+	Archived   bool        `json:"archived,omitempty" bson:"archived,omitempty"`
+	Shares     []NoteShare `json:"shares,omitempty" bson:"shares,omitempty"`
+	RemindAt   time.Time   `json:"remind_at,omitzero" bson:"remind_at,omitempty"`
+	DueAt      time.Time   `json:"due_at,omitzero" bson:"due_at,omitempty"`
+	Recurrence string      `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	CreatedAt  time.Time   `json:"created_at,omitzero" bson:"created_at,omitempty"`
+	UpdatedAt  time.Time   `json:"updated_at,omitzero" bson:"updated_at,omitempty"`
+	Version    int         `json:"version,omitempty" bson:"version,omitempty"`
+	DeletedAt  time.Time   `json:"deleted_at,omitzero" bson:"deleted_at,omitempty"`
 }
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	NotebookDeleteReparent = "reparent"
+	NotebookDeleteCascade  = "cascade"
+
+	MaxNotebookNameLength = 128
+)
+
+type Notebook struct {
+	ID        string    `json:"id,omitempty" bson:"id,omitempty"`
+	Name      string    `json:"name" bson:"name"`
+	ParentID  string    `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	AuthorID  int       `json:"author_id,omitempty" bson:"author_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero" bson:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero" bson:"updated_at,omitempty"`
+}
+
+type MoveNoteRequest struct {
+	NotebookID string `json:"notebook_id"`
+}
+
+// Valid не даёт блокноту стать родителем самому себе; у нового блокнота ID
+// ещё пустой, поэтому для него проверяется только название.
+func (n Notebook) Valid() bool {
+	length := len([]rune(n.Name))
+	return length > 0 && length <= MaxNotebookNameLength && (n.ID == "" || n.ParentID != n.ID)
+}
+
+func ValidNotebookDeleteMode(mode string) bool {
+	return mode == NotebookDeleteReparent || mode == NotebookDeleteCascade
+}
+
+// Потомки считаются по плоскому списку блокнотов автора: вложенность
+// небольшая, и так не нужен $graphLookup на каждый запрос.
+func NotebookDescendants(notebooks []Notebook, rootID string) []string {
+	children := make(map[string][]string, len(notebooks))
+	for _, notebook := range notebooks {
+		children[notebook.ParentID] = append(children[notebook.ParentID], notebook.ID)
+	}
+
+	ids := []string{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNotebookValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		notebook Notebook
+		want     bool
+	}{
+		{"новый корневой", Notebook{Name: "Работа"}, true},
+		{"новый вложенный", Notebook{Name: "Проекты", ParentID: "665f1c2e8b3a4d0012345678"}, true},
+		{"переименование", Notebook{ID: "665f1c2e8b3a4d0012345678", Name: "Дом"}, true},
+		{"родитель сам себе", Notebook{ID: "665f1c2e8b3a4d0012345678", Name: "Дом", ParentID: "665f1c2e8b3a4d0012345678"}, false},
+		{"пустое название", Notebook{}, false},
+		{"длинное название", Notebook{Name: strings.Repeat("я", MaxNotebookNameLength+1)}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.notebook.Valid(); got != test.want {
+				t.Fatalf("Valid() = %v, ожидалось %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNotebookDescendants(t *testing.T) {
+	notebooks := []Notebook{
+		{ID: "a"},
+		{ID: "b", ParentID: "a"},
+		{ID: "c", ParentID: "b"},
+		{ID: "d"},
+	}
+
+	got := NotebookDescendants(notebooks, "a")
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Fatalf("NotebookDescendants = %v, ожидалось %v", got, want)
+	}
+}
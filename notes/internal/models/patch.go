@@ -0,0 +1,55 @@
+package models
+
+var editableNoteFields = map[string]struct{}{
+	"name":    {},
+	"content": {},
+	"tags":    {},
+}
+
+// dedicatedNoteFields меняются только своими запросами: у них отдельные
+// проверки (блокнот автора, правило повторения) и события, поэтому PATCH их
+// не принимает, а подсказывает нужный запрос.
+var dedicatedNoteFields = map[string]string{
+	"notebook_id": "PUT /notes/note/:id/notebook",
+	"pinned":      "PUT и DELETE /notes/note/:id/pin",
+	"archived":    "PUT и DELETE /notes/note/:id/archive",
+	"remind_at":   "PUT и DELETE /notes/note/:id/reminder",
+	"due_at":      "PUT и DELETE /notes/note/:id/reminder",
+	"recurrence":  "PUT и DELETE /notes/note/:id/reminder",
+}
+
+type NotePatchDocument struct {
+	Name    string   `json:"name"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+}
+
+func IsEditableNoteField(field string) bool {
+	_, ok := editableNoteFields[field]
+	return ok
+}
+
+// DedicatedNoteFieldRoute возвращает запрос, которым меняется поле, если
+// PATCH его не принимает.
+func DedicatedNoteFieldRoute(field string) (string, bool) {
+	route, ok := dedicatedNoteFields[field]
+	return route, ok
+}
+
+func PatchDocumentFromNote(note Note) NotePatchDocument {
+	tags := note.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return NotePatchDocument{
+		Name:    note.Name,
+		Content: note.Content,
+		Tags:    tags,
+	}
+}
+
+func (d NotePatchDocument) ApplyTo(note *Note) {
+	note.Name = d.Name
+	note.Content = d.Content
+	note.Tags = d.Tags
+}
@@ -0,0 +1,63 @@
+package models
+
+const (
+	SortByName    = "name"
+	SortByCreated = "created"
+	SortByUpdated = "updated"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type NoteQuery struct {
+	Limit   int
+	Cursor  string
+	SortBy  string
+	Order   string
+	Name    string
+	Tags    []string
+	TagMode string
+
+	NotebookID          string
+	IncludeSubnotebooks bool
+
+	Archived bool
+}
+
+type NotesPage struct {
+	Notes      []Note `json:"notes"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (q *NoteQuery) Normalize() {
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.Limit > MaxPageLimit {
+		q.Limit = MaxPageLimit
+	}
+	if q.SortBy == "" {
+		q.SortBy = SortByCreated
+	}
+	if q.Order == "" {
+		q.Order = SortOrderDesc
+	}
+	if q.TagMode == "" {
+		q.TagMode = TagModeAll
+	}
+}
+
+func (q NoteQuery) Valid() bool {
+	switch q.SortBy {
+	case SortByName, SortByCreated, SortByUpdated:
+	default:
+		return false
+	}
+	if q.TagMode != TagModeAll && q.TagMode != TagModeAny {
+		return false
+	}
+	return q.Order == SortOrderAsc || q.Order == SortOrderDesc
+}
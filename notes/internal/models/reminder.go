@@ -0,0 +1,232 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+
+	MaxUpcomingReminders = 100
+)
+
+type ReminderRequest struct {
+	RemindAt   time.Time `json:"remind_at"`
+	DueAt      time.Time `json:"due_at"`
+	Recurrence string    `json:"recurrence"`
+}
+
+type Reminder struct {
+	NoteID       string    `json:"note_id" bson:"note_id"`
+	AuthorID     int       `json:"author_id" bson:"author_id"`
+	Name         string    `json:"name" bson:"name"`
+	RemindAt     time.Time `json:"remind_at" bson:"remind_at"`
+	DueAt        time.Time `json:"due_at,omitzero" bson:"due_at,omitempty"`
+	Recurrence   string    `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	NextRemindAt time.Time `json:"next_remind_at,omitzero" bson:"next_remind_at,omitempty"`
+	// ClaimToken выдаёт ClaimDueReminders; CompleteReminder сдвигает
+	// напоминание, только если оно всё ещё забрано с этим токеном.
+	ClaimToken string `json:"-" bson:"-"`
+}
+
+type InboxItem struct {
+	ID        string    `json:"id" bson:"id,omitempty"`
+	UserID    int       `json:"user_id" bson:"user_id"`
+	Reminder  Reminder  `json:"reminder" bson:"reminder"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+func ReminderFromNote(note Note) Reminder {
+	return Reminder{
+		NoteID:     note.ID,
+		AuthorID:   note.AuthorID,
+		Name:       note.Name,
+		RemindAt:   note.RemindAt,
+		DueAt:      note.DueAt,
+		Recurrence: note.Recurrence,
+	}
+}
+
+// ValidateReminder проверяет поля напоминания заметки: повторение имеет
+// смысл только вместе со временем напоминания.
+func ValidateReminder(note Note) error {
+	if note.Recurrence == "" {
+		return nil
+	}
+	if note.RemindAt.IsZero() {
+		return fmt.Errorf("recurrence без remind_at")
+	}
+	_, err := ParseRecurrence(note.Recurrence)
+	return err
+}
+
+// Recurrence — поддерживаемое подмножество RRULE: FREQ=DAILY|WEEKLY|MONTHLY,
+// INTERVAL, BYDAY (только для WEEKLY), BYMONTHDAY (только для MONTHLY) и
+// UNTIL. Короткие daily, weekly и monthly означают FREQ с интервалом 1.
+type Recurrence struct {
+	Frequency  string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+	Until      time.Time
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+func ParseRecurrence(rule string) (Recurrence, error) {
+	recurrence := Recurrence{Interval: 1}
+
+	switch strings.ToLower(rule) {
+	case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		recurrence.Frequency = strings.ToLower(rule)
+		return recurrence, nil
+	}
+
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return recurrence, fmt.Errorf("некорректная часть правила %q", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY":
+				recurrence.Frequency = strings.ToLower(value)
+			default:
+				return recurrence, fmt.Errorf("частота %s не поддерживается", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval <= 0 || interval > 366 {
+				return recurrence, fmt.Errorf("некорректный INTERVAL=%s", value)
+			}
+			recurrence.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return recurrence, fmt.Errorf("некорректный день недели %s", day)
+				}
+				if !slices.Contains(recurrence.ByDay, weekday) {
+					recurrence.ByDay = append(recurrence.ByDay, weekday)
+				}
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day < 1 || day > 31 {
+				return recurrence, fmt.Errorf("некорректный BYMONTHDAY=%s", value)
+			}
+			recurrence.ByMonthDay = day
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return recurrence, fmt.Errorf("некорректный UNTIL=%s", value)
+			}
+			recurrence.Until = until
+		default:
+			return recurrence, fmt.Errorf("параметр %s не поддерживается", key)
+		}
+	}
+
+	if recurrence.Frequency == "" {
+		return recurrence, fmt.Errorf("не указан FREQ")
+	}
+	if len(recurrence.ByDay) > 0 && recurrence.Frequency != RecurrenceWeekly {
+		return recurrence, fmt.Errorf("BYDAY поддерживается только для FREQ=WEEKLY")
+	}
+	if recurrence.ByMonthDay > 0 && recurrence.Frequency != RecurrenceMonthly {
+		return recurrence, fmt.Errorf("BYMONTHDAY поддерживается только для FREQ=MONTHLY")
+	}
+
+	return recurrence, nil
+}
+
+// Next возвращает первое повторение строго после after, отсчитывая от
+// предыдущего срабатывания from. false — повторений больше нет.
+func (r Recurrence) Next(from time.Time, after time.Time) (time.Time, bool) {
+	next := from
+	for !next.After(after) {
+		next = r.step(next, from)
+		if !r.Until.IsZero() && next.After(r.Until) {
+			return time.Time{}, false
+		}
+	}
+	return next, true
+}
+
+func (r Recurrence) step(current time.Time, from time.Time) time.Time {
+	switch r.Frequency {
+	case RecurrenceMonthly:
+		day := r.ByMonthDay
+		if day == 0 {
+			day = from.Day()
+		}
+		months := monthsBetween(from, current)
+		if next := addMonths(from, months, day); next.After(current) {
+			return next
+		}
+		return addMonths(from, months+r.Interval, day)
+	case RecurrenceWeekly:
+		if len(r.ByDay) == 0 {
+			return current.AddDate(0, 0, 7*r.Interval)
+		}
+		// Недели считаются с понедельника недели, в которую попал from,
+		// чтобы INTERVAL пропускал целые недели, как в RRULE.
+		weekStart := startOfWeek(from)
+		for day := current.AddDate(0, 0, 1); ; day = day.AddDate(0, 0, 1) {
+			weeks := int(startOfWeek(day).Sub(weekStart).Hours()+12) / (24 * 7)
+			if weeks%r.Interval == 0 && slices.Contains(r.ByDay, day.Weekday()) {
+				return day
+			}
+		}
+	default:
+		return current.AddDate(0, 0, r.Interval)
+	}
+}
+
+// Месяц без нужного числа (31 февраля) даёт последний день месяца, а не
+// перескок на следующий, как у time.AddDate.
+func addMonths(t time.Time, months int, day int) time.Time {
+	year, month, _ := t.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	year, month, day := t.AddDate(0, 0, -offset).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("некорректное время %s", value)
+}
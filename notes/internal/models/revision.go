@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type NoteRevision struct {
+	NoteID    string    `json:"note_id" bson:"note_id"`
+	Version   int       `json:"version" bson:"version"`
+	Name      string    `json:"name" bson:"name"`
+	Content   string    `json:"content,omitempty" bson:"content"`
+	Tags      []string  `json:"tags,omitempty" bson:"tags,omitempty"`
+	AuthorID  int       `json:"author_id" bson:"author_id"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+func RevisionFromNote(note Note) NoteRevision {
+	return NoteRevision{
+		NoteID:    note.ID,
+		Version:   note.Version,
+		Name:      note.Name,
+		Content:   note.Content,
+		Tags:      note.Tags,
+		AuthorID:  note.AuthorID,
+		UpdatedAt: note.UpdatedAt,
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+type SearchQuery struct {
+	Text  string
+	Limit int
+}
+
+type SearchResult struct {
+	Note       Note                `json:"note"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+func (q *SearchQuery) Normalize() {
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+}
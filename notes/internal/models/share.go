@@ -0,0 +1,62 @@
+package models
+
+const (
+	ShareRoleViewer = "viewer"
+	ShareRoleEditor = "editor"
+)
+
+type NoteShare struct {
+	UserID int    `json:"user_id" bson:"user_id"`
+	Role   string `json:"role" bson:"role"`
+}
+
+func (s NoteShare) Valid() bool {
+	return s.UserID > 0 && (s.Role == ShareRoleViewer || s.Role == ShareRoleEditor)
+}
+
+func (n Note) IsOwner(userID int) bool {
+	return n.AuthorID == userID
+}
+
+func (n Note) CanRead(userID int) bool {
+	if n.IsOwner(userID) {
+		return true
+	}
+	for _, share := range n.Shares {
+		if share.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (n Note) CanWrite(userID int) bool {
+	if n.IsOwner(userID) {
+		return true
+	}
+	for _, share := range n.Shares {
+		if share.UserID == userID && share.Role == ShareRoleEditor {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleTo убирает из заметки список доступа и блокнот, если userID не
+// владелец: это личные данные автора.
+func (n Note) VisibleTo(userID int) Note {
+	if n.IsOwner(userID) {
+		return n
+	}
+	n.Shares = nil
+	n.NotebookID = ""
+	return n
+}
+
+func (n Note) SharedUserIDs() []int {
+	ids := make([]int, 0, len(n.Shares))
+	for _, share := range n.Shares {
+		ids = append(ids, share.UserID)
+	}
+	return ids
+}
@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestNoteVisibleTo(t *testing.T) {
+	note := Note{
+		AuthorID:   1,
+		NotebookID: "665f1c2e8b3a4d0012345678",
+		Shares:     []NoteShare{{UserID: 2, Role: ShareRoleEditor}},
+	}
+
+	if owner := note.VisibleTo(1); len(owner.Shares) != 1 || owner.NotebookID == "" {
+		t.Fatalf("владелец видит %+v", owner)
+	}
+
+	editor := note.VisibleTo(2)
+	if editor.Shares != nil || editor.NotebookID != "" {
+		t.Fatalf("редактор видит список доступа или блокнот: %+v", editor)
+	}
+	if len(note.Shares) != 1 {
+		t.Fatal("VisibleTo изменил исходную заметку")
+	}
+}
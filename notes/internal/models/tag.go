@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"notes/internal/errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxTagsPerNote = 32
+	MaxTagLength   = 64
+
+	TagModeAll = "all"
+	TagModeAny = "any"
+)
+
+type TagCount struct {
+	Tag   string `json:"tag" bson:"_id"`
+	Count int    `json:"count" bson:"count"`
+}
+
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: тег длиннее %d символов", errors.ErrInvalidTags, MaxTagLength)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTagsPerNote {
+		return nil, fmt.Errorf("%w: больше %d тегов", errors.ErrInvalidTags, MaxTagsPerNote)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	MaxWebhooksPerUser   = 20
+	MaxWebhookDeliveries = 100
+	MaxWebhookURLLength  = 2048
+)
+
+var WebhookEvents = []string{NoteEventCreated, NoteEventUpdated, NoteEventDeleted}
+
+// Webhook — адрес, на который отправляются события заметок автора. Пустой
+// Events означает подписку на все события.
+type Webhook struct {
+	ID                  string    `json:"id" bson:"id,omitempty"`
+	AuthorID            int       `json:"author_id" bson:"author_id"`
+	URL                 string    `json:"url" bson:"url"`
+	Events              []string  `json:"events,omitempty" bson:"events,omitempty"`
+	Secret              string    `json:"secret,omitempty" bson:"secret"`
+	Active              bool      `json:"active" bson:"active"`
+	ConsecutiveFailures int       `json:"consecutive_failures" bson:"consecutive_failures"`
+	LastDeliveryAt      time.Time `json:"last_delivery_at,omitzero" bson:"last_delivery_at,omitempty"`
+	DisabledAt          time.Time `json:"disabled_at,omitzero" bson:"disabled_at,omitempty"`
+	CreatedAt           time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookJob — одна доставка события в очереди. Webhook подставляется при
+// выдаче задачи и в очереди не хранится.
+type WebhookJob struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	Attempt    int       `json:"attempt"`
+	Event      NoteEvent `json:"event"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	Webhook    Webhook   `json:"-"`
+}
+
+type WebhookDelivery struct {
+	ID         string    `json:"id" bson:"id,omitempty"`
+	WebhookID  string    `json:"webhook_id" bson:"webhook_id"`
+	JobID      string    `json:"job_id" bson:"job_id"`
+	EventID    int64     `json:"event_id" bson:"event_id"`
+	EventType  string    `json:"event_type" bson:"event_type"`
+	NoteID     string    `json:"note_id" bson:"note_id"`
+	Attempt    int       `json:"attempt" bson:"attempt"`
+	Success    bool      `json:"success" bson:"success"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" bson:"duration_ms"`
+	NextRetry  time.Time `json:"next_retry_at,omitzero" bson:"next_retry_at,omitempty"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+func (w Webhook) Subscribed(eventType string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+// Redacted убирает секрет: он показывается только при создании webhook.
+func (w Webhook) Redacted() Webhook {
+	w.Secret = ""
+	return w
+}
+
+func ValidateWebhook(webhook Webhook) error {
+	if len(webhook.URL) > MaxWebhookURLLength {
+		return fmt.Errorf("адрес длиннее %d символов", MaxWebhookURLLength)
+	}
+
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес: %v", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("адрес должен начинаться с http:// или https://")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("в адресе не указан хост")
+	}
+
+	for _, event := range webhook.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("неизвестное событие %q", event)
+		}
+	}
+
+	return nil
+}
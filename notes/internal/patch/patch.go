@@ -0,0 +1,333 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func MergePatchFields(patch []byte) ([]string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, fmt.Errorf("%w: merge patch должен быть JSON-объектом: %v", errors.ErrInvalidPatch, err)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var targetValue, patchValue interface{}
+	if err := json.Unmarshal(target, &targetValue); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(mergeValue(targetValue, patchValue))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+func DecodeOperations(data []byte) ([]Operation, error) {
+	var operations []Operation
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, fmt.Errorf("%w: JSON Patch должен быть массивом операций: %v", errors.ErrInvalidPatch, err)
+	}
+	return operations, nil
+}
+
+func OperationFields(operations []Operation) ([]string, error) {
+	var fields []string
+	for _, operation := range operations {
+		paths := []string{operation.Path}
+		if operation.Op == "move" || operation.Op == "copy" {
+			paths = append(paths, operation.From)
+		}
+
+		for _, path := range paths {
+			tokens, err := parsePointer(path)
+			if err != nil {
+				return nil, err
+			}
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("%w: операция %s над всем документом", errors.ErrInvalidPatch, operation.Op)
+			}
+			fields = append(fields, tokens[0])
+		}
+	}
+	return fields, nil
+}
+
+func ApplyJSONPatch(target []byte, operations []Operation) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(target, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+
+	for i, operation := range operations {
+		var err error
+		doc, err = applyOperation(doc, operation)
+		if err != nil {
+			return nil, fmt.Errorf("операция %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func applyOperation(doc interface{}, operation Operation) (interface{}, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%w: отсутствует value", errors.ErrInvalidPatch)
+		}
+		var value interface{}
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+		}
+
+		switch operation.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if _, err := get(doc, path); err != nil {
+				return nil, err
+			}
+			if doc, _, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: значение %s не совпадает", errors.ErrPatchTestFailed, operation.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if operation.Op == "move" {
+			if strings.HasPrefix(operation.Path+"/", operation.From+"/") && operation.Path != operation.From {
+				return nil, fmt.Errorf("%w: нельзя переместить значение внутрь самого себя", errors.ErrInvalidPatch)
+			}
+			if doc, value, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = get(doc, from); err != nil {
+				return nil, err
+			}
+			if value, err = deepCopy(value); err != nil {
+				return nil, err
+			}
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("%w: неизвестная операция %q", errors.ErrInvalidPatch, operation.Op)
+	}
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: некорректный путь %q", errors.ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	node := doc
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, token)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, token)
+		}
+	}
+	return node, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(container interface{}, key string) (interface{}, error) {
+		switch parent := container.(type) {
+		case map[string]interface{}:
+			parent[key] = value
+			return parent, nil
+		case []interface{}:
+			if key == "-" {
+				return append(parent, value), nil
+			}
+			index, err := arrayIndex(key, len(parent))
+			if err != nil {
+				return nil, err
+			}
+			parent = append(parent, nil)
+			copy(parent[index+1:], parent[index:])
+			parent[index] = value
+			return parent, nil
+		default:
+			return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, key)
+		}
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	var removed interface{}
+	doc, err := modify(doc, path, func(container interface{}, key string) (interface{}, error) {
+		switch parent := container.(type) {
+		case map[string]interface{}:
+			value, ok := parent[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, key)
+			}
+			removed = value
+			delete(parent, key)
+			return parent, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(parent)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = parent[index]
+			return append(parent[:index], parent[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, key)
+		}
+	})
+	return doc, removed, err
+}
+
+func modify(node interface{}, path []string, apply func(container interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return apply(node, path[0])
+	}
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, path[0])
+		}
+		updated, err := modify(child, path[1:], apply)
+		if err != nil {
+			return nil, err
+		}
+		container[path[0]] = updated
+		return container, nil
+	case []interface{}:
+		index, err := arrayIndex(path[0], len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := modify(container[index], path[1:], apply)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = updated
+		return container, nil
+	default:
+		return nil, fmt.Errorf("%w: путь %q не найден", errors.ErrInvalidPatch, path[0])
+	}
+}
+
+func arrayIndex(token string, maxIndex int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > maxIndex || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: некорректный индекс массива %q", errors.ErrInvalidPatch, token)
+	}
+	return index, nil
+}
+
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidPatch, err)
+	}
+	return copied, nil
+}
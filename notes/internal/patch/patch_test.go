@@ -0,0 +1,164 @@
+package patch
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"notes/internal/errors"
+	"reflect"
+	"testing"
+)
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("результат не JSON: %s", got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("ожидаемое значение не JSON: %s", want)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Fatalf("получено %s, ожидалось %s", got, want)
+	}
+}
+
+// Примеры из приложения A RFC 7396.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(test.target), []byte(test.patch))
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+			assertJSONEqual(t, got, test.want)
+		})
+	}
+}
+
+func TestMergePatchFields(t *testing.T) {
+	fields, err := MergePatchFields([]byte(`{"name":"a","tags":null}`))
+	if err != nil {
+		t.Fatalf("MergePatchFields: %v", err)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("поля %v", fields)
+	}
+
+	if _, err := MergePatchFields([]byte(`["name"]`)); !stdErrors.Is(err, errors.ErrInvalidPatch) {
+		t.Fatalf("MergePatchFields массива: %v, ожидалась ErrInvalidPatch", err)
+	}
+}
+
+// Примеры из приложения A RFC 6902.
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name, target, patch, want string
+	}{
+		{"A.1", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"A.2", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"A.3", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"A.4", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"A.5", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{
+			"A.6",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{"A.7", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{
+			"A.8",
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{"A.10", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"A.11", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{"A.14", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"A.16", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"copy", `{"foo":{"a":1}}`, `[{"op":"copy","from":"/foo","path":"/bar"},{"op":"remove","path":"/foo/a"}]`, `{"foo":{},"bar":{"a":1}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operations, err := DecodeOperations([]byte(test.patch))
+			if err != nil {
+				t.Fatalf("DecodeOperations: %v", err)
+			}
+			got, err := ApplyJSONPatch([]byte(test.target), operations)
+			if err != nil {
+				t.Fatalf("ApplyJSONPatch: %v", err)
+			}
+			assertJSONEqual(t, got, test.want)
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name, target, patch string
+		want                error
+	}{
+		{"A.9", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"bar"}]`, errors.ErrPatchTestFailed},
+		{"A.12", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, errors.ErrInvalidPatch},
+		{"A.15", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, errors.ErrPatchTestFailed},
+		{"replace отсутствующего", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, errors.ErrInvalidPatch},
+		{"индекс за границей", `{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":1}]`, errors.ErrInvalidPatch},
+		{"ведущий ноль", `{"foo":[1,2]}`, `[{"op":"remove","path":"/foo/01"}]`, errors.ErrInvalidPatch},
+		{"перенос внутрь себя", `{"foo":{"a":1}}`, `[{"op":"move","from":"/foo","path":"/foo/a/b"}]`, errors.ErrInvalidPatch},
+		{"без value", `{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, errors.ErrInvalidPatch},
+		{"неизвестная операция", `{"foo":"bar"}`, `[{"op":"merge","path":"/foo"}]`, errors.ErrInvalidPatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operations, err := DecodeOperations([]byte(test.patch))
+			if err != nil {
+				t.Fatalf("DecodeOperations: %v", err)
+			}
+			if _, err := ApplyJSONPatch([]byte(test.target), operations); !stdErrors.Is(err, test.want) {
+				t.Fatalf("ApplyJSONPatch: %v, ожидалась %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestOperationFields(t *testing.T) {
+	operations, err := DecodeOperations([]byte(`[
+		{"op":"replace","path":"/name","value":"a"},
+		{"op":"move","from":"/tags/0","path":"/content"}
+	]`))
+	if err != nil {
+		t.Fatalf("DecodeOperations: %v", err)
+	}
+
+	fields, err := OperationFields(operations)
+	if err != nil {
+		t.Fatalf("OperationFields: %v", err)
+	}
+	if want := []string{"name", "content", "tags"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("поля %v, ожидались %v", fields, want)
+	}
+
+	if _, err := OperationFields([]Operation{{Op: "replace", Path: ""}}); !stdErrors.Is(err, errors.ErrInvalidPatch) {
+		t.Fatalf("операция над всем документом: %v, ожидалась ErrInvalidPatch", err)
+	}
+}
@@ -0,0 +1,123 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"notes/internal/config"
+	"notes/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	NotifierLog     = "log"
+	NotifierInbox   = "inbox"
+	NotifierWebhook = "webhook"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// Notifier доставляет сработавшее напоминание пользователю.
+type Notifier interface {
+	Notify(ctx context.Context, reminder models.Reminder) error
+}
+
+type Inbox interface {
+	DeliverToInbox(ctx context.Context, reminder models.Reminder) error
+}
+
+// NewNotifier собирает доставщиков из REMINDER_NOTIFIERS; напоминание
+// отправляется каждому из них.
+func NewNotifier(cfg *config.Config, inbox Inbox) (Notifier, error) {
+	var notifiers multiNotifier
+	for _, name := range cfg.ReminderNotifiers {
+		switch strings.TrimSpace(name) {
+		case "":
+		case NotifierLog:
+			notifiers = append(notifiers, logNotifier{})
+		case NotifierInbox:
+			notifiers = append(notifiers, inboxNotifier{inbox: inbox})
+		case NotifierWebhook:
+			if cfg.ReminderWebhookURL == "" {
+				return nil, fmt.Errorf("для доставки напоминаний через webhook не задан REMINDER_WEBHOOK_URL")
+			}
+			notifiers = append(notifiers, newWebhookNotifier(cfg.ReminderWebhookURL))
+		default:
+			return nil, fmt.Errorf("неизвестный способ доставки напоминаний %q", name)
+		}
+	}
+	return notifiers, nil
+}
+
+type multiNotifier []Notifier
+
+func (n multiNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, reminder); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stdErrors.Join(errs...)
+}
+
+type logNotifier struct{}
+
+func (logNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	fmt.Printf("Напоминание для пользователя с ID %d: заметка %s %q, время %s\n",
+		reminder.AuthorID, reminder.NoteID, reminder.Name, reminder.RemindAt.Format(time.RFC3339))
+	return nil
+}
+
+type inboxNotifier struct {
+	inbox Inbox
+}
+
+func (n inboxNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	return n.inbox.DeliverToInbox(ctx, reminder)
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+type webhookPayload struct {
+	Event    string          `json:"event"`
+	Reminder models.Reminder `json:"reminder"`
+}
+
+func newWebhookNotifier(url string) webhookNotifier {
+	return webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	body, err := json.Marshal(webhookPayload{Event: "reminder.due", Reminder: reminder})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("webhook напоминаний недоступен: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook напоминаний ответил %d", response.StatusCode)
+	}
+	return nil
+}
@@ -9,14 +9,64 @@ import (
 func SetupRouter(noteHandler *handler.Handler) *gin.Engine {
 	router := gin.Default()
 
+	router.GET("/notes/public/:token", noteHandler.OpenPublicLink)
+	router.GET("/notes/note/:id/collab", noteHandler.CollabNote)
+
 	noteAPI := router.Group("/notes")
 	noteAPI.Use(noteHandler.GetJWTMiddleware())
 	{
 		noteAPI.POST("/note", noteHandler.CreateNote)
+		noteAPI.POST("/batch", noteHandler.BatchNotes)
 		noteAPI.GET("/note/:id", noteHandler.GetNoteByID)
 		noteAPI.PUT("/note/:id", noteHandler.UpdateNote)
+		noteAPI.PATCH("/note/:id", noteHandler.PatchNote)
 		noteAPI.DELETE("/note/:id", noteHandler.DeleteNote)
+		noteAPI.GET("/note/:id/revisions", noteHandler.GetRevisions)
+		noteAPI.GET("/note/:id/revisions/:rev", noteHandler.GetRevision)
+		noteAPI.GET("/note/:id/diff", noteHandler.DiffRevisions)
+		noteAPI.POST("/note/:id/collab/ticket", noteHandler.CreateCollabTicket)
+		noteAPI.POST("/note/:id/restore/:rev", noteHandler.RestoreRevision)
+		noteAPI.POST("/note/:id/shares", noteHandler.ShareNote)
+		noteAPI.DELETE("/note/:id/shares", noteHandler.UnshareNote)
+		noteAPI.GET("/shared", noteHandler.GetSharedNotes)
+		noteAPI.POST("/note/:id/links", noteHandler.CreateLink)
+		noteAPI.GET("/note/:id/links", noteHandler.GetLinks)
+		noteAPI.DELETE("/note/:id/links/:token", noteHandler.RevokeLink)
+		noteAPI.PUT("/note/:id/notebook", noteHandler.MoveNote)
+		noteAPI.PUT("/note/:id/pin", noteHandler.PinNote)
+		noteAPI.DELETE("/note/:id/pin", noteHandler.UnpinNote)
+		noteAPI.PUT("/note/:id/archive", noteHandler.ArchiveNote)
+		noteAPI.DELETE("/note/:id/archive", noteHandler.UnarchiveNote)
+		noteAPI.PUT("/note/:id/reminder", noteHandler.SetReminder)
+		noteAPI.DELETE("/note/:id/reminder", noteHandler.ClearReminder)
+		noteAPI.POST("/note/:id/attachments", noteHandler.UploadAttachment)
+		noteAPI.GET("/note/:id/attachments", noteHandler.GetAttachments)
+		noteAPI.GET("/note/:id/attachments/:attachment_id", noteHandler.DownloadAttachment)
+		noteAPI.DELETE("/note/:id/attachments/:attachment_id", noteHandler.DeleteAttachment)
 		noteAPI.GET("/notes", noteHandler.GetAllNotes)
+		noteAPI.GET("/archive", noteHandler.GetArchive)
+		noteAPI.GET("/export", noteHandler.ExportNotes)
+		noteAPI.POST("/import", noteHandler.ImportNotes)
+		noteAPI.GET("/import/:id", noteHandler.GetImportJob)
+		noteAPI.GET("/events", noteHandler.StreamEvents)
+		noteAPI.GET("/reminders/upcoming", noteHandler.GetUpcomingReminders)
+		noteAPI.GET("/reminders/inbox", noteHandler.GetInbox)
+		noteAPI.POST("/webhooks", noteHandler.CreateWebhook)
+		noteAPI.GET("/webhooks", noteHandler.GetWebhooks)
+		noteAPI.GET("/webhooks/:id", noteHandler.GetWebhook)
+		noteAPI.PUT("/webhooks/:id", noteHandler.UpdateWebhook)
+		noteAPI.DELETE("/webhooks/:id", noteHandler.DeleteWebhook)
+		noteAPI.GET("/webhooks/:id/deliveries", noteHandler.GetWebhookDeliveries)
+		noteAPI.POST("/notebooks", noteHandler.CreateNotebook)
+		noteAPI.GET("/notebooks", noteHandler.GetNotebooks)
+		noteAPI.GET("/notebooks/:id", noteHandler.GetNotebook)
+		noteAPI.PUT("/notebooks/:id", noteHandler.UpdateNotebook)
+		noteAPI.DELETE("/notebooks/:id", noteHandler.DeleteNotebook)
+		noteAPI.GET("/search", noteHandler.SearchNotes)
+		noteAPI.GET("/tags", noteHandler.GetTags)
+		noteAPI.GET("/trash", noteHandler.GetTrash)
+		noteAPI.POST("/trash/:id/restore", noteHandler.RestoreFromTrash)
+		noteAPI.DELETE("/trash/:id", noteHandler.DeleteFromTrash)
 	}
 	return router
 }
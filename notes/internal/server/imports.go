@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/service"
+	"time"
+)
+
+// importSweeper помечает неудачными фоновые импорты, которые перестали
+// обновляться: их оборвал перезапуск или падение сервера. Проверка идёт
+// при старте и дальше с тем же периодом, за который задача считается
+// зависшей.
+type importSweeper struct {
+	service service.Service
+	stop    chan struct{}
+	done    chan struct{}
+	started bool
+}
+
+func newImportSweeper(service service.Service) *importSweeper {
+	return &importSweeper{
+		service: service,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *importSweeper) Start() {
+	s.started = true
+	go s.run()
+}
+
+func (s *importSweeper) Stop() {
+	if !s.started {
+		return
+	}
+
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+}
+
+func (s *importSweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(service.ImportStaleAfter)
+	defer ticker.Stop()
+
+	s.sweep()
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *importSweeper) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), service.ImportStaleAfter)
+	defer cancel()
+
+	failed, err := s.service.FailStaleImports(ctx, time.Now().Add(-service.ImportStaleAfter))
+	if err != nil {
+		fmt.Printf("Ошибка проверки прерванных импортов: %v\n", err)
+		return
+	}
+
+	if failed > 0 {
+		fmt.Printf("Прерванных задач импорта помечено неудачными: %d\n", failed)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/config"
+	"notes/internal/service"
+	"time"
+)
+
+type trashPurger struct {
+	service   service.Service
+	retention time.Duration
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+	started   bool
+}
+
+func newTrashPurger(cfg *config.Config, service service.Service) *trashPurger {
+	return &trashPurger{
+		service:   service,
+		retention: time.Duration(cfg.TrashRetentionHours) * time.Hour,
+		interval:  time.Duration(cfg.TrashPurgeIntervalMinutes) * time.Minute,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (p *trashPurger) Start() {
+	p.started = true
+	if p.retention <= 0 || p.interval <= 0 {
+		fmt.Println("Очистка корзины отключена")
+		close(p.done)
+		return
+	}
+
+	go p.run()
+}
+
+func (p *trashPurger) Stop() {
+	if !p.started {
+		return
+	}
+
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	<-p.done
+}
+
+func (p *trashPurger) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.purge()
+	for {
+		select {
+		case <-ticker.C:
+			p.purge()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *trashPurger) purge() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+
+	purged, err := p.service.PurgeTrash(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		fmt.Printf("Ошибка очистки корзины: %v\n", err)
+		return
+	}
+
+	if purged > 0 {
+		fmt.Printf("Из корзины окончательно удалено заметок: %d\n", purged)
+	}
+}
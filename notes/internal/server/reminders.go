@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/config"
+	"notes/internal/reminders"
+	"notes/internal/service"
+	"time"
+)
+
+const reminderBatchSize = 100
+
+type reminderScheduler struct {
+	service  service.Service
+	notifier reminders.Notifier
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	started  bool
+}
+
+func newReminderScheduler(cfg *config.Config, service service.Service, notifier reminders.Notifier) *reminderScheduler {
+	return &reminderScheduler{
+		service:  service,
+		notifier: notifier,
+		interval: time.Duration(cfg.ReminderPollIntervalSeconds) * time.Second,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *reminderScheduler) Start() {
+	s.started = true
+	if s.interval <= 0 {
+		fmt.Println("Планировщик напоминаний отключён")
+		close(s.done)
+		return
+	}
+
+	go s.run()
+}
+
+func (s *reminderScheduler) Stop() {
+	if !s.started {
+		return
+	}
+
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+}
+
+func (s *reminderScheduler) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.fire()
+	for {
+		select {
+		case <-ticker.C:
+			s.fire()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Полная пачка означает, что сработавших напоминаний может быть больше,
+// поэтому забираем следующую, не дожидаясь тика. Напоминание подтверждается
+// только после доставки; недоставленное вернётся в ClaimDueReminders, когда
+// истечёт его аренда.
+func (s *reminderScheduler) fire() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+		due, err := s.service.ClaimDueReminders(ctx, time.Now().UTC(), reminderBatchSize)
+		if err != nil {
+			cancel()
+			fmt.Printf("Ошибка получения напоминаний: %v\n", err)
+			return
+		}
+
+		for _, reminder := range due {
+			if err := s.notifier.Notify(ctx, reminder); err != nil {
+				fmt.Printf("Ошибка доставки напоминания по заметке %s, повторим позже: %v\n", reminder.NoteID, err)
+				continue
+			}
+			if err := s.service.CompleteReminder(ctx, reminder); err != nil {
+				fmt.Printf("Ошибка подтверждения напоминания по заметке %s: %v\n", reminder.NoteID, err)
+			}
+		}
+		cancel()
+
+		if len(due) < reminderBatchSize {
+			return
+		}
+	}
+}
@@ -1,19 +1,35 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"notes/internal/config"
 	"notes/internal/handler"
+	"notes/internal/reminders"
 	"notes/internal/routes"
 	"notes/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+const shutdownTimeout = 15 * time.Second
+
 type Server struct {
-	cfg    *config.Config
-	router *gin.Engine
+	cfg        *config.Config
+	router     *gin.Engine
+	service    service.Service
+	handler    *handler.Handler
+	purger     *trashPurger
+	scheduler  *reminderScheduler
+	dispatcher *webhookDispatcher
+	imports    *importSweeper
 }
 
 func NewServer(cfg *config.Config) (*Server, error) {
@@ -26,6 +42,11 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("не удалось создать сервис: %w", err)
 	}
 
+	notifier, err := reminders.NewNotifier(cfg, service)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось настроить доставку напоминаний: %w", err)
+	}
+
 	handler := handler.NewHandler(cfg, service)
 
 	if handler == nil {
@@ -37,27 +58,69 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	router := routes.SetupRouter(handler)
 
 	return &Server{
-		router: router,
-		cfg:    cfg,
+		router:     router,
+		cfg:        cfg,
+		service:    service,
+		handler:    handler,
+		purger:     newTrashPurger(cfg, service),
+		scheduler:  newReminderScheduler(cfg, service, notifier),
+		dispatcher: newWebhookDispatcher(cfg, service),
+		imports:    newImportSweeper(service),
 	}, nil
 }
 
 func (s *Server) Start() error {
 	fmt.Printf("Сервер запускается на %s:%s\n", s.cfg.Host, s.cfg.Port)
+	s.purger.Start()
+	s.scheduler.Start()
+	s.dispatcher.Start()
+	s.imports.Start()
 	return nil
 }
 
 func (s *Server) Stop() error {
+	s.purger.Stop()
+	s.scheduler.Stop()
+	s.dispatcher.Stop()
+	s.imports.Stop()
+	s.service.StopImports()
+	s.handler.Close()
 	fmt.Println("Сервер остановлен")
 	return nil
 }
 
+// Serve обслуживает запросы до SIGINT или SIGTERM, после чего дожидается
+// текущих запросов и останавливает фоновые задачи.
 func (s *Server) Serve() error {
 	if err := s.Start(); err != nil {
 		return err
 	}
 
 	address := fmt.Sprintf("%s:%s", s.cfg.Host, s.cfg.Port)
-	fmt.Printf("Сервер готов к обработке запросов на %s...\n", address)
-	return s.router.Run(address)
+	httpServer := &http.Server{Addr: address, Handler: s.router}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("Сервер готов к обработке запросов на %s...\n", address)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		s.Stop()
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Println("Получен сигнал остановки, сервер завершает работу")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Printf("Не все запросы завершились до остановки сервера: %v\n", err)
+	}
+
+	return s.Stop()
 }
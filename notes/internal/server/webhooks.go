@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/config"
+	"notes/internal/service"
+	"notes/internal/webhooks"
+	"sync"
+	"time"
+)
+
+const (
+	webhookBatchSize    = 50
+	webhookStoreTimeout = 10 * time.Second
+)
+
+// webhookDispatcher забирает доставки из очереди в Redis и отправляет их
+// параллельно: медленный адрес не задерживает остальные в пачке.
+type webhookDispatcher struct {
+	service  service.Service
+	sender   *webhooks.Sender
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	started  bool
+}
+
+func newWebhookDispatcher(cfg *config.Config, service service.Service) *webhookDispatcher {
+	return &webhookDispatcher{
+		service:  service,
+		sender:   webhooks.NewSender(),
+		interval: time.Duration(cfg.WebhookPollIntervalSeconds) * time.Second,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (d *webhookDispatcher) Start() {
+	d.started = true
+	if d.interval <= 0 {
+		fmt.Println("Доставка webhook отключена")
+		close(d.done)
+		return
+	}
+
+	go d.run()
+}
+
+func (d *webhookDispatcher) Stop() {
+	if !d.started {
+		return
+	}
+
+	select {
+	case <-d.stop:
+	default:
+		close(d.stop)
+	}
+	<-d.done
+}
+
+func (d *webhookDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.dispatch()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func (d *webhookDispatcher) dispatch() {
+	for {
+		select {
+		case <-d.stop:
+			return
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), webhookStoreTimeout)
+		jobs, err := d.service.ClaimWebhookJobs(ctx, time.Now().UTC(), webhookBatchSize)
+		cancel()
+		if err != nil {
+			fmt.Printf("Ошибка получения доставок webhook: %v\n", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				delivery := d.sender.Deliver(context.Background(), job)
+
+				ctx, cancel := context.WithTimeout(context.Background(), webhookStoreTimeout)
+				defer cancel()
+				if err := d.service.CompleteWebhookJob(ctx, job, delivery); err != nil {
+					fmt.Printf("Ошибка завершения доставки webhook %s: %v\n", job.WebhookID, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(jobs) < webhookBatchSize {
+			return
+		}
+	}
+}
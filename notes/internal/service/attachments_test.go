@@ -0,0 +1,47 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"sync"
+	"testing"
+)
+
+func TestParallelUploadsRespectQuota(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	cfg.AttachmentMaxSizeMB = 1
+	cfg.AttachmentsUserQuotaMB = 1
+	service := NewMemoryService(cfg)
+
+	note, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "Файлы"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("x"), 300<<10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.UploadAttachment(ctx, *note, "file.txt", "text/plain", bytes.NewReader(content))
+			if err != nil && !stdErrors.Is(err, errors.ErrAttachmentQuota) {
+				t.Errorf("UploadAttachment: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	attachments, err := service.GetAttachments(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetAttachments: %v", err)
+	}
+	if len(attachments) != 3 {
+		t.Fatalf("загружено %d вложений по 300 КБ при квоте 1 МБ, ожидалось 3", len(attachments))
+	}
+}
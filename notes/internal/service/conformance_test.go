@@ -0,0 +1,1173 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"io"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Общий набор проверок для всех хранилищ. Memory проверяется всегда,
+// Postgres и Mongo — только если заданы NOTES_TEST_POSTGRES_DSN и
+// NOTES_TEST_MONGO. Базы не очищаются: каждая проверка работает со своим
+// автором, поэтому набор можно гонять на общей базе повторно.
+
+func TestMemoryConformance(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.StorageBackend = BackendMemory
+
+	runConformance(t, cfg)
+}
+
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv("NOTES_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("NOTES_TEST_POSTGRES_DSN не задан")
+	}
+
+	cfg := config.NewConfig()
+	cfg.StorageBackend = BackendPostgres
+	cfg.PostgresDSN = dsn
+
+	runConformance(t, cfg)
+}
+
+// Mongo и Redis берутся из тех же переменных окружения, что и у сервиса.
+func TestMongoConformance(t *testing.T) {
+	if os.Getenv("NOTES_TEST_MONGO") == "" {
+		t.Skip("NOTES_TEST_MONGO не задан")
+	}
+
+	cfg := config.NewConfig()
+	cfg.StorageBackend = BackendMongo
+
+	runConformance(t, cfg)
+}
+
+var lastTestAuthor atomic.Int64
+
+func init() {
+	lastTestAuthor.Store(time.Now().UnixMilli() % 1_000_000_000)
+}
+
+func newTestAuthor() int {
+	return int(lastTestAuthor.Add(1))
+}
+
+func runConformance(t *testing.T, cfg *config.Config) {
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := service.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, service Service)
+	}{
+		{"CRUD", testCRUD},
+		{"VersionConflict", testVersionConflict},
+		{"Trash", testTrash},
+		{"ListCursor", testListCursor},
+		{"ListPinned", testListPinned},
+		{"StateVersion", testStateVersion},
+		{"ListTags", testListTags},
+		{"Search", testSearch},
+		{"Tags", testTags},
+		{"Revisions", testRevisions},
+		{"Shares", testShares},
+		{"Batch", testBatch},
+		{"ReminderDelivery", testReminderDelivery},
+		{"Reminders", testReminders},
+		{"States", testStates},
+		{"Notebooks", testNotebooks},
+		{"Attachments", testAttachments},
+		{"Links", testLinks},
+		{"Webhooks", testWebhooks},
+		{"ImportExport", testImportExport},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, service)
+		})
+	}
+}
+
+func createTestNote(t *testing.T, service Service, note models.Note) *models.Note {
+	t.Helper()
+
+	created, err := service.Create(context.Background(), note)
+	if err != nil {
+		t.Fatalf("Create(%q): %v", note.Name, err)
+	}
+	return created
+}
+
+func noteIDs(notes []models.Note) []string {
+	ids := make([]string, 0, len(notes))
+	for _, note := range notes {
+		ids = append(ids, note.ID)
+	}
+	return ids
+}
+
+func testCRUD(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	created := createTestNote(t, service, models.Note{AuthorID: author, Name: "Покупки", Content: "хлеб", Tags: []string{"дом"}})
+	if created.ID == "" || created.Version != 1 || created.CreatedAt.IsZero() {
+		t.Fatalf("Create вернул %+v", created)
+	}
+
+	found, err := service.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Name != "Покупки" || found.Content != "хлеб" || !slices.Equal(found.Tags, []string{"дом"}) || found.AuthorID != author {
+		t.Fatalf("GetByID вернул %+v", found)
+	}
+
+	updated, err := service.Update(ctx, models.Note{ID: created.ID, AuthorID: author, Name: "Покупки", Content: "хлеб и молоко", Version: 1})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Version != 2 || updated.Content != "хлеб и молоко" || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("Update вернул %+v", updated)
+	}
+
+	if err := service.Delete(ctx, created.ID, 2); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := service.GetByID(ctx, created.ID); !stdErrors.Is(err, errors.ErrNoteNotFound) {
+		t.Fatalf("GetByID после удаления: %v, ожидалась ErrNoteNotFound", err)
+	}
+
+	if _, err := service.GetByID(ctx, "not-an-id"); !stdErrors.Is(err, errors.ErrInvalidNoteID) {
+		t.Fatalf("GetByID с некорректным ID: %v, ожидалась ErrInvalidNoteID", err)
+	}
+}
+
+func testVersionConflict(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	created := createTestNote(t, service, models.Note{AuthorID: author, Name: "Черновик"})
+	if _, err := service.Update(ctx, models.Note{ID: created.ID, AuthorID: author, Name: "Первая правка", Version: 1}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	_, err := service.Update(ctx, models.Note{ID: created.ID, AuthorID: author, Name: "Устаревшая правка", Version: 1})
+	if !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("Update со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+
+	if err := service.Delete(ctx, created.ID, 1); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("Delete со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+
+	// Версия 0 означает запись без проверки.
+	updated, err := service.Update(ctx, models.Note{ID: created.ID, AuthorID: author, Name: "Без проверки"})
+	if err != nil {
+		t.Fatalf("Update без версии: %v", err)
+	}
+	if updated.Version != 3 {
+		t.Fatalf("версия после двух правок %d, ожидалась 3", updated.Version)
+	}
+}
+
+func testTrash(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	kept := createTestNote(t, service, models.Note{AuthorID: author, Name: "Остаётся"})
+	restored := createTestNote(t, service, models.Note{AuthorID: author, Name: "Вернётся"})
+	purged := createTestNote(t, service, models.Note{AuthorID: author, Name: "Исчезнет"})
+
+	for _, note := range []*models.Note{restored, purged} {
+		if err := service.Delete(ctx, note.ID, 0); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	}
+
+	trash, err := service.GetTrash(ctx, author)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	ids := noteIDs(trash)
+	if len(ids) != 2 || !slices.Contains(ids, restored.ID) || !slices.Contains(ids, purged.ID) {
+		t.Fatalf("в корзине %v, ожидались %s и %s", ids, restored.ID, purged.ID)
+	}
+
+	if _, err := service.GetTrashedByID(ctx, kept.ID); !stdErrors.Is(err, errors.ErrNoteNotFound) {
+		t.Fatalf("GetTrashedByID для активной заметки: %v, ожидалась ErrNoteNotFound", err)
+	}
+
+	restoredNote, err := service.RestoreFromTrash(ctx, restored.ID)
+	if err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+	if restoredNote.Version != restored.Version+1 {
+		t.Fatalf("версия после восстановления %d, ожидалась %d", restoredNote.Version, restored.Version+1)
+	}
+	if _, err := service.GetByID(ctx, restored.ID); err != nil {
+		t.Fatalf("GetByID после восстановления: %v", err)
+	}
+	stale := models.Note{ID: restored.ID, AuthorID: author, Name: "Поверх", Version: restored.Version}
+	if _, err := service.Update(ctx, stale); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("Update с версией до удаления: %v, ожидалась ErrVersionConflict", err)
+	}
+
+	if err := service.DeletePermanently(ctx, purged.ID); err != nil {
+		t.Fatalf("DeletePermanently: %v", err)
+	}
+	if _, err := service.GetTrashedByID(ctx, purged.ID); !stdErrors.Is(err, errors.ErrNoteNotFound) {
+		t.Fatalf("GetTrashedByID после окончательного удаления: %v, ожидалась ErrNoteNotFound", err)
+	}
+}
+
+func testListCursor(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	var want []string
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		want = append(want, createTestNote(t, service, models.Note{AuthorID: author, Name: name}).ID)
+	}
+
+	var got []string
+	query := models.NoteQuery{Limit: 2, SortBy: models.SortByName, Order: models.SortOrderAsc}
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatalf("курсор не заканчивается, получено %v", got)
+		}
+
+		page, err := service.GetAll(ctx, author, query)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(page.Notes) > 2 {
+			t.Fatalf("на странице %d заметок при лимите 2", len(page.Notes))
+		}
+		got = append(got, noteIDs(page.Notes)...)
+
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("порядок по курсору %v, ожидался %v", got, want)
+	}
+
+	desc, err := service.GetAll(ctx, author, models.NoteQuery{SortBy: models.SortByName, Order: models.SortOrderDesc})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	slices.Reverse(want)
+	if ids := noteIDs(desc.Notes); !slices.Equal(ids, want) {
+		t.Fatalf("порядок по убыванию %v, ожидался %v", ids, want)
+	}
+
+	if _, err := service.GetAll(ctx, author, models.NoteQuery{Cursor: "!!!"}); !stdErrors.Is(err, errors.ErrInvalidCursor) {
+		t.Fatalf("GetAll с испорченным курсором: %v, ожидалась ErrInvalidCursor", err)
+	}
+}
+
+func testListPinned(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	first := createTestNote(t, service, models.Note{AuthorID: author, Name: "a"})
+	pinned := createTestNote(t, service, models.Note{AuthorID: author, Name: "b"})
+	last := createTestNote(t, service, models.Note{AuthorID: author, Name: "c"})
+	archived := createTestNote(t, service, models.Note{AuthorID: author, Name: "d"})
+
+	if _, err := service.SetPinned(ctx, pinned.ID, true, 0); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	if _, err := service.SetPinned(ctx, archived.ID, true, 0); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	note, err := service.SetArchived(ctx, archived.ID, true, 0)
+	if err != nil {
+		t.Fatalf("SetArchived: %v", err)
+	}
+	if note.Pinned {
+		t.Fatal("архивная заметка осталась закреплённой")
+	}
+
+	var got []string
+	query := models.NoteQuery{Limit: 1, SortBy: models.SortByName, Order: models.SortOrderAsc}
+	for {
+		page, err := service.GetAll(ctx, author, query)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		got = append(got, noteIDs(page.Notes)...)
+		if page.NextCursor == "" || len(got) > 3 {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if want := []string{pinned.ID, first.ID, last.ID}; !slices.Equal(got, want) {
+		t.Fatalf("порядок с закреплённой заметкой %v, ожидался %v", got, want)
+	}
+
+	page, err := service.GetAll(ctx, author, models.NoteQuery{Archived: true})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if ids := noteIDs(page.Notes); !slices.Equal(ids, []string{archived.ID}) {
+		t.Fatalf("в архиве %v, ожидалась %s", ids, archived.ID)
+	}
+}
+
+// Закрепление, архив и напоминание меняют версию, поэтому их можно защитить
+// If-Match так же, как обновление содержимого.
+func testStateVersion(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "Состояние"})
+
+	pinned, err := service.SetPinned(ctx, note.ID, true, note.Version)
+	if err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	if pinned.Version != note.Version+1 || pinned.UpdatedAt.Before(note.UpdatedAt) {
+		t.Fatalf("после закрепления версия %d, updated_at %v", pinned.Version, pinned.UpdatedAt)
+	}
+
+	if _, err := service.SetArchived(ctx, note.ID, true, note.Version); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("SetArchived со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+
+	reminder := models.ReminderRequest{RemindAt: time.Now().Add(time.Hour)}
+	updated, err := service.SetReminder(ctx, note.ID, reminder, pinned.Version)
+	if err != nil {
+		t.Fatalf("SetReminder: %v", err)
+	}
+	if updated.Version != pinned.Version+1 {
+		t.Fatalf("после напоминания версия %d, ожидалась %d", updated.Version, pinned.Version+1)
+	}
+
+	current, err := service.GetByID(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if current.Version != updated.Version || !current.Pinned || current.Archived {
+		t.Fatalf("после изменений заметка %+v", current)
+	}
+}
+
+func testListTags(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	both := createTestNote(t, service, models.Note{AuthorID: author, Name: "a", Tags: []string{"работа", "срочно"}})
+	work := createTestNote(t, service, models.Note{AuthorID: author, Name: "b", Tags: []string{"работа"}})
+	urgent := createTestNote(t, service, models.Note{AuthorID: author, Name: "c", Tags: []string{"срочно"}})
+	createTestNote(t, service, models.Note{AuthorID: author, Name: "d"})
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{models.TagModeAll, []string{both.ID}},
+		{models.TagModeAny, []string{both.ID, work.ID, urgent.ID}},
+	}
+	for _, test := range tests {
+		page, err := service.GetAll(ctx, author, models.NoteQuery{
+			SortBy:  models.SortByName,
+			Order:   models.SortOrderAsc,
+			Tags:    []string{"работа", "срочно"},
+			TagMode: test.mode,
+		})
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if ids := noteIDs(page.Notes); !slices.Equal(ids, test.want) {
+			t.Fatalf("теги в режиме %s: %v, ожидалось %v", test.mode, ids, test.want)
+		}
+	}
+}
+
+func testSearch(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	inName := createTestNote(t, service, models.Note{AuthorID: author, Name: "кактус", Content: "стоит на окне"})
+	inContent := createTestNote(t, service, models.Note{AuthorID: author, Name: "цветы", Content: "кактус и фикус"})
+	excluded := createTestNote(t, service, models.Note{AuthorID: author, Name: "полив", Content: "кактус поливать редко"})
+	createTestNote(t, service, models.Note{AuthorID: author, Name: "другое", Content: "ничего общего"})
+
+	results, err := service.Search(ctx, author, models.SearchQuery{Text: "кактус"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("найдено %d заметок, ожидалось 3", len(results))
+	}
+	if results[0].Note.ID != inName.ID {
+		t.Fatalf("первой найдена %q, ожидалась заметка со словом в названии", results[0].Note.Name)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Fatalf("оценка совпадения в названии %v не выше оценки %v", results[0].Score, results[1].Score)
+	}
+
+	results, err = service.Search(ctx, author, models.SearchQuery{Text: "кактус -редко"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Note.ID)
+	}
+	if slices.Contains(ids, excluded.ID) || !slices.Contains(ids, inName.ID) || !slices.Contains(ids, inContent.ID) {
+		t.Fatalf("поиск с исключением нашёл %v", ids)
+	}
+
+	if _, err := service.Search(ctx, author, models.SearchQuery{Text: "  "}); !stdErrors.Is(err, errors.ErrInvalidQuery) {
+		t.Fatalf("пустой поиск: %v, ожидалась ErrInvalidQuery", err)
+	}
+}
+
+func testTags(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	createTestNote(t, service, models.Note{AuthorID: author, Name: "a", Tags: []string{"работа", "срочно"}})
+	createTestNote(t, service, models.Note{AuthorID: author, Name: "b", Tags: []string{"работа"}})
+	createTestNote(t, service, models.Note{AuthorID: author, Name: "c", Tags: []string{"дом"}})
+	trashed := createTestNote(t, service, models.Note{AuthorID: author, Name: "d", Tags: []string{"корзина"}})
+	if err := service.Delete(ctx, trashed.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	tags, err := service.GetTags(ctx, author)
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	want := []models.TagCount{{Tag: "работа", Count: 2}, {Tag: "дом", Count: 1}, {Tag: "срочно", Count: 1}}
+	if !slices.Equal(tags, want) {
+		t.Fatalf("теги %v, ожидались %v", tags, want)
+	}
+}
+
+func testRevisions(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	created := createTestNote(t, service, models.Note{AuthorID: author, Name: "v1", Content: "первая версия"})
+	if _, err := service.Update(ctx, models.Note{ID: created.ID, AuthorID: author, Name: "v2", Content: "вторая версия", Version: 1}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	revisions, err := service.GetRevisions(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetRevisions: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Version != 1 || revisions[0].Name != "v1" {
+		t.Fatalf("ревизии %+v, ожидалась одна ревизия v1", revisions)
+	}
+
+	revision, err := service.GetRevision(ctx, created.ID, 1)
+	if err != nil {
+		t.Fatalf("GetRevision: %v", err)
+	}
+	if revision.Content != "первая версия" {
+		t.Fatalf("содержимое ревизии %q", revision.Content)
+	}
+
+	if _, err := service.GetRevision(ctx, created.ID, 7); !stdErrors.Is(err, errors.ErrRevisionNotFound) {
+		t.Fatalf("GetRevision несуществующей версии: %v, ожидалась ErrRevisionNotFound", err)
+	}
+
+	if _, err := service.RestoreRevision(ctx, created.ID, 1, 1); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("RestoreRevision со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+
+	restored, err := service.RestoreRevision(ctx, created.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	if restored.Name != "v1" || restored.Content != "первая версия" || restored.Version != 3 {
+		t.Fatalf("RestoreRevision вернул %+v", restored)
+	}
+
+	pinned, err := service.SetPinned(ctx, created.ID, true, restored.Version)
+	if err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	if pinned.Version != 4 {
+		t.Fatalf("версия после закрепления %d, ожидалась 4", pinned.Version)
+	}
+
+	revisions, err = service.GetRevisions(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetRevisions: %v", err)
+	}
+	versions := make([]int, 0, len(revisions))
+	for _, revision := range revisions {
+		versions = append(versions, revision.Version)
+	}
+	slices.Sort(versions)
+	if !slices.Equal(versions, []int{1, 2, 3}) {
+		t.Fatalf("версии ревизий после закрепления %v, ожидались [1 2 3]", versions)
+	}
+
+	if _, err := service.GetRevision(ctx, created.ID, 3); err != nil {
+		t.Fatalf("GetRevision версии до закрепления: %v", err)
+	}
+	restored, err = service.RestoreRevision(ctx, created.ID, 2, 0)
+	if err != nil {
+		t.Fatalf("RestoreRevision версии 2: %v", err)
+	}
+	if restored.Name != "v2" || !restored.Pinned || restored.Version != 5 {
+		t.Fatalf("RestoreRevision версии 2 вернул %+v", restored)
+	}
+}
+
+func testShares(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	reader := newTestAuthor()
+
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "Общая"})
+	createTestNote(t, service, models.Note{AuthorID: author, Name: "Личная"})
+
+	shared, err := service.ShareNote(ctx, note.ID, models.NoteShare{UserID: reader, Role: models.ShareRoleViewer})
+	if err != nil {
+		t.Fatalf("ShareNote: %v", err)
+	}
+	if shared.CanWrite(reader) {
+		t.Fatal("читатель получил право на запись")
+	}
+
+	shared, err = service.ShareNote(ctx, note.ID, models.NoteShare{UserID: reader, Role: models.ShareRoleEditor})
+	if err != nil {
+		t.Fatalf("ShareNote: %v", err)
+	}
+	if len(shared.Shares) != 1 || !shared.CanWrite(reader) {
+		t.Fatalf("доступ после смены роли %+v", shared.Shares)
+	}
+	if shared.Version != note.Version+2 {
+		t.Fatalf("версия после двух изменений доступа %d, ожидалась %d", shared.Version, note.Version+2)
+	}
+
+	notes, err := service.GetShared(ctx, reader)
+	if err != nil {
+		t.Fatalf("GetShared: %v", err)
+	}
+	if ids := noteIDs(notes); !slices.Equal(ids, []string{note.ID}) {
+		t.Fatalf("доступные заметки %v, ожидалась %s", ids, note.ID)
+	}
+
+	if _, err := service.ShareNote(ctx, note.ID, models.NoteShare{UserID: reader, Role: "owner"}); !stdErrors.Is(err, errors.ErrInvalidShare) {
+		t.Fatalf("ShareNote с неизвестной ролью: %v, ожидалась ErrInvalidShare", err)
+	}
+
+	unshared, err := service.UnshareNote(ctx, note.ID, reader)
+	if err != nil {
+		t.Fatalf("UnshareNote: %v", err)
+	}
+	if len(unshared.Shares) != 0 || unshared.Version != shared.Version+1 {
+		t.Fatalf("после отзыва доступа заметка %+v", unshared)
+	}
+
+	notes, err = service.GetShared(ctx, reader)
+	if err != nil {
+		t.Fatalf("GetShared: %v", err)
+	}
+	if len(notes) != 0 {
+		t.Fatalf("после отзыва доступны %v", noteIDs(notes))
+	}
+}
+
+func testBatch(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	existing := createTestNote(t, service, models.Note{AuthorID: author, Name: "Было"})
+	missing := "000000000000000000000000"
+
+	results, err := service.Batch(ctx, author, models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpCreate, Note: models.Note{Name: "Новая"}},
+		{Op: models.BatchOpUpdate, ID: existing.ID, Version: 1, Note: models.Note{Name: "Стало"}},
+		{Op: models.BatchOpDelete, ID: missing},
+	}})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("результатов %d, ожидалось 3", len(results))
+	}
+	if results[0].Err != nil || results[0].Note == nil || results[0].Note.AuthorID != author {
+		t.Fatalf("создание в пакете: %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Note == nil || results[1].Note.Name != "Стало" || results[1].Note.Version != 2 {
+		t.Fatalf("обновление в пакете: %+v", results[1])
+	}
+	if !stdErrors.Is(results[2].Err, errors.ErrNoteNotFound) {
+		t.Fatalf("удаление несуществующей заметки: %v, ожидалась ErrNoteNotFound", results[2].Err)
+	}
+
+	if _, err := service.GetByID(ctx, results[0].ID); err != nil {
+		t.Fatalf("GetByID созданной в пакете заметки: %v", err)
+	}
+
+	// Атомарный пакет с ошибкой не оставляет изменений.
+	results, err = service.Batch(ctx, author, models.BatchRequest{Atomic: true, Operations: []models.BatchOperation{
+		{Op: models.BatchOpUpdate, ID: existing.ID, Version: 2, Note: models.Note{Name: "Откатится"}},
+		{Op: models.BatchOpDelete, ID: missing},
+	}})
+	if !stdErrors.Is(err, errors.ErrBatchAborted) {
+		t.Fatalf("атомарный пакет: %v, ожидалась ErrBatchAborted", err)
+	}
+	if len(results) != 2 || results[0].Err != nil {
+		t.Fatalf("результаты атомарного пакета %+v", results)
+	}
+
+	note, err := service.GetByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if note.Name != "Стало" || note.Version != 2 {
+		t.Fatalf("после отката заметка %q версии %d", note.Name, note.Version)
+	}
+}
+
+func claimedReminder(t *testing.T, service Service, now time.Time, noteID string) (models.Reminder, bool) {
+	t.Helper()
+
+	reminders, err := service.ClaimDueReminders(context.Background(), now, 1000)
+	if err != nil {
+		t.Fatalf("ClaimDueReminders: %v", err)
+	}
+	for _, reminder := range reminders {
+		if reminder.NoteID == noteID {
+			return reminder, true
+		}
+	}
+	return models.Reminder{}, false
+}
+
+// Напоминание сдвигается только после CompleteReminder, а недоставленное
+// возвращается, когда истекает пометка о доставке.
+func testReminderDelivery(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	remindAt := now.Add(-time.Hour)
+
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "Полить цветы", RemindAt: remindAt, Recurrence: models.RecurrenceDaily})
+
+	reminder, ok := claimedReminder(t, service, now, note.ID)
+	if !ok {
+		t.Fatal("наступившее напоминание не выдано")
+	}
+	if !reminder.RemindAt.Equal(remindAt) || !reminder.NextRemindAt.Equal(remindAt.Add(24*time.Hour)) {
+		t.Fatalf("выдано напоминание %+v", reminder)
+	}
+
+	if _, ok := claimedReminder(t, service, now, note.ID); ok {
+		t.Fatal("доставляемое напоминание выдано повторно")
+	}
+
+	retryAt := now.Add(reminderClaimTTL + time.Second)
+	stale := reminder
+	reminder, ok = claimedReminder(t, service, retryAt, note.ID)
+	if !ok {
+		t.Fatal("недоставленное напоминание не выдано после истечения пометки")
+	}
+
+	// Запоздалое завершение по истёкшей пометке не сдвигает напоминание,
+	// которое уже забрали заново.
+	if err := service.CompleteReminder(ctx, stale); err != nil {
+		t.Fatalf("CompleteReminder по истёкшей пометке: %v", err)
+	}
+	upcoming, err := service.GetUpcomingReminders(ctx, author, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetUpcomingReminders: %v", err)
+	}
+	if len(upcoming) != 1 || !upcoming[0].RemindAt.Equal(remindAt) {
+		t.Fatalf("после завершения по истёкшей пометке напоминания %+v", upcoming)
+	}
+
+	if err := service.CompleteReminder(ctx, reminder); err != nil {
+		t.Fatalf("CompleteReminder: %v", err)
+	}
+	if _, ok := claimedReminder(t, service, retryAt, note.ID); ok {
+		t.Fatal("доставленное напоминание выдано повторно")
+	}
+
+	upcoming, err = service.GetUpcomingReminders(ctx, author, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetUpcomingReminders: %v", err)
+	}
+	if len(upcoming) != 1 || !upcoming[0].RemindAt.Equal(remindAt.Add(24*time.Hour)) {
+		t.Fatalf("после доставки напоминания %+v", upcoming)
+	}
+}
+
+func testNotebooks(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	root, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: author, Name: "Работа"})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	child, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: author, Name: "Проекты", ParentID: root.ID})
+	if err != nil {
+		t.Fatalf("CreateNotebook вложенного блокнота: %v", err)
+	}
+	foreign, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: newTestAuthor(), Name: "Чужой"})
+	if err != nil {
+		t.Fatalf("CreateNotebook чужого блокнота: %v", err)
+	}
+
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "План"})
+	if _, err := service.MoveNote(ctx, note.ID, foreign.ID, 0); !stdErrors.Is(err, errors.ErrNotebookNotFound) {
+		t.Fatalf("MoveNote в чужой блокнот: %v, ожидалась ErrNotebookNotFound", err)
+	}
+	if _, err := service.MoveNote(ctx, note.ID, child.ID, 0); err != nil {
+		t.Fatalf("MoveNote: %v", err)
+	}
+
+	page, err := service.GetAll(ctx, author, models.NoteQuery{NotebookID: root.ID, IncludeSubnotebooks: true})
+	if err != nil {
+		t.Fatalf("GetAll по блокноту: %v", err)
+	}
+	if ids := noteIDs(page.Notes); !slices.Equal(ids, []string{note.ID}) {
+		t.Fatalf("в блокноте с вложенными %v, ожидалась %s", ids, note.ID)
+	}
+	page, err = service.GetAll(ctx, author, models.NoteQuery{NotebookID: root.ID})
+	if err != nil {
+		t.Fatalf("GetAll по блокноту: %v", err)
+	}
+	if len(page.Notes) != 0 {
+		t.Fatalf("в самом блокноте %v, ожидалось пусто", noteIDs(page.Notes))
+	}
+
+	// Удаление с переносом поднимает вложенный блокнот на уровень выше, а
+	// заметки переносит с новой версией, как MoveNote.
+	inRoot := createTestNote(t, service, models.Note{AuthorID: author, Name: "В корне", NotebookID: root.ID})
+	if err := service.DeleteNotebook(ctx, *root, models.NotebookDeleteReparent); err != nil {
+		t.Fatalf("DeleteNotebook reparent: %v", err)
+	}
+	reparented, err := service.GetByID(ctx, inRoot.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if reparented.NotebookID != "" || reparented.Version != inRoot.Version+1 {
+		t.Fatalf("после удаления блокнота заметка %+v", reparented)
+	}
+	if _, err := service.GetRevision(ctx, inRoot.ID, inRoot.Version); err != nil {
+		t.Fatalf("GetRevision версии до переноса: %v", err)
+	}
+	if _, err := service.GetNotebook(ctx, root.ID); !stdErrors.Is(err, errors.ErrNotebookNotFound) {
+		t.Fatalf("GetNotebook удалённого блокнота: %v, ожидалась ErrNotebookNotFound", err)
+	}
+	moved, err := service.GetNotebook(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("GetNotebook: %v", err)
+	}
+	if moved.ParentID != "" {
+		t.Fatalf("после удаления родителя блокнот вложен в %q", moved.ParentID)
+	}
+
+	// Каскадное удаление уносит заметки в корзину.
+	if err := service.DeleteNotebook(ctx, *moved, models.NotebookDeleteCascade); err != nil {
+		t.Fatalf("DeleteNotebook cascade: %v", err)
+	}
+	if _, err := service.GetByID(ctx, note.ID); !stdErrors.Is(err, errors.ErrNoteNotFound) {
+		t.Fatalf("GetByID заметки удалённого блокнота: %v, ожидалась ErrNoteNotFound", err)
+	}
+	trashed, err := service.GetTrashedByID(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetTrashedByID: %v", err)
+	}
+	if trashed.NotebookID != "" {
+		t.Fatalf("заметка в корзине осталась в блокноте %q", trashed.NotebookID)
+	}
+
+	notebooks, err := service.GetNotebooks(ctx, author)
+	if err != nil {
+		t.Fatalf("GetNotebooks: %v", err)
+	}
+	if len(notebooks) != 0 {
+		t.Fatalf("после удаления остались блокноты %+v", notebooks)
+	}
+}
+
+func testAttachments(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "С вложением"})
+
+	attachment, err := service.UploadAttachment(ctx, *note, "список.txt", "text/plain", strings.NewReader("хлеб, молоко"))
+	if err != nil {
+		t.Fatalf("UploadAttachment: %v", err)
+	}
+	if attachment.NoteID != note.ID || attachment.AuthorID != author || attachment.Size != int64(len("хлеб, молоко")) {
+		t.Fatalf("загруженное вложение %+v", attachment)
+	}
+
+	attachments, err := service.GetAttachments(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetAttachments: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].ID != attachment.ID || attachments[0].Filename != "список.txt" {
+		t.Fatalf("вложения заметки %+v", attachments)
+	}
+
+	opened, content, err := service.OpenAttachment(ctx, note.ID, attachment.ID)
+	if err != nil {
+		t.Fatalf("OpenAttachment: %v", err)
+	}
+	data, err := io.ReadAll(content)
+	content.Close()
+	if err != nil {
+		t.Fatalf("чтение вложения: %v", err)
+	}
+	if string(data) != "хлеб, молоко" || opened.ContentType != "text/plain" {
+		t.Fatalf("открыто вложение %+v с содержимым %q", opened, data)
+	}
+
+	other := createTestNote(t, service, models.Note{AuthorID: author, Name: "Другая"})
+	if _, _, err := service.OpenAttachment(ctx, other.ID, attachment.ID); !stdErrors.Is(err, errors.ErrAttachmentNotFound) {
+		t.Fatalf("OpenAttachment через другую заметку: %v, ожидалась ErrAttachmentNotFound", err)
+	}
+
+	if err := service.DeleteAttachment(ctx, note.ID, attachment.ID); err != nil {
+		t.Fatalf("DeleteAttachment: %v", err)
+	}
+	if _, _, err := service.OpenAttachment(ctx, note.ID, attachment.ID); !stdErrors.Is(err, errors.ErrAttachmentNotFound) {
+		t.Fatalf("OpenAttachment удалённого вложения: %v, ожидалась ErrAttachmentNotFound", err)
+	}
+}
+
+func testLinks(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "Публичная", Content: "текст"})
+
+	if _, err := service.CreateLink(ctx, *note, models.CreateLinkRequest{ExpiresAt: time.Now().Add(-time.Minute)}); !stdErrors.Is(err, errors.ErrInvalidLink) {
+		t.Fatalf("CreateLink с истёкшим сроком: %v, ожидалась ErrInvalidLink", err)
+	}
+
+	link, err := service.CreateLink(ctx, *note, models.CreateLinkRequest{Password: "секрет", ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	if link.Token == "" || !link.Protected || link.NoteID != note.ID {
+		t.Fatalf("созданная ссылка %+v", link)
+	}
+
+	if _, _, err := service.OpenLink(ctx, link.Token, "не тот"); !stdErrors.Is(err, errors.ErrLinkPassword) {
+		t.Fatalf("OpenLink с неверным паролем: %v, ожидалась ErrLinkPassword", err)
+	}
+	opened, openedLink, err := service.OpenLink(ctx, link.Token, "секрет")
+	if err != nil {
+		t.Fatalf("OpenLink: %v", err)
+	}
+	if opened.ID != note.ID || opened.Content != "текст" || openedLink.Views != 1 {
+		t.Fatalf("по ссылке открыта заметка %+v, просмотров %d", opened, openedLink.Views)
+	}
+
+	links, err := service.GetLinks(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetLinks: %v", err)
+	}
+	if len(links) != 1 || links[0].Token != link.Token {
+		t.Fatalf("ссылки заметки %+v", links)
+	}
+
+	if err := service.RevokeLink(ctx, note.ID, link.Token); err != nil {
+		t.Fatalf("RevokeLink: %v", err)
+	}
+	if _, _, err := service.OpenLink(ctx, link.Token, "секрет"); !stdErrors.Is(err, errors.ErrLinkNotFound) {
+		t.Fatalf("OpenLink отозванной ссылки: %v, ожидалась ErrLinkNotFound", err)
+	}
+	if err := service.RevokeLink(ctx, note.ID, link.Token); !stdErrors.Is(err, errors.ErrLinkNotFound) {
+		t.Fatalf("повторный RevokeLink: %v, ожидалась ErrLinkNotFound", err)
+	}
+}
+
+func testReminders(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	later := createTestNote(t, service, models.Note{AuthorID: author, Name: "Позже"})
+	sooner := createTestNote(t, service, models.Note{AuthorID: author, Name: "Раньше"})
+	createTestNote(t, service, models.Note{AuthorID: newTestAuthor(), Name: "Чужое", RemindAt: now.Add(time.Hour)})
+
+	if _, err := service.SetReminder(ctx, later.ID, models.ReminderRequest{Recurrence: models.RecurrenceDaily}, 0); !stdErrors.Is(err, errors.ErrInvalidReminder) {
+		t.Fatalf("SetReminder повтора без времени: %v, ожидалась ErrInvalidReminder", err)
+	}
+	if _, err := service.SetReminder(ctx, later.ID, models.ReminderRequest{RemindAt: now.Add(3 * time.Hour)}, 0); err != nil {
+		t.Fatalf("SetReminder: %v", err)
+	}
+	if _, err := service.SetReminder(ctx, sooner.ID, models.ReminderRequest{RemindAt: now.Add(time.Hour)}, 0); err != nil {
+		t.Fatalf("SetReminder: %v", err)
+	}
+
+	upcoming, err := service.GetUpcomingReminders(ctx, author, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetUpcomingReminders: %v", err)
+	}
+	if len(upcoming) != 2 || upcoming[0].NoteID != sooner.ID || upcoming[1].NoteID != later.ID {
+		t.Fatalf("ближайшие напоминания %+v", upcoming)
+	}
+
+	upcoming, err = service.GetUpcomingReminders(ctx, author, now.Add(2*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("GetUpcomingReminders: %v", err)
+	}
+	if len(upcoming) != 1 || upcoming[0].NoteID != sooner.ID {
+		t.Fatalf("напоминания до %v: %+v", now.Add(2*time.Hour), upcoming)
+	}
+
+	// Пустой запрос снимает напоминание.
+	if _, err := service.SetReminder(ctx, sooner.ID, models.ReminderRequest{}, 0); err != nil {
+		t.Fatalf("SetReminder без времени: %v", err)
+	}
+	upcoming, err = service.GetUpcomingReminders(ctx, author, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetUpcomingReminders: %v", err)
+	}
+	if len(upcoming) != 1 || upcoming[0].NoteID != later.ID {
+		t.Fatalf("после снятия напоминания %+v", upcoming)
+	}
+
+	if err := service.DeliverToInbox(ctx, upcoming[0]); err != nil {
+		t.Fatalf("DeliverToInbox: %v", err)
+	}
+	inbox, err := service.GetInbox(ctx, author, 10)
+	if err != nil {
+		t.Fatalf("GetInbox: %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].UserID != author || inbox[0].Reminder.NoteID != later.ID {
+		t.Fatalf("входящие %+v", inbox)
+	}
+}
+
+func claimedWebhookJob(t *testing.T, service Service, webhookID string) (models.WebhookJob, bool) {
+	t.Helper()
+
+	jobs, err := service.ClaimWebhookJobs(context.Background(), time.Now().Add(time.Second), 1000)
+	if err != nil {
+		t.Fatalf("ClaimWebhookJobs: %v", err)
+	}
+	for _, job := range jobs {
+		if job.WebhookID == webhookID {
+			return job, true
+		}
+	}
+	return models.WebhookJob{}, false
+}
+
+func testWebhooks(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+
+	if _, err := service.CreateWebhook(ctx, models.Webhook{AuthorID: author, URL: "http://127.0.0.1/hook"}); !stdErrors.Is(err, errors.ErrInvalidWebhook) {
+		t.Fatalf("CreateWebhook на внутренний адрес: %v, ожидалась ErrInvalidWebhook", err)
+	}
+
+	webhook, err := service.CreateWebhook(ctx, models.Webhook{AuthorID: author, URL: "https://93.184.216.34/hook", Events: []string{models.NoteEventUpdated}})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	if !webhook.Active || webhook.Secret == "" {
+		t.Fatalf("созданный webhook %+v", webhook)
+	}
+
+	// Webhook подписан только на обновления.
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "Событие"})
+	if _, ok := claimedWebhookJob(t, service, webhook.ID); ok {
+		t.Fatal("доставка создания для webhook, подписанного только на обновления")
+	}
+
+	webhook.Events = nil
+	updatedWebhook, err := service.UpdateWebhook(ctx, *webhook)
+	if err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	if len(updatedWebhook.Events) != 0 {
+		t.Fatalf("после обновления webhook подписан на %v", updatedWebhook.Events)
+	}
+
+	if _, err := service.Update(ctx, models.Note{ID: note.ID, AuthorID: author, Name: "Событие 2"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	job, ok := claimedWebhookJob(t, service, webhook.ID)
+	if !ok {
+		t.Fatal("доставка обновления не поставлена в очередь")
+	}
+	if job.Event.Type != models.NoteEventUpdated || job.Event.NoteID != note.ID || job.Webhook.ID != webhook.ID {
+		t.Fatalf("выдана доставка %+v", job)
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		JobID:     job.ID,
+		EventID:   job.Event.ID,
+		EventType: job.Event.Type,
+		NoteID:    job.Event.NoteID,
+		Attempt:   job.Attempt,
+		Success:   true,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if err := service.CompleteWebhookJob(ctx, job, delivery); err != nil {
+		t.Fatalf("CompleteWebhookJob: %v", err)
+	}
+	if _, ok := claimedWebhookJob(t, service, webhook.ID); ok {
+		t.Fatal("подтверждённая доставка выдана повторно")
+	}
+
+	deliveries, err := service.GetWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries: %v", err)
+	}
+	if len(deliveries) != 1 || !deliveries[0].Success || deliveries[0].JobID != job.ID {
+		t.Fatalf("журнал доставок %+v", deliveries)
+	}
+
+	if err := service.DeleteWebhook(ctx, webhook.ID); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := service.GetWebhook(ctx, webhook.ID); !stdErrors.Is(err, errors.ErrWebhookNotFound) {
+		t.Fatalf("GetWebhook удалённого webhook: %v, ожидалась ErrWebhookNotFound", err)
+	}
+}
+
+func testImportExport(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	existing := createTestNote(t, service, models.Note{AuthorID: author, Name: "Была", Content: "текст"})
+
+	items := []models.ImportItem{
+		{Source: "a.md", Note: models.Note{Name: "Новая", Content: "новый текст", Tags: []string{"импорт"}}},
+		{Source: "b.md", Note: models.Note{Name: "Была", Content: "текст"}},
+		{Source: "c.md", Error: "не разобран"},
+	}
+
+	job, err := service.ImportNotes(ctx, author, models.ImportRequest{Format: models.ImportFormatZip, DryRun: true, Items: items})
+	if err != nil {
+		t.Fatalf("ImportNotes dry run: %v", err)
+	}
+	if job.Status != models.ImportStatusDone || job.Created != 1 || job.Duplicates != 1 || job.Failed != 1 {
+		t.Fatalf("пробный импорт %+v", job)
+	}
+
+	job, err = service.ImportNotes(ctx, author, models.ImportRequest{Format: models.ImportFormatZip, Items: items})
+	if err != nil {
+		t.Fatalf("ImportNotes: %v", err)
+	}
+	if job.Status != models.ImportStatusDone || job.Created != 1 || job.Duplicates != 1 || job.Failed != 1 {
+		t.Fatalf("импорт %+v", job)
+	}
+	if job.Items[1].DuplicateOf != existing.ID {
+		t.Fatalf("дубликат указывает на %q, ожидалась %s", job.Items[1].DuplicateOf, existing.ID)
+	}
+
+	stored, err := service.GetImportJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	if stored.AuthorID != author || stored.Created != 1 || len(stored.Items) != 3 {
+		t.Fatalf("сохранённая задача импорта %+v", stored)
+	}
+
+	if _, err := service.FailStaleImports(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("FailStaleImports: %v", err)
+	}
+	if stored, err = service.GetImportJob(ctx, job.ID); err != nil || stored.Status != models.ImportStatusDone {
+		t.Fatalf("завершённая задача после FailStaleImports: %+v, %v", stored, err)
+	}
+
+	var exported []string
+	err = service.ExportNotes(ctx, author, func(note models.Note) error {
+		if note.AuthorID != author {
+			t.Fatalf("в экспорт попала заметка автора %d", note.AuthorID)
+		}
+		exported = append(exported, note.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportNotes: %v", err)
+	}
+	slices.Sort(exported)
+	if want := []string{"Была", "Новая"}; !slices.Equal(exported, want) {
+		t.Fatalf("экспортированы %v, ожидались %v", exported, want)
+	}
+}
+
+// Снятие закрепления и архива возвращает заметку в обычный список.
+func testStates(t *testing.T, service Service) {
+	ctx := context.Background()
+	author := newTestAuthor()
+	note := createTestNote(t, service, models.Note{AuthorID: author, Name: "Состояния"})
+
+	if _, err := service.SetArchived(ctx, note.ID, true, 0); err != nil {
+		t.Fatalf("SetArchived: %v", err)
+	}
+	page, err := service.GetAll(ctx, author, models.NoteQuery{})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(page.Notes) != 0 {
+		t.Fatalf("архивная заметка в обычном списке: %v", noteIDs(page.Notes))
+	}
+	if _, err := service.SetPinned(ctx, note.ID, true, 0); !stdErrors.Is(err, errors.ErrPinArchived) {
+		t.Fatalf("SetPinned архивной заметки: %v, ожидалась ErrPinArchived", err)
+	}
+
+	if _, err := service.SetArchived(ctx, note.ID, false, 0); err != nil {
+		t.Fatalf("SetArchived false: %v", err)
+	}
+	if _, err := service.SetPinned(ctx, note.ID, true, 0); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	unpinned, err := service.SetPinned(ctx, note.ID, false, 0)
+	if err != nil {
+		t.Fatalf("SetPinned false: %v", err)
+	}
+	if unpinned.Pinned || unpinned.Archived || unpinned.Version != note.Version+4 {
+		t.Fatalf("после снятия состояний заметка %+v", unpinned)
+	}
+
+	page, err = service.GetAll(ctx, author, models.NoteQuery{})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if ids := noteIDs(page.Notes); !slices.Equal(ids, []string{note.ID}) {
+		t.Fatalf("в списке %v, ожидалась %s", ids, note.ID)
+	}
+
+	missing := "000000000000000000000000"
+	if _, err := service.SetPinned(ctx, missing, true, 0); !stdErrors.Is(err, errors.ErrNoteNotFound) {
+		t.Fatalf("SetPinned несуществующей заметки: %v, ожидалась ErrNoteNotFound", err)
+	}
+	if _, err := service.SetArchived(ctx, missing, true, 0); !stdErrors.Is(err, errors.ErrNoteNotFound) {
+		t.Fatalf("SetArchived несуществующей заметки: %v, ожидалась ErrNoteNotFound", err)
+	}
+}
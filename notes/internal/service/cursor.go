@@ -0,0 +1,39 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+)
+
+type pageCursor struct {
+	ID     string `json:"id"`
+	Value  string `json:"v,omitempty"`
+	Pinned bool   `json:"p,omitempty"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+	}
+	if cursor.ID == "" {
+		return nil, fmt.Errorf("%w: пустой ID", errors.ErrInvalidCursor)
+	}
+
+	return &cursor, nil
+}
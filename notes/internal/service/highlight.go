@@ -0,0 +1,153 @@
+package service
+
+import (
+	"html"
+	"notes/internal/models"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightOpen    = "<mark>"
+	highlightClose   = "</mark>"
+	snippetRadius    = 40
+	maxSnippets      = 3
+	snippetEllipsis  = "…"
+	minSearchTermLen = 2
+)
+
+type matchRange struct {
+	start int
+	end   int
+}
+
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(field, isNotWordRune) {
+			word = strings.ToLower(word)
+			if len([]rune(word)) >= minSearchTermLen {
+				terms = append(terms, termStem(word))
+			}
+		}
+	}
+	return terms
+}
+
+// Стемминг в индексе Mongo не отдаёт найденные словоформы, поэтому
+// подсветка сравнивает слова с укороченной основой поискового терма.
+func termStem(term string) string {
+	runes := []rune(term)
+	switch {
+	case len(runes) > 5:
+		return string(runes[:len(runes)-2])
+	case len(runes) > 3:
+		return string(runes[:len(runes)-1])
+	default:
+		return term
+	}
+}
+
+func highlightFields(note models.Note, terms []string) map[string][]string {
+	highlights := make(map[string][]string)
+	if len(terms) == 0 {
+		return highlights
+	}
+
+	if name, ok := highlightText(note.Name, terms); ok {
+		highlights["name"] = []string{name}
+	}
+	if snippets := contentSnippets(note.Content, terms); len(snippets) > 0 {
+		highlights["content"] = snippets
+	}
+
+	return highlights
+}
+
+func highlightText(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	matches := findMatches(runes, terms)
+	if len(matches) == 0 {
+		return text, false
+	}
+	return markRange(runes, matches, 0, len(runes)), true
+}
+
+func contentSnippets(text string, terms []string) []string {
+	runes := []rune(text)
+	matches := findMatches(runes, terms)
+
+	var snippets []string
+	for i := 0; i < len(matches) && len(snippets) < maxSnippets; {
+		start := max(0, matches[i].start-snippetRadius)
+		end := min(len(runes), matches[i].end+snippetRadius)
+
+		j := i + 1
+		for j < len(matches) && matches[j].start < end {
+			end = min(len(runes), matches[j].end+snippetRadius)
+			j++
+		}
+
+		snippet := strings.TrimSpace(markRange(runes, matches[i:j], start, end))
+		if start > 0 {
+			snippet = snippetEllipsis + snippet
+		}
+		if end < len(runes) {
+			snippet += snippetEllipsis
+		}
+		snippets = append(snippets, snippet)
+		i = j
+	}
+
+	return snippets
+}
+
+func findMatches(runes []rune, terms []string) []matchRange {
+	var matches []matchRange
+	for i := 0; i < len(runes); {
+		if isNotWordRune(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !isNotWordRune(runes[i]) {
+			i++
+		}
+
+		word := strings.ToLower(string(runes[start:i]))
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				matches = append(matches, matchRange{start: start, end: i})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// markRange экранирует текст заметки: подсветку отдают как готовый HTML, и
+// разметка из самой заметки не должна в него попасть.
+func markRange(runes []rune, matches []matchRange, start, end int) string {
+	var builder strings.Builder
+	position := start
+	for _, match := range matches {
+		if match.start < start || match.end > end {
+			continue
+		}
+		builder.WriteString(html.EscapeString(string(runes[position:match.start])))
+		builder.WriteString(highlightOpen)
+		builder.WriteString(html.EscapeString(string(runes[match.start:match.end])))
+		builder.WriteString(highlightClose)
+		position = match.end
+	}
+	builder.WriteString(html.EscapeString(string(runes[position:end])))
+	return builder.String()
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
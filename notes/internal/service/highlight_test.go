@@ -0,0 +1,37 @@
+package service
+
+import (
+	"notes/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestHighlightFieldsEscapesHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		note  models.Note
+		field string
+		want  []string
+	}{
+		{
+			"заголовок",
+			models.Note{Name: `<img src=x onerror=alert(1)> план`},
+			"name",
+			[]string{`&lt;img src=x onerror=alert(1)&gt; <mark>план</mark>`},
+		},
+		{
+			"содержимое",
+			models.Note{Content: `<script>план</script> & "дела"`},
+			"content",
+			[]string{`&lt;script&gt;<mark>план</mark>&lt;/script&gt; &amp; &#34;дела&#34;`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			highlights := highlightFields(test.note, searchTerms("план"))
+			if got := highlights[test.field]; !slices.Equal(got, test.want) {
+				t.Fatalf("подсветка %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+}
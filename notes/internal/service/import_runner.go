@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"sync"
+	"time"
+)
+
+const (
+	// Фоновая задача сохраняет прогресс не реже importHeartbeatInterval;
+	// задача, не обновлявшаяся ImportStaleAfter, считается прерванной.
+	importHeartbeatInterval = 30 * time.Second
+	ImportStaleAfter        = 5 * time.Minute
+	importSaveTimeout       = 10 * time.Second
+)
+
+// importRunner отслеживает фоновые импорты, чтобы остановка сервера
+// прерывала их и дожидалась сохранения итога.
+type importRunner struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+func newImportRunner() *importRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &importRunner{ctx: ctx, cancel: cancel}
+}
+
+// Go запускает импорт; после Stop возвращает false и ничего не запускает.
+func (r *importRunner) Go(run func(ctx context.Context)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return false
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		run(r.ctx)
+	}()
+	return true
+}
+
+func (r *importRunner) Stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	r.cancel()
+	r.wg.Wait()
+}
+
+// saveContext даёт сохранить итог задачи и после отмены её контекста.
+func saveContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), importSaveTimeout)
+}
+
+func failImport(job *models.ImportJob, err error) {
+	job.Status = models.ImportStatusFailed
+	job.Error = err.Error()
+	job.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// interruptedImport проверяет отмену фонового импорта между заметками.
+func interruptedImport(ctx context.Context, job *models.ImportJob) bool {
+	if err := ctx.Err(); err != nil {
+		failImport(job, fmt.Errorf("%w: %v", errors.ErrImportInterrupted, err))
+		return true
+	}
+	return false
+}
+
+func importProgressDue(job *models.ImportJob, lastSave time.Time) bool {
+	return job.Processed%importProgressStep == 0 || time.Since(lastSave) >= importHeartbeatInterval
+}
+
+func staleImportError(staleBefore time.Time) error {
+	return fmt.Errorf("%w: задача не обновлялась с %s", errors.ErrImportInterrupted, staleBefore.UTC().Format(time.RFC3339))
+}
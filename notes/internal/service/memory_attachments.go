@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAttachment struct {
+	models.Attachment
+	data []byte
+}
+
+func (m *MemoryService) UploadAttachment(ctx context.Context, note models.Note, filename string, contentType string, content io.Reader) (*models.Attachment, error) {
+	m.mu.RLock()
+	used := m.attachmentsUsage(note.AuthorID)
+	m.mu.RUnlock()
+
+	limit := m.attachmentMaxSize
+	limitErr := errors.ErrAttachmentTooLarge
+	if remaining := m.attachmentQuota - used; remaining < limit {
+		limit = remaining
+		limitErr = errors.ErrAttachmentQuota
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: использовано %d из %d байт", errors.ErrAttachmentQuota, used, m.attachmentQuota)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: допустимо не больше %d байт", limitErr, limit)
+	}
+
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID().Hex(),
+		NoteID:      note.ID,
+		AuthorID:    note.AuthorID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		UploadedAt:  time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	// Квота проверялась до чтения файла; под блокировкой проверяем ещё раз,
+	// чтобы параллельные загрузки не превысили её вместе.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if used := m.attachmentsUsage(note.AuthorID); used+attachment.Size > m.attachmentQuota {
+		return nil, fmt.Errorf("%w: использовано %d из %d байт", errors.ErrAttachmentQuota, used, m.attachmentQuota)
+	}
+	m.attachments[attachment.ID] = memoryAttachment{Attachment: attachment, data: data}
+
+	return &attachment, nil
+}
+
+func (m *MemoryService) GetAttachments(ctx context.Context, noteID string) ([]models.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	attachments := make([]models.Attachment, 0)
+	for _, attachment := range m.attachments {
+		if attachment.NoteID == noteID {
+			attachments = append(attachments, attachment.Attachment)
+		}
+	}
+	slices.SortFunc(attachments, func(a, b models.Attachment) int {
+		return a.UploadedAt.Compare(b.UploadedAt)
+	})
+
+	return attachments, nil
+}
+
+func (m *MemoryService) OpenAttachment(ctx context.Context, noteID string, attachmentID string) (*models.Attachment, io.ReadSeekCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	attachment, err := m.findAttachment(noteID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &attachment.Attachment, nopCloser{bytes.NewReader(attachment.data)}, nil
+}
+
+func (m *MemoryService) DeleteAttachment(ctx context.Context, noteID string, attachmentID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attachment, err := m.findAttachment(noteID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	delete(m.attachments, attachment.ID)
+	return nil
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) findAttachment(noteID string, attachmentID string) (*memoryAttachment, error) {
+	attachment, ok := m.attachments[attachmentID]
+	if !ok || attachment.NoteID != noteID {
+		return nil, fmt.Errorf("%w: %s", errors.ErrAttachmentNotFound, attachmentID)
+	}
+	return &attachment, nil
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) attachmentsUsage(authorID int) int64 {
+	var total int64
+	for _, attachment := range m.attachments {
+		if attachment.AuthorID == authorID {
+			total += attachment.Size
+		}
+	}
+	return total
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Пакет выполняется под m.mu целиком. Для атомарного пакета снимается снимок
+// заметок и ревизий, к которому они возвращаются при ошибке; записи в обоих
+// хранилищах заменяют значения целиком, поэтому снимку хватает копии карт.
+func (m *MemoryService) Batch(ctx context.Context, authorId int, request models.BatchRequest) ([]models.BatchResult, error) {
+	if len(request.Operations) == 0 || len(request.Operations) > models.MaxBatchOperations {
+		return nil, fmt.Errorf("%w: операций %d, допустимо от 1 до %d", errors.ErrInvalidBatch, len(request.Operations), models.MaxBatchOperations)
+	}
+
+	m.mu.Lock()
+	var notes map[string]models.Note
+	var revisions map[string][]models.NoteRevision
+	if request.Atomic {
+		notes, revisions = maps.Clone(m.notes), maps.Clone(m.revisions)
+	}
+
+	results, changes := m.runBatch(authorId, request.Operations, request.Atomic)
+	if request.Atomic {
+		for _, result := range results {
+			if result.Err != nil {
+				m.notes, m.revisions = notes, revisions
+				m.mu.Unlock()
+				return results, fmt.Errorf("%w: операция %d: %v", errors.ErrBatchAborted, result.Index, result.Err)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	m.publishNoteChanges(changes)
+
+	return results, nil
+}
+
+func (m *MemoryService) runBatch(authorID int, operations []models.BatchOperation, stopOnError bool) ([]models.BatchResult, []noteChange) {
+	results := make([]models.BatchResult, 0, len(operations))
+	changes := make([]noteChange, 0, len(operations))
+	for i, operation := range operations {
+		result := models.BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+
+		note, err := m.runBatchOperation(authorID, operation)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.ID = note.ID
+			if operation.Op != models.BatchOpDelete {
+				result.Note = note
+			}
+			changes = append(changes, noteChange{eventType: batchEventType(operation.Op), note: *note})
+		}
+
+		results = append(results, result)
+		if err != nil && stopOnError {
+			break
+		}
+	}
+	return results, changes
+}
+
+func (m *MemoryService) runBatchOperation(authorID int, operation models.BatchOperation) (*models.Note, error) {
+	if !operation.Valid() {
+		return nil, fmt.Errorf("%w: op=%q, id=%q", errors.ErrInvalidBatch, operation.Op, operation.ID)
+	}
+
+	if operation.Op == models.BatchOpCreate {
+		note := operation.Note
+		note.AuthorID = authorID
+
+		tags, err := models.NormalizeTags(note.Tags)
+		if err != nil {
+			return nil, err
+		}
+		note.Tags = tags
+
+		return m.createNote(note)
+	}
+
+	existingNote, err := m.activeNote(operation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if operation.Op == models.BatchOpDelete {
+		if !existingNote.IsOwner(authorID) {
+			return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrNoteForbidden, operation.ID)
+		}
+		return m.deleteNote(operation.ID, operation.Version)
+	}
+
+	if !existingNote.CanWrite(authorID) {
+		return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrNoteForbidden, operation.ID)
+	}
+
+	note := operation.Note
+	note.ID = operation.ID
+	note.AuthorID = existingNote.AuthorID
+	note.Version = operation.Version
+
+	tags, err := models.NormalizeTags(note.Tags)
+	if err != nil {
+		return nil, err
+	}
+	note.Tags = tags
+
+	return m.updateNote(note)
+}
+
+func (m *MemoryService) ImportNotes(ctx context.Context, authorId int, request models.ImportRequest) (*models.ImportJob, error) {
+	if len(request.Items) == 0 || len(request.Items) > models.MaxImportItems {
+		return nil, fmt.Errorf("%w: заметок %d, допустимо от 1 до %d", errors.ErrInvalidImport, len(request.Items), models.MaxImportItems)
+	}
+
+	job := &models.ImportJob{
+		ID:        primitive.NewObjectID().Hex(),
+		AuthorID:  authorId,
+		Format:    request.Format,
+		DryRun:    request.DryRun,
+		Status:    models.ImportStatusPending,
+		Total:     len(request.Items),
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if len(request.Items) <= m.importSyncLimit {
+		m.runImport(ctx, job, request.Items, false)
+		m.saveImportJob(*job)
+		return job, nil
+	}
+
+	m.saveImportJob(*job)
+
+	background := *job
+	started := m.imports.Go(func(ctx context.Context) {
+		m.runImport(ctx, &background, request.Items, true)
+		m.saveImportJob(background)
+	})
+	if !started {
+		failImport(job, errors.ErrImportInterrupted)
+		m.saveImportJob(*job)
+	}
+
+	return job, nil
+}
+
+func (m *MemoryService) StopImports() {
+	m.imports.Stop()
+}
+
+func (m *MemoryService) FailStaleImports(ctx context.Context, staleBefore time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var failed int64
+	for id, job := range m.importJobs {
+		if job.Finished() || !job.UpdatedAt.Before(staleBefore) {
+			continue
+		}
+		failImport(&job, staleImportError(staleBefore))
+		job.UpdatedAt = job.FinishedAt
+		m.importJobs[id] = job
+		failed++
+	}
+	return failed, nil
+}
+
+func (m *MemoryService) GetImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.importJobs[id]
+	if !ok || time.Since(job.CreatedAt) > importJobTTL {
+		return nil, fmt.Errorf("%w: задача с ID %s не найдена", errors.ErrImportJobNotFound, id)
+	}
+
+	job.Items = append([]models.ImportItemReport(nil), job.Items...)
+	return &job, nil
+}
+
+func (m *MemoryService) runImport(ctx context.Context, job *models.ImportJob, items []models.ImportItem, background bool) {
+	job.Status = models.ImportStatusRunning
+	job.Items = make([]models.ImportItemReport, 0, len(items))
+
+	known := make(map[string]string)
+	m.mu.RLock()
+	for _, note := range m.authorNotes(job.AuthorID) {
+		known[noteContentHash(note)] = note.ID
+	}
+	m.mu.RUnlock()
+
+	lastSave := time.Now()
+	for i, item := range items {
+		if background && interruptedImport(ctx, job) {
+			return
+		}
+
+		report := m.importItem(job, i, item, known)
+		switch report.Status {
+		case models.ImportItemCreated, models.ImportItemWouldCreate:
+			job.Created++
+		case models.ImportItemDuplicate:
+			job.Duplicates++
+		default:
+			job.Failed++
+		}
+
+		job.Items = append(job.Items, report)
+		job.Processed++
+
+		if background && importProgressDue(job, lastSave) {
+			m.saveImportJob(*job)
+			lastSave = time.Now()
+		}
+	}
+
+	job.Status = models.ImportStatusDone
+	job.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func (m *MemoryService) importItem(job *models.ImportJob, index int, item models.ImportItem, known map[string]string) models.ImportItemReport {
+	report := models.ImportItemReport{Index: index, Source: item.Source, Name: item.Note.Name}
+	if item.Error != "" {
+		report.Status = models.ImportItemFailed
+		report.Error = item.Error
+		return report
+	}
+
+	note := item.Note
+	tags, err := models.NormalizeTags(note.Tags)
+	if err != nil {
+		report.Status = models.ImportItemFailed
+		report.Error = err.Error()
+		return report
+	}
+	note.Tags = tags
+
+	hash := noteContentHash(note)
+	if duplicateOf, found := known[hash]; found {
+		report.Status = models.ImportItemDuplicate
+		report.DuplicateOf = duplicateOf
+		return report
+	}
+
+	if job.DryRun {
+		known[hash] = ""
+		report.Status = models.ImportItemWouldCreate
+		return report
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	note.AuthorID = job.AuthorID
+	note.Shares = nil
+	note.DeletedAt = time.Time{}
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = now
+	}
+	if note.UpdatedAt.IsZero() {
+		note.UpdatedAt = note.CreatedAt
+	}
+
+	m.mu.Lock()
+	if m.checkNotebook(job.AuthorID, note.NotebookID) != nil {
+		note.NotebookID = ""
+	}
+	createdNote, err := m.insertNote(note)
+	m.mu.Unlock()
+	if err != nil {
+		report.Status = models.ImportItemFailed
+		report.Error = err.Error()
+		return report
+	}
+
+	known[hash] = createdNote.ID
+	m.publishNoteEvent(models.NoteEventCreated, *createdNote)
+	report.Status = models.ImportItemCreated
+	report.NoteID = createdNote.ID
+	return report
+}
+
+func (m *MemoryService) saveImportJob(job models.ImportJob) {
+	job.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	job.Items = append([]models.ImportItemReport(nil), job.Items...)
+
+	m.mu.Lock()
+	m.importJobs[job.ID] = job
+	m.mu.Unlock()
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/events"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MemoryService) SubscribeEvents(userID int) *events.Subscription {
+	return m.events.Subscribe(userID)
+}
+
+func (m *MemoryService) ReplayEvents(ctx context.Context, userID int, afterID int64) ([]models.NoteEvent, error) {
+	return m.events.Replay(userID, afterID)
+}
+
+func (m *MemoryService) publishNoteEvent(eventType string, note models.Note) {
+	audience := append([]int{note.AuthorID}, note.SharedUserIDs()...)
+	m.publishNoteEventTo(eventType, note, audience)
+}
+
+func (m *MemoryService) publishNoteEventTo(eventType string, note models.Note, audience []int) {
+	event := models.NoteEvent{
+		Type:       eventType,
+		NoteID:     note.ID,
+		AuthorID:   note.AuthorID,
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if eventType != models.NoteEventDeleted {
+		event.Note = &note
+	}
+
+	published, err := m.events.Publish(event, audience)
+	if err != nil {
+		fmt.Printf("Ошибка публикации события %s для заметки %s: %v\n", eventType, note.ID, err)
+	}
+
+	m.enqueueWebhooks(published, audience)
+}
+
+func (m *MemoryService) publishNoteChanges(changes []noteChange) {
+	for _, change := range changes {
+		m.publishNoteEvent(change.eventType, change.note)
+	}
+}
+
+func (m *MemoryService) enqueueWebhooks(event models.NoteEvent, audience []int) {
+	m.mu.RLock()
+	now := time.Now().UTC()
+	jobs := make([]models.WebhookJob, 0)
+	for _, webhook := range m.webhooks {
+		if !webhook.Active || !webhook.Subscribed(event.Type) || !slices.Contains(audience, webhook.AuthorID) {
+			continue
+		}
+		jobs = append(jobs, models.WebhookJob{
+			ID:         primitive.NewObjectID().Hex(),
+			WebhookID:  webhook.ID,
+			Attempt:    1,
+			Event:      event.VisibleTo(webhook.AuthorID),
+			EnqueuedAt: now,
+		})
+	}
+	m.mu.RUnlock()
+
+	if err := m.webhookQueue.Enqueue(jobs...); err != nil {
+		fmt.Printf("Ошибка постановки webhook для заметки %s: %v\n", event.NoteID, err)
+	}
+}
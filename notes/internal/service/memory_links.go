@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (m *MemoryService) CreateLink(ctx context.Context, note models.Note, request models.CreateLinkRequest) (*models.ShareLink, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if !request.ExpiresAt.IsZero() && !request.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: срок действия уже истёк", errors.ErrInvalidLink)
+	}
+
+	token, err := newLinkToken()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrLinkCreation, err)
+	}
+
+	link := models.ShareLink{
+		Token:     token,
+		NoteID:    note.ID,
+		AuthorID:  note.AuthorID,
+		ExpiresAt: request.ExpiresAt.UTC(),
+		CreatedAt: now,
+	}
+
+	if request.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidLink, err)
+		}
+		link.PasswordHash = string(hash)
+		link.Protected = true
+	}
+
+	m.mu.Lock()
+	m.links[link.Token] = link
+	m.mu.Unlock()
+
+	return &link, nil
+}
+
+// Истёкшие ссылки в Mongo удаляет TTL-индекс, поэтому и здесь их не видно.
+func (m *MemoryService) GetLinks(ctx context.Context, noteID string) ([]models.ShareLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	links := make([]models.ShareLink, 0)
+	for _, link := range m.links {
+		if link.NoteID == noteID && !link.Expired(now) {
+			links = append(links, link)
+		}
+	}
+	slices.SortFunc(links, func(a, b models.ShareLink) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return links, nil
+}
+
+func (m *MemoryService) RevokeLink(ctx context.Context, noteID string, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	link, ok := m.links[token]
+	if !ok || link.NoteID != noteID {
+		return fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+	}
+
+	delete(m.links, token)
+	return nil
+}
+
+func (m *MemoryService) OpenLink(ctx context.Context, token string, password string) (*models.Note, *models.ShareLink, error) {
+	m.mu.RLock()
+	link, ok := m.links[token]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+	}
+
+	if link.Expired(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkExpired, link.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if link.Protected {
+		if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+			return nil, nil, fmt.Errorf("%w", errors.ErrLinkPassword)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	note, err := m.activeNote(link.NoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	link, ok = m.links[token]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+	}
+	link.Views++
+	m.links[token] = link
+
+	return cloneNote(&note), &link, nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MemoryService) CreateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error) {
+	if !notebook.Valid() {
+		return nil, fmt.Errorf("%w: название %q", errors.ErrInvalidNotebook, notebook.Name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkNotebook(notebook.AuthorID, notebook.ParentID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	notebook.ID = primitive.NewObjectID().Hex()
+	notebook.CreatedAt = now
+	notebook.UpdatedAt = now
+	m.notebooks[notebook.ID] = notebook
+
+	return &notebook, nil
+}
+
+func (m *MemoryService) GetNotebooks(ctx context.Context, authorId int) ([]models.Notebook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.authorNotebooks(authorId), nil
+}
+
+func (m *MemoryService) GetNotebook(ctx context.Context, id string) (*models.Notebook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notebook, err := m.findNotebook(id)
+	if err != nil {
+		return nil, err
+	}
+	return &notebook, nil
+}
+
+func (m *MemoryService) UpdateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error) {
+	if !notebook.Valid() {
+		return nil, fmt.Errorf("%w: название %q, родитель %q", errors.ErrInvalidNotebook, notebook.Name, notebook.ParentID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if notebook.ParentID != "" {
+		if err := m.checkNotebook(notebook.AuthorID, notebook.ParentID); err != nil {
+			return nil, err
+		}
+
+		notebooks := m.authorNotebooks(notebook.AuthorID)
+		if slices.Contains(models.NotebookDescendants(notebooks, notebook.ID), notebook.ParentID) {
+			return nil, fmt.Errorf("%w: блокнот нельзя вложить в собственный подблокнот", errors.ErrInvalidNotebook)
+		}
+	}
+
+	updated, err := m.findNotebook(notebook.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated.Name = notebook.Name
+	updated.ParentID = notebook.ParentID
+	updated.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	m.notebooks[updated.ID] = updated
+
+	return &updated, nil
+}
+
+func (m *MemoryService) DeleteNotebook(ctx context.Context, notebook models.Notebook, mode string) error {
+	if !models.ValidNotebookDeleteMode(mode) {
+		return fmt.Errorf("%w: режим удаления %q", errors.ErrInvalidNotebook, mode)
+	}
+
+	m.mu.Lock()
+	var movedNotes, trashedNotes []models.Note
+	if mode == models.NotebookDeleteReparent {
+		movedNotes = m.reparentNotebook(notebook)
+	} else {
+		trashedNotes = m.cascadeNotebook(notebook)
+	}
+	delete(m.notebooks, notebook.ID)
+	m.mu.Unlock()
+
+	for _, note := range movedNotes {
+		m.publishNoteEvent(models.NoteEventUpdated, note)
+	}
+	for _, note := range trashedNotes {
+		m.publishNoteEvent(models.NoteEventDeleted, note)
+	}
+
+	return nil
+}
+
+func (m *MemoryService) MoveNote(ctx context.Context, noteID string, notebookID string, version int) (*models.Note, error) {
+	m.mu.Lock()
+	note, err := m.versionedNote(noteID, version)
+	if err == nil {
+		err = m.checkNotebook(note.AuthorID, notebookID)
+	}
+	if err == nil {
+		m.saveRevision(note)
+		note.NotebookID = notebookID
+		note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		note.Version++
+		m.notes[note.ID] = note
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventUpdated, note)
+	return cloneNote(&note), nil
+}
+
+// reparentNotebook, как MoveNote, сохраняет ревизию и увеличивает версию
+// перенесённых заметок и возвращает их для событий; у заметок в корзине
+// меняется только блокнот. Вызывается под m.mu.
+func (m *MemoryService) reparentNotebook(notebook models.Notebook) []models.Note {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	var moved []models.Note
+	for id, note := range m.notes {
+		if note.AuthorID != notebook.AuthorID || note.NotebookID != notebook.ID {
+			continue
+		}
+		if note.DeletedAt.IsZero() {
+			m.saveRevision(note)
+			note.UpdatedAt = now
+			note.Version++
+		}
+		note.NotebookID = notebook.ParentID
+		m.notes[id] = note
+		if note.DeletedAt.IsZero() {
+			moved = append(moved, note)
+		}
+	}
+
+	for id, child := range m.notebooks {
+		if child.AuthorID == notebook.AuthorID && child.ParentID == notebook.ID {
+			child.ParentID = notebook.ParentID
+			m.notebooks[id] = child
+		}
+	}
+
+	return moved
+}
+
+// Заметки из удаляемых блокнотов уходят в корзину, а у заметок, уже лежащих
+// в корзине, блокнот сбрасывается. Возвращает заметки, ушедшие в корзину, для
+// событий удаления; вызывается под m.mu.
+func (m *MemoryService) cascadeNotebook(notebook models.Notebook) []models.Note {
+	ids := models.NotebookDescendants(m.authorNotebooks(notebook.AuthorID), notebook.ID)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	var trashed []models.Note
+	for id, note := range m.notes {
+		if note.AuthorID != notebook.AuthorID || !slices.Contains(ids, note.NotebookID) {
+			continue
+		}
+		if note.DeletedAt.IsZero() {
+			trashed = append(trashed, note)
+			note.DeletedAt = now
+		}
+		note.NotebookID = ""
+		m.notes[id] = note
+	}
+
+	for _, id := range ids {
+		delete(m.notebooks, id)
+	}
+
+	return trashed
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) findNotebook(id string) (models.Notebook, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Notebook{}, fmt.Errorf("%w: %v", errors.ErrNotebookNotFound, err)
+	}
+
+	notebook, ok := m.notebooks[objectID.Hex()]
+	if !ok {
+		return models.Notebook{}, fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, id)
+	}
+	return notebook, nil
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) authorNotebooks(authorID int) []models.Notebook {
+	notebooks := make([]models.Notebook, 0)
+	for _, notebook := range m.notebooks {
+		if notebook.AuthorID == authorID {
+			notebooks = append(notebooks, notebook)
+		}
+	}
+	slices.SortFunc(notebooks, func(a, b models.Notebook) int {
+		if result := strings.Compare(a.Name, b.Name); result != 0 {
+			return result
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return notebooks
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) checkNotebook(authorID int, notebookID string) error {
+	if notebookID == "" {
+		return nil
+	}
+
+	notebook, err := m.findNotebook(notebookID)
+	if err != nil {
+		return err
+	}
+
+	if notebook.AuthorID != authorID {
+		return fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, notebookID)
+	}
+
+	return nil
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) notebookFilterIDs(authorID int, query models.NoteQuery) ([]string, error) {
+	if err := m.checkNotebook(authorID, query.NotebookID); err != nil {
+		return nil, err
+	}
+
+	if !query.IncludeSubnotebooks {
+		return []string{query.NotebookID}, nil
+	}
+
+	return models.NotebookDescendants(m.authorNotebooks(authorID), query.NotebookID), nil
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MemoryService) SetPinned(ctx context.Context, noteID string, pinned bool, version int) (*models.Note, error) {
+	return m.updateNoteState(noteID, version, func(note *models.Note) error {
+		if pinned && note.Archived {
+			return fmt.Errorf("%w: заметка с ID %s", errors.ErrPinArchived, noteID)
+		}
+		note.Pinned = pinned
+		return nil
+	})
+}
+
+func (m *MemoryService) SetArchived(ctx context.Context, noteID string, archived bool, version int) (*models.Note, error) {
+	return m.updateNoteState(noteID, version, func(note *models.Note) error {
+		note.Archived = archived
+		if archived {
+			note.Pinned = false
+		}
+		return nil
+	})
+}
+
+func (m *MemoryService) SetReminder(ctx context.Context, noteID string, request models.ReminderRequest, version int) (*models.Note, error) {
+	reminder := models.Note{RemindAt: request.RemindAt, DueAt: request.DueAt, Recurrence: request.Recurrence}
+	if err := models.ValidateReminder(reminder); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReminder, err)
+	}
+
+	return m.updateNoteState(noteID, version, func(note *models.Note) error {
+		note.RemindAt = reminderTime(request.RemindAt)
+		note.DueAt = reminderTime(request.DueAt)
+		note.Recurrence = request.Recurrence
+		delete(m.claims, note.ID)
+		return nil
+	})
+}
+
+// updateNoteState, как и в Mongo, сохраняет ревизию и увеличивает версию и
+// updated_at.
+func (m *MemoryService) updateNoteState(noteID string, version int, update func(note *models.Note) error) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(noteID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	m.mu.Lock()
+	note, err := m.versionedNote(noteID, version)
+	if err == nil {
+		err = update(&note)
+	}
+	if err == nil {
+		m.saveRevision(m.notes[note.ID])
+		note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		note.Version++
+		m.notes[note.ID] = note
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventUpdated, note)
+
+	return cloneNote(&note), nil
+}
+
+func (m *MemoryService) GetUpcomingReminders(ctx context.Context, authorId int, until time.Time, limit int) ([]models.Reminder, error) {
+	m.mu.RLock()
+	notes := make([]models.Note, 0)
+	for _, note := range m.notes {
+		if note.AuthorID != authorId || !note.DeletedAt.IsZero() || note.RemindAt.IsZero() {
+			continue
+		}
+		if !until.IsZero() && note.RemindAt.After(until) {
+			continue
+		}
+		notes = append(notes, note)
+	}
+	m.mu.RUnlock()
+
+	sortByRemindAt(notes)
+	if limit > 0 && len(notes) > limit {
+		notes = notes[:limit]
+	}
+
+	reminders := make([]models.Reminder, 0, len(notes))
+	for _, note := range notes {
+		reminder := models.ReminderFromNote(note)
+		if next, ok := nextReminder(note, note.RemindAt); ok {
+			reminder.NextRemindAt = next
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+// reminderClaim — пометка о доставке напоминания в памяти.
+type reminderClaim struct {
+	token string
+	until time.Time
+}
+
+// В памяти реплика одна, поэтому аренда не нужна: напоминания помечаются
+// доставляемыми в m.claims под m.mu, а remind_at сдвигает CompleteReminder,
+// если пометка с тем же токеном ещё на месте.
+func (m *MemoryService) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]models.Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := make([]models.Note, 0)
+	for _, note := range m.notes {
+		if !note.DeletedAt.IsZero() || note.RemindAt.IsZero() || note.RemindAt.After(now) {
+			continue
+		}
+		if claim, ok := m.claims[note.ID]; ok && claim.until.After(now) {
+			continue
+		}
+		due = append(due, note)
+	}
+
+	sortByRemindAt(due)
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimToken := primitive.NewObjectID().Hex()
+	reminders := make([]models.Reminder, 0, len(due))
+	for _, note := range due {
+		reminder := models.ReminderFromNote(note)
+		if next, ok := nextReminder(note, now); ok {
+			reminder.NextRemindAt = next
+		}
+		reminder.ClaimToken = claimToken
+		m.claims[note.ID] = reminderClaim{token: claimToken, until: now.Add(reminderClaimTTL)}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+func (m *MemoryService) CompleteReminder(ctx context.Context, reminder models.Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	note, ok := m.notes[reminder.NoteID]
+	if !ok || !note.RemindAt.Equal(reminder.RemindAt) {
+		return nil
+	}
+	if claim, ok := m.claims[note.ID]; !ok || claim.token != reminder.ClaimToken {
+		return nil
+	}
+
+	note.RemindAt = reminder.NextRemindAt
+	if !note.DueAt.IsZero() && !reminder.NextRemindAt.IsZero() {
+		note.DueAt = note.DueAt.Add(reminder.NextRemindAt.Sub(reminder.RemindAt))
+	}
+	m.notes[note.ID] = note
+	delete(m.claims, note.ID)
+
+	return nil
+}
+
+func (m *MemoryService) DeliverToInbox(ctx context.Context, reminder models.Reminder) error {
+	item := models.InboxItem{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    reminder.AuthorID,
+		Reminder:  reminder,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	expired := item.CreatedAt.Add(-inboxTTL)
+	stale := 0
+
+	m.mu.Lock()
+	for stale < len(m.inbox) && m.inbox[stale].CreatedAt.Before(expired) {
+		stale++
+	}
+	m.inbox = append(m.inbox[stale:], item)
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *MemoryService) GetInbox(ctx context.Context, userID int, limit int) ([]models.InboxItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	expired := time.Now().Add(-inboxTTL)
+	items := make([]models.InboxItem, 0)
+	for i := len(m.inbox) - 1; i >= 0; i-- {
+		item := m.inbox[i]
+		if item.UserID != userID || item.CreatedAt.Before(expired) {
+			continue
+		}
+		items = append(items, item)
+		if limit > 0 && len(items) == limit {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+func sortByRemindAt(notes []models.Note) {
+	slices.SortFunc(notes, func(a, b models.Note) int {
+		if result := a.RemindAt.Compare(b.RemindAt); result != 0 {
+			return result
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+func reminderTime(value time.Time) time.Time {
+	if value.IsZero() {
+		return time.Time{}
+	}
+	return value.UTC().Truncate(time.Millisecond)
+}
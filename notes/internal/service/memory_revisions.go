@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+)
+
+func (m *MemoryService) GetRevisions(ctx context.Context, noteID string) ([]models.NoteRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored := m.revisions[noteID]
+	revisions := make([]models.NoteRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revision := stored[i]
+		revision.Content = ""
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+func (m *MemoryService) GetRevision(ctx context.Context, noteID string, version int) (*models.NoteRevision, error) {
+	if version <= 0 {
+		return nil, fmt.Errorf("%w: %d", errors.ErrInvalidRevision, version)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	note, err := m.activeNote(noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if note.Version == version {
+		revision := models.RevisionFromNote(note)
+		return &revision, nil
+	}
+
+	for _, revision := range m.revisions[noteID] {
+		if revision.Version == version {
+			return &revision, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: версия %d заметки %s", errors.ErrRevisionNotFound, version, noteID)
+}
+
+func (m *MemoryService) RestoreRevision(ctx context.Context, noteID string, version int, expectedVersion int) (*models.Note, error) {
+	revision, err := m.GetRevision(ctx, noteID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Update(ctx, models.Note{
+		ID:       noteID,
+		Name:     revision.Name,
+		Content:  revision.Content,
+		Tags:     revision.Tags,
+		AuthorID: revision.AuthorID,
+		Version:  expectedVersion,
+	})
+}
+
+// saveRevision хранит ревизии по возрастанию версии и всегда создаёт новый
+// срез, чтобы снимок атомарного пакета не видел изменений; вызывается под m.mu.
+func (m *MemoryService) saveRevision(note models.Note) {
+	revisions := m.revisions[note.ID]
+	if slices.ContainsFunc(revisions, func(revision models.NoteRevision) bool {
+		return revision.Version == note.Version
+	}) {
+		return
+	}
+
+	revisions = append(slices.Clone(revisions), models.RevisionFromNote(note))
+	if m.revisionsLimit > 0 && len(revisions) > m.revisionsLimit {
+		revisions = revisions[len(revisions)-m.revisionsLimit:]
+	}
+	m.revisions[note.ID] = revisions
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"strings"
+)
+
+// Search повторяет текстовый индекс Mongo без морфологии: заметка подходит,
+// если в ней есть слово, начинающееся с основы терма, совпадения в названии
+// весят в 10 раз больше, а слова с минусом исключают заметку.
+func (m *MemoryService) Search(ctx context.Context, authorId int, query models.SearchQuery) ([]models.SearchResult, error) {
+	query.Normalize()
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: пустой поисковый запрос", errors.ErrInvalidQuery)
+	}
+
+	terms := searchTerms(query.Text)
+	var excluded []string
+	for _, field := range strings.Fields(query.Text) {
+		if word, ok := strings.CutPrefix(field, "-"); ok {
+			excluded = append(excluded, searchTerms(word)...)
+		}
+	}
+
+	m.mu.RLock()
+	results := make([]models.SearchResult, 0)
+	for _, note := range m.notes {
+		if note.AuthorID != authorId || !note.DeletedAt.IsZero() {
+			continue
+		}
+
+		name, content := []rune(note.Name), []rune(note.Content)
+		if len(excluded) > 0 && (len(findMatches(name, excluded)) > 0 || len(findMatches(content, excluded)) > 0) {
+			continue
+		}
+
+		score := 10*len(findMatches(name, terms)) + len(findMatches(content, terms))
+		if score == 0 {
+			continue
+		}
+		results = append(results, models.SearchResult{Note: *cloneNote(&note), Score: float64(score)})
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(results, func(a, b models.SearchResult) int {
+		if order := cmp.Compare(b.Score, a.Score); order != 0 {
+			return order
+		}
+		return strings.Compare(b.Note.ID, a.Note.ID)
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	for i := range results {
+		results[i].Highlights = highlightFields(results[i].Note, terms)
+	}
+
+	return results, nil
+}
+
+func (m *MemoryService) GetTags(ctx context.Context, authorId int) ([]models.TagCount, error) {
+	m.mu.RLock()
+	counts := make(map[string]int)
+	for _, note := range m.notes {
+		if note.AuthorID != authorId || !note.DeletedAt.IsZero() {
+			continue
+		}
+		for _, tag := range note.Tags {
+			counts[tag]++
+		}
+	}
+	m.mu.RUnlock()
+
+	tags := make([]models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, models.TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b models.TagCount) int {
+		if order := cmp.Compare(b.Count, a.Count); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+
+	return tags, nil
+}
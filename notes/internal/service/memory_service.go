@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"notes/internal/events"
+	"notes/internal/models"
+	"notes/internal/webhooks"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryService хранит всё в памяти процесса и повторяет поведение
+// MongoService: те же ID в формате ObjectID, те же ошибки и правила доступа.
+// Кэш ему не нужен, события и очередь webhook тоже работают в памяти.
+//
+// Всё состояние защищено одним mu; события публикуются уже после его
+// освобождения, потому что постановка webhook снова читает состояние.
+type MemoryService struct {
+	mu          sync.RWMutex
+	notes       map[string]models.Note
+	revisions   map[string][]models.NoteRevision
+	links       map[string]models.ShareLink
+	notebooks   map[string]models.Notebook
+	attachments map[string]memoryAttachment
+	importJobs  map[string]models.ImportJob
+	inbox       []models.InboxItem
+	claims      map[string]reminderClaim
+	webhooks    map[string]models.Webhook
+	deliveries  []models.WebhookDelivery
+
+	events         *events.Broker
+	revisionsLimit int
+
+	attachmentMaxSize int64
+	attachmentQuota   int64
+	importSyncLimit   int
+	imports           *importRunner
+
+	webhookQueue        *webhooks.MemoryQueue
+	webhookMaxAttempts  int
+	webhookDisableAfter int
+}
+
+var _ Service = (*MemoryService)(nil)
+
+func NewMemoryService(cfg *config.Config) *MemoryService {
+	return &MemoryService{
+		notes:       make(map[string]models.Note),
+		revisions:   make(map[string][]models.NoteRevision),
+		links:       make(map[string]models.ShareLink),
+		notebooks:   make(map[string]models.Notebook),
+		attachments: make(map[string]memoryAttachment),
+		importJobs:  make(map[string]models.ImportJob),
+		claims:      make(map[string]reminderClaim),
+		webhooks:    make(map[string]models.Webhook),
+
+		events:         events.NewLocalBroker(cfg.EventsReplayLimit),
+		revisionsLimit: cfg.RevisionsLimit,
+
+		attachmentMaxSize: int64(cfg.AttachmentMaxSizeMB) << 20,
+		attachmentQuota:   int64(cfg.AttachmentsUserQuotaMB) << 20,
+		importSyncLimit:   cfg.ImportSyncLimit,
+		imports:           newImportRunner(),
+
+		webhookQueue:        webhooks.NewMemoryQueue(),
+		webhookMaxAttempts:  cfg.WebhookMaxAttempts,
+		webhookDisableAfter: cfg.WebhookDisableAfterFailures,
+	}
+}
+
+func (m *MemoryService) Create(ctx context.Context, note models.Note) (*models.Note, error) {
+	m.mu.Lock()
+	createdNote, err := m.createNote(note)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventCreated, *createdNote)
+
+	return createdNote, nil
+}
+
+func (m *MemoryService) createNote(note models.Note) (*models.Note, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	note.Shares = nil
+	note.DeletedAt = time.Time{}
+	note.CreatedAt = now
+	note.UpdatedAt = now
+
+	return m.insertNote(note)
+}
+
+func (m *MemoryService) insertNote(note models.Note) (*models.Note, error) {
+	note.Version = 1
+
+	if err := models.ValidateReminder(note); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReminder, err)
+	}
+
+	if err := m.checkNotebook(note.AuthorID, note.NotebookID); err != nil {
+		return nil, err
+	}
+
+	if note.Archived {
+		note.Pinned = false
+	}
+
+	note.ID = primitive.NewObjectID().Hex()
+	m.notes[note.ID] = *cloneNote(&note)
+
+	return &note, nil
+}
+
+func (m *MemoryService) GetByID(ctx context.Context, id string) (*models.Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	note, err := m.activeNote(id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneNote(&note), nil
+}
+
+// activeNote возвращает заметку не из корзины; вызывается под m.mu.
+func (m *MemoryService) activeNote(id string) (models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	note, ok := m.notes[objectID.Hex()]
+	if !ok || !note.DeletedAt.IsZero() {
+		return models.Note{}, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
+	}
+	return note, nil
+}
+
+func (m *MemoryService) GetAll(ctx context.Context, authorId int, query models.NoteQuery) (*models.NotesPage, error) {
+	query.Normalize()
+	if !query.Valid() {
+		return nil, fmt.Errorf("%w: сортировка %s %s", errors.ErrInvalidQuery, query.SortBy, query.Order)
+	}
+
+	order := listOrder(query)
+
+	var after *models.Note
+	if query.Cursor != "" {
+		cursorNote, err := cursorPosition(query)
+		if err != nil {
+			return nil, err
+		}
+		after = cursorNote
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var notebookIDs []string
+	if query.NotebookID != "" {
+		ids, err := m.notebookFilterIDs(authorId, query)
+		if err != nil {
+			return nil, err
+		}
+		notebookIDs = ids
+	}
+
+	notes := make([]models.Note, 0, query.Limit+1)
+	for _, note := range m.notes {
+		if note.AuthorID != authorId || !note.DeletedAt.IsZero() || !matchesListQuery(note, query) {
+			continue
+		}
+		if query.NotebookID != "" && !slices.Contains(notebookIDs, note.NotebookID) {
+			continue
+		}
+		if after != nil && order(note, *after) <= 0 {
+			continue
+		}
+		notes = append(notes, *cloneNote(&note))
+	}
+
+	slices.SortFunc(notes, order)
+	if len(notes) > query.Limit+1 {
+		notes = notes[:query.Limit+1]
+	}
+
+	page := &models.NotesPage{Notes: notes}
+	if len(notes) > query.Limit {
+		page.Notes = notes[:query.Limit]
+		page.NextCursor = encodeCursor(cursorForNote(page.Notes[query.Limit-1], query.SortBy))
+	}
+
+	return page, nil
+}
+
+func (m *MemoryService) Update(ctx context.Context, note models.Note) (*models.Note, error) {
+	m.mu.Lock()
+	updatedNote, err := m.updateNote(note)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventUpdated, *updatedNote)
+
+	return updatedNote, nil
+}
+
+func (m *MemoryService) updateNote(note models.Note) (*models.Note, error) {
+	existingNote, err := m.versionedNote(note.ID, note.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	m.saveRevision(existingNote)
+
+	updatedNote := existingNote
+	updatedNote.Name = note.Name
+	updatedNote.Content = note.Content
+	updatedNote.Tags = slices.Clone(note.Tags)
+	updatedNote.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	updatedNote.Version = existingNote.Version + 1
+	m.notes[updatedNote.ID] = updatedNote
+
+	return cloneNote(&updatedNote), nil
+}
+
+func (m *MemoryService) Delete(ctx context.Context, id string, version int) error {
+	m.mu.Lock()
+	deletedNote, err := m.deleteNote(id, version)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	m.publishNoteEvent(models.NoteEventDeleted, *deletedNote)
+
+	return nil
+}
+
+func (m *MemoryService) deleteNote(id string, version int) (*models.Note, error) {
+	existingNote, err := m.versionedNote(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedNote := existingNote
+	deletedNote.DeletedAt = time.Now().UTC().Truncate(time.Millisecond)
+	m.notes[deletedNote.ID] = deletedNote
+
+	return cloneNote(&existingNote), nil
+}
+
+// versionedNote проверяет версию так же, как условие записи в Mongo:
+// version <= 0 означает запись без проверки.
+func (m *MemoryService) versionedNote(id string, version int) (models.Note, error) {
+	note, err := m.activeNote(id)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	if version > 0 && note.Version != version {
+		return models.Note{}, fmt.Errorf("%w: заметка с ID %s была изменена", errors.ErrVersionConflict, id)
+	}
+	return note, nil
+}
+
+func (m *MemoryService) ExportNotes(ctx context.Context, authorId int, write func(models.Note) error) error {
+	m.mu.RLock()
+	notes := m.authorNotes(authorId)
+	m.mu.RUnlock()
+
+	for _, note := range notes {
+		if err := write(note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorNotes возвращает копии заметок автора вне корзины в порядке
+// создания; вызывается под m.mu.
+func (m *MemoryService) authorNotes(authorID int) []models.Note {
+	notes := make([]models.Note, 0)
+	for _, note := range m.notes {
+		if note.AuthorID == authorID && note.DeletedAt.IsZero() {
+			notes = append(notes, *cloneNote(&note))
+		}
+	}
+	slices.SortFunc(notes, func(a, b models.Note) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return notes
+}
+
+func (m *MemoryService) Close() error {
+	return m.events.Close()
+}
+
+func matchesListQuery(note models.Note, query models.NoteQuery) bool {
+	if note.Archived != query.Archived {
+		return false
+	}
+
+	if query.Name != "" && !strings.Contains(strings.ToLower(note.Name), strings.ToLower(query.Name)) {
+		return false
+	}
+
+	if len(query.Tags) > 0 {
+		matched := 0
+		for _, tag := range query.Tags {
+			if slices.Contains(note.Tags, tag) {
+				matched++
+			}
+		}
+		if query.TagMode == models.TagModeAny && matched == 0 {
+			return false
+		}
+		if query.TagMode != models.TagModeAny && matched < len(query.Tags) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listOrder повторяет listSort: закреплённые первыми, затем поле сортировки
+// и ID в заданном направлении.
+func listOrder(query models.NoteQuery) func(a, b models.Note) int {
+	return func(a, b models.Note) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+
+		var result int
+		switch query.SortBy {
+		case models.SortByName:
+			result = strings.Compare(a.Name, b.Name)
+		case models.SortByUpdated:
+			result = a.UpdatedAt.Compare(b.UpdatedAt)
+		default:
+			result = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if result == 0 {
+			result = strings.Compare(a.ID, b.ID)
+		}
+
+		if query.Order == models.SortOrderDesc {
+			return -result
+		}
+		return result
+	}
+}
+
+// cursorPosition превращает курсор в заметку-ориентир: страница продолжается
+// заметками, которые идут после неё в порядке listOrder.
+func cursorPosition(query models.NoteQuery) (*models.Note, error) {
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := primitive.ObjectIDFromHex(cursor.ID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+	}
+
+	note := &models.Note{ID: cursor.ID, Pinned: cursor.Pinned}
+	if query.SortBy == models.SortByName {
+		note.Name = cursor.Value
+		return note, nil
+	}
+
+	value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+	}
+	note.CreatedAt = value
+	note.UpdatedAt = value
+
+	return note, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewServiceMemoryBackend(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.StorageBackend = BackendMemory
+
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	defer service.Close()
+
+	if _, ok := service.(*MemoryService); !ok {
+		t.Fatalf("STORAGE_BACKEND=memory создал %T", service)
+	}
+}
+
+func TestMemoryCreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	created, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "План", Tags: []string{"дом"}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := primitive.ObjectIDFromHex(created.ID); err != nil {
+		t.Fatalf("ID %q не в формате ObjectID: %v", created.ID, err)
+	}
+	if created.Version != 1 || created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("созданная заметка %+v", created)
+	}
+
+	created.Tags[0] = "изменено"
+	note, err := service.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if note.Name != "План" || note.Tags[0] != "дом" {
+		t.Fatalf("хранилище отдало изменённую снаружи заметку %+v", note)
+	}
+}
+
+func TestMemoryNotFound(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	deleted, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "В корзину"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := service.Delete(ctx, deleted.ID, deleted.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	missingID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"GetByID несуществующей", func() error { _, err := service.GetByID(ctx, missingID); return err }, errors.ErrNoteNotFound},
+		{"GetByID удалённой", func() error { _, err := service.GetByID(ctx, deleted.ID); return err }, errors.ErrNoteNotFound},
+		{"GetByID с неверным ID", func() error { _, err := service.GetByID(ctx, "не-id"); return err }, errors.ErrInvalidNoteID},
+		{"Update несуществующей", func() error {
+			_, err := service.Update(ctx, models.Note{ID: missingID, AuthorID: 1, Name: "x"})
+			return err
+		}, errors.ErrNoteNotFound},
+		{"Delete несуществующей", func() error { return service.Delete(ctx, missingID, 0) }, errors.ErrNoteNotFound},
+		{"Delete удалённой", func() error { return service.Delete(ctx, deleted.ID, 0) }, errors.ErrNoteNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.call(); !stdErrors.Is(err, test.want) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestMemoryUpdateVersion(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	note, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "План"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	updated, err := service.Update(ctx, models.Note{ID: note.ID, AuthorID: 1, Name: "План на неделю", Version: note.Version})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "План на неделю" || updated.Version != 2 || updated.CreatedAt != note.CreatedAt {
+		t.Fatalf("обновлённая заметка %+v", updated)
+	}
+
+	if _, err := service.Update(ctx, models.Note{ID: note.ID, AuthorID: 1, Name: "Опоздал", Version: note.Version}); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("Update со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+	if err := service.Delete(ctx, note.ID, note.Version); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("Delete со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+}
+
+func TestMemoryAuthorFiltering(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	for _, note := range []models.Note{
+		{AuthorID: 1, Name: "Первая"},
+		{AuthorID: 1, Name: "Вторая"},
+		{AuthorID: 2, Name: "Чужая"},
+	} {
+		if _, err := service.Create(ctx, note); err != nil {
+			t.Fatalf("Create(%q): %v", note.Name, err)
+		}
+	}
+
+	page, err := service.GetAll(ctx, 1, models.NoteQuery{})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(page.Notes) != 2 {
+		t.Fatalf("автор 1 видит %d заметок, ожидалось 2", len(page.Notes))
+	}
+	for _, note := range page.Notes {
+		if note.AuthorID != 1 {
+			t.Fatalf("в списке автора 1 заметка автора %d", note.AuthorID)
+		}
+	}
+
+	exported := 0
+	err = service.ExportNotes(ctx, 2, func(note models.Note) error {
+		if note.AuthorID != 2 {
+			t.Fatalf("в экспорте автора 2 заметка автора %d", note.AuthorID)
+		}
+		exported++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportNotes: %v", err)
+	}
+	if exported != 1 {
+		t.Fatalf("экспортировано %d заметок, ожидалась 1", exported)
+	}
+}
+
+func TestMemoryConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	note, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "Общая"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "Параллельная"}); err != nil {
+				t.Errorf("Create: %v", err)
+			}
+			if _, err := service.Update(ctx, models.Note{ID: note.ID, AuthorID: 1, Name: "Общая"}); err != nil {
+				t.Errorf("Update: %v", err)
+			}
+			if _, err := service.GetAll(ctx, 1, models.NoteQuery{}); err != nil {
+				t.Errorf("GetAll: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	updated, err := service.GetByID(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if updated.Version != workers+1 {
+		t.Fatalf("версия после %d обновлений %d, ожидалась %d", workers, updated.Version, workers+1)
+	}
+
+	count := 0
+	service.ExportNotes(ctx, 1, func(models.Note) error {
+		count++
+		return nil
+	})
+	if count != workers+1 {
+		t.Fatalf("у автора %d заметок, ожидалось %d", count, workers+1)
+	}
+}
+
+func TestMemoryImportInterruption(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	cfg.ImportSyncLimit = 1
+	service := NewMemoryService(cfg)
+
+	items := []models.ImportItem{
+		{Source: "a.md", Note: models.Note{Name: "Первая"}},
+		{Source: "b.md", Note: models.Note{Name: "Вторая"}},
+	}
+
+	staleJob := models.ImportJob{ID: primitive.NewObjectID().Hex(), AuthorID: 1, Status: models.ImportStatusRunning, Total: 2, CreatedAt: time.Now()}
+	service.saveImportJob(staleJob)
+	failed, err := service.FailStaleImports(ctx, time.Now().Add(time.Minute))
+	if err != nil || failed != 1 {
+		t.Fatalf("FailStaleImports: %d, %v, ожидалась одна задача", failed, err)
+	}
+	stored, err := service.GetImportJob(ctx, staleJob.ID)
+	if err != nil {
+		t.Fatalf("GetImportJob: %v", err)
+	}
+	if stored.Status != models.ImportStatusFailed || !strings.Contains(stored.Error, errors.ErrImportInterrupted.Error()) {
+		t.Fatalf("зависшая задача после проверки %+v", stored)
+	}
+
+	service.StopImports()
+	job, err := service.ImportNotes(ctx, 1, models.ImportRequest{Format: models.ImportFormatZip, Items: items})
+	if err != nil {
+		t.Fatalf("ImportNotes: %v", err)
+	}
+	if job.Status != models.ImportStatusFailed || job.Created != 0 {
+		t.Fatalf("фоновый импорт после остановки %+v", job)
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MemoryService) ShareNote(ctx context.Context, noteID string, share models.NoteShare) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(noteID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	if !share.Valid() {
+		return nil, fmt.Errorf("%w: пользователь %d, роль %q", errors.ErrInvalidShare, share.UserID, share.Role)
+	}
+
+	m.mu.Lock()
+	note, err := m.activeNote(noteID)
+	if err == nil {
+		shares := slices.Clone(note.Shares)
+		index := slices.IndexFunc(shares, func(existing models.NoteShare) bool {
+			return existing.UserID == share.UserID
+		})
+		if index >= 0 {
+			shares[index].Role = share.Role
+		} else {
+			shares = append(shares, share)
+		}
+		m.saveRevision(note)
+		note.Shares = shares
+		note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		note.Version++
+		m.notes[note.ID] = note
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventUpdated, note)
+
+	return cloneNote(&note), nil
+}
+
+func (m *MemoryService) UnshareNote(ctx context.Context, noteID string, userID int) (*models.Note, error) {
+	m.mu.Lock()
+	note, err := m.activeNote(noteID)
+	if err == nil {
+		m.saveRevision(note)
+		note.Shares = slices.DeleteFunc(slices.Clone(note.Shares), func(share models.NoteShare) bool {
+			return share.UserID == userID
+		})
+		note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		note.Version++
+		m.notes[note.ID] = note
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventUpdated, note)
+	m.publishNoteEventTo(models.NoteEventDeleted, note, []int{userID})
+
+	return cloneNote(&note), nil
+}
+
+func (m *MemoryService) GetShared(ctx context.Context, userID int) ([]models.Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notes := make([]models.Note, 0)
+	for _, note := range m.notes {
+		if note.DeletedAt.IsZero() && slices.Contains(note.SharedUserIDs(), userID) {
+			notes = append(notes, *cloneNote(&note))
+		}
+	}
+	slices.SortFunc(notes, func(a, b models.Note) int {
+		if result := b.UpdatedAt.Compare(a.UpdatedAt); result != 0 {
+			return result
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+
+	return notes, nil
+}
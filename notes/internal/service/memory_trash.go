@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MemoryService) GetTrash(ctx context.Context, authorId int) ([]models.Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notes := make([]models.Note, 0)
+	for _, note := range m.notes {
+		if note.AuthorID == authorId && !note.DeletedAt.IsZero() {
+			notes = append(notes, *cloneNote(&note))
+		}
+	}
+	slices.SortFunc(notes, func(a, b models.Note) int {
+		if result := b.DeletedAt.Compare(a.DeletedAt); result != 0 {
+			return result
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+
+	return notes, nil
+}
+
+func (m *MemoryService) GetTrashedByID(ctx context.Context, id string) (*models.Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	note, err := m.trashedNote(id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneNote(&note), nil
+}
+
+func (m *MemoryService) RestoreFromTrash(ctx context.Context, id string) (*models.Note, error) {
+	m.mu.Lock()
+	note, err := m.trashedNote(id)
+	if err == nil {
+		m.saveRevision(note)
+		note.DeletedAt = time.Time{}
+		note.Version++
+		m.notes[note.ID] = note
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.publishNoteEvent(models.NoteEventCreated, note)
+
+	return cloneNote(&note), nil
+}
+
+func (m *MemoryService) DeletePermanently(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	note, err := m.trashedNote(id)
+	if err != nil {
+		return err
+	}
+
+	m.purgeNote(note.ID)
+	return nil
+}
+
+func (m *MemoryService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged int64
+	for id, note := range m.notes {
+		if !note.DeletedAt.IsZero() && !note.DeletedAt.After(deletedBefore) {
+			m.purgeNote(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeNote удаляет заметку вместе с ревизиями, ссылками и вложениями;
+// вызывается под m.mu.
+func (m *MemoryService) purgeNote(id string) {
+	delete(m.notes, id)
+	delete(m.revisions, id)
+	delete(m.claims, id)
+
+	for token, link := range m.links {
+		if link.NoteID == id {
+			delete(m.links, token)
+		}
+	}
+
+	for attachmentID, attachment := range m.attachments {
+		if attachment.NoteID == id {
+			delete(m.attachments, attachmentID)
+		}
+	}
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) trashedNote(id string) (models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	note, ok := m.notes[objectID.Hex()]
+	if !ok || note.DeletedAt.IsZero() {
+		return models.Note{}, fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+	}
+	return note, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"notes/internal/webhooks"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *MemoryService) CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebhookCreation, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.authorWebhooks(webhook.AuthorID)) >= models.MaxWebhooksPerUser {
+		return nil, fmt.Errorf("%w: не больше %d на пользователя", errors.ErrWebhookLimit, models.MaxWebhooksPerUser)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.Events = slices.Clone(webhook.Events)
+	webhook.Secret = secret
+	webhook.Active = true
+	webhook.ConsecutiveFailures = 0
+	webhook.LastDeliveryAt = time.Time{}
+	webhook.DisabledAt = time.Time{}
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+	m.webhooks[webhook.ID] = webhook
+
+	return &webhook, nil
+}
+
+func (m *MemoryService) GetWebhooks(ctx context.Context, authorId int) ([]models.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := m.authorWebhooks(authorId)
+	for i := range result {
+		result[i] = result[i].Redacted()
+	}
+	return result, nil
+}
+
+func (m *MemoryService) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	webhook, err := m.findWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook = webhook.Redacted()
+	return &webhook, nil
+}
+
+// Как и в Mongo, включение webhook обнуляет счётчик ошибок.
+func (m *MemoryService) UpdateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated, err := m.findWebhook(webhook.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated.URL = webhook.URL
+	updated.Active = webhook.Active
+	updated.Events = slices.Clone(webhook.Events)
+	updated.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if webhook.Active {
+		updated.ConsecutiveFailures = 0
+		updated.DisabledAt = time.Time{}
+	}
+	m.webhooks[updated.ID] = updated
+
+	updated = updated.Redacted()
+	return &updated, nil
+}
+
+func (m *MemoryService) DeleteWebhook(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhook, err := m.findWebhook(id)
+	if err != nil {
+		return err
+	}
+
+	delete(m.webhooks, webhook.ID)
+	m.deliveries = slices.DeleteFunc(m.deliveries, func(delivery models.WebhookDelivery) bool {
+		return delivery.WebhookID == webhook.ID
+	})
+	return nil
+}
+
+func (m *MemoryService) GetWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	expired := time.Now().Add(-webhookDeliveriesTTL)
+	result := make([]models.WebhookDelivery, 0)
+	for i := len(m.deliveries) - 1; i >= 0; i-- {
+		delivery := m.deliveries[i]
+		if delivery.WebhookID != webhookID || delivery.CreatedAt.Before(expired) {
+			continue
+		}
+		result = append(result, delivery)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (m *MemoryService) ClaimWebhookJobs(ctx context.Context, now time.Time, limit int) ([]models.WebhookJob, error) {
+	jobs, err := m.webhookQueue.Claim(now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrCacheGet, err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	claimed := make([]models.WebhookJob, 0, len(jobs))
+	for _, job := range jobs {
+		webhook, ok := m.webhooks[job.WebhookID]
+		if !ok || !webhook.Active {
+			if err := m.webhookQueue.Ack(job.ID); err != nil {
+				fmt.Printf("Ошибка удаления доставки webhook: %v\n", err)
+			}
+			continue
+		}
+		job.Webhook = webhook
+		claimed = append(claimed, job)
+	}
+
+	return claimed, nil
+}
+
+func (m *MemoryService) CompleteWebhookJob(ctx context.Context, job models.WebhookJob, delivery models.WebhookDelivery) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	retry := !delivery.Success && job.Attempt < m.webhookMaxAttempts
+
+	m.mu.Lock()
+	webhook, ok := m.webhooks[job.WebhookID]
+	if !ok {
+		retry = false
+	} else if delivery.Success {
+		webhook.ConsecutiveFailures = 0
+		webhook.LastDeliveryAt = now
+	} else {
+		webhook.ConsecutiveFailures++
+		webhook.LastDeliveryAt = now
+		if m.webhookDisableAfter > 0 && webhook.ConsecutiveFailures >= m.webhookDisableAfter {
+			retry = false
+			if webhook.Active {
+				webhook.Active = false
+				webhook.DisabledAt = now
+				webhook.UpdatedAt = now
+				fmt.Printf("Webhook %s отключён после %d ошибок доставки подряд\n", webhook.ID, m.webhookDisableAfter)
+			}
+		}
+	}
+	if ok {
+		m.webhooks[webhook.ID] = webhook
+	}
+
+	if retry {
+		delivery.NextRetry = now.Add(webhooks.Backoff(job.Attempt))
+	}
+	// Журнал пополняется по времени, поэтому устаревшие записи лежат в начале.
+	expired := now.Add(-webhookDeliveriesTTL)
+	stale := 0
+	for stale < len(m.deliveries) && m.deliveries[stale].CreatedAt.Before(expired) {
+		stale++
+	}
+	delivery.ID = primitive.NewObjectID().Hex()
+	m.deliveries = append(m.deliveries[stale:], delivery)
+	m.mu.Unlock()
+
+	if !retry {
+		if err := m.webhookQueue.Ack(job.ID); err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrCacheSet, err)
+		}
+		return nil
+	}
+
+	job.Attempt++
+	job.Webhook = models.Webhook{}
+	if err := m.webhookQueue.Retry(job, delivery.NextRetry); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrCacheSet, err)
+	}
+	return nil
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) findWebhook(id string) (models.Webhook, error) {
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return models.Webhook{}, fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, id)
+	}
+	return webhook, nil
+}
+
+// Вызывается под m.mu.
+func (m *MemoryService) authorWebhooks(authorID int) []models.Webhook {
+	result := make([]models.Webhook, 0)
+	for _, webhook := range m.webhooks {
+		if webhook.AuthorID == authorID {
+			result = append(result, webhook)
+		}
+	}
+	slices.SortFunc(result, func(a, b models.Webhook) int {
+		if order := a.CreatedAt.Compare(b.CreatedAt); order != 0 {
+			return order
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
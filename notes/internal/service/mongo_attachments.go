@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type attachmentMetadata struct {
+	NoteID      string `bson:"note_id"`
+	AuthorID    int    `bson:"author_id"`
+	ContentType string `bson:"content_type"`
+}
+
+type attachmentFile struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Length     int64              `bson:"length"`
+	UploadDate time.Time          `bson:"uploadDate"`
+	Filename   string             `bson:"filename"`
+	Metadata   attachmentMetadata `bson:"metadata"`
+}
+
+func (f attachmentFile) toAttachment() models.Attachment {
+	return models.Attachment{
+		ID:          f.ID.Hex(),
+		NoteID:      f.Metadata.NoteID,
+		AuthorID:    f.Metadata.AuthorID,
+		Filename:    f.Filename,
+		ContentType: f.Metadata.ContentType,
+		Size:        f.Length,
+		UploadedAt:  f.UploadDate,
+	}
+}
+
+func attachmentIndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "metadata.note_id", Value: 1}}},
+		{Keys: bson.D{{Key: "metadata.author_id", Value: 1}}},
+	}
+}
+
+func (m *MongoService) UploadAttachment(ctx context.Context, note models.Note, filename string, contentType string, content io.Reader) (*models.Attachment, error) {
+	used, err := m.attachmentsUsage(ctx, note.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := m.attachmentMaxSize
+	limitErr := errors.ErrAttachmentTooLarge
+	if remaining := m.attachmentQuota - used; remaining < limit {
+		limit = remaining
+		limitErr = errors.ErrAttachmentQuota
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: использовано %d из %d байт", errors.ErrAttachmentQuota, used, m.attachmentQuota)
+	}
+
+	metadata := attachmentMetadata{
+		NoteID:      note.ID,
+		AuthorID:    note.AuthorID,
+		ContentType: contentType,
+	}
+
+	uploadStream, err := m.attachments.OpenUploadStream(filename, options.GridFSUpload().SetMetadata(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	written, err := io.Copy(uploadStream, io.LimitReader(content, limit+1))
+	if err != nil {
+		uploadStream.Abort()
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	if written > limit {
+		uploadStream.Abort()
+		return nil, fmt.Errorf("%w: допустимо не больше %d байт", limitErr, limit)
+	}
+
+	if err := uploadStream.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	// Квота проверялась до загрузки, и параллельные загрузки могли вместе её
+	// превысить. Файл виден в подсчёте только после Close, поэтому каждая
+	// загрузка пересчитывает объём уже со своим файлом и при превышении
+	// удаляет его: одновременные загрузки могут отклонить обе, но квота не
+	// превышается.
+	fileID, _ := uploadStream.FileID.(primitive.ObjectID)
+	total, err := m.attachmentsUsage(ctx, note.AuthorID)
+	if err != nil || total > m.attachmentQuota {
+		if deleteErr := m.attachments.DeleteContext(ctx, fileID); deleteErr != nil {
+			fmt.Printf("Ошибка удаления вложения %s сверх квоты: %v\n", fileID.Hex(), deleteErr)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: использовано %d из %d байт", errors.ErrAttachmentQuota, total-written, m.attachmentQuota)
+	}
+	return &models.Attachment{
+		ID:          fileID.Hex(),
+		NoteID:      note.ID,
+		AuthorID:    note.AuthorID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        written,
+		UploadedAt:  time.Now().UTC().Truncate(time.Millisecond),
+	}, nil
+}
+
+func (m *MongoService) GetAttachments(ctx context.Context, noteID string) ([]models.Attachment, error) {
+	findOptions := options.GridFSFind().SetSort(bson.D{{Key: "uploadDate", Value: 1}})
+
+	cursor, err := m.attachments.FindContext(ctx, bson.M{"metadata.note_id": noteID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	attachments := make([]models.Attachment, 0)
+	for cursor.Next(ctx) {
+		var file attachmentFile
+		if err := cursor.Decode(&file); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		attachments = append(attachments, file.toAttachment())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return attachments, nil
+}
+
+func (m *MongoService) OpenAttachment(ctx context.Context, noteID string, attachmentID string) (*models.Attachment, io.ReadSeekCloser, error) {
+	file, err := m.findAttachment(ctx, noteID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment := file.toAttachment()
+	return &attachment, &gridfsReader{bucket: m.attachments, fileID: file.ID, size: file.Length}, nil
+}
+
+func (m *MongoService) DeleteAttachment(ctx context.Context, noteID string, attachmentID string) error {
+	file, err := m.findAttachment(ctx, noteID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.attachments.DeleteContext(ctx, file.ID); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return nil
+}
+
+func (m *MongoService) deleteAttachments(ctx context.Context, noteID string) error {
+	attachments, err := m.GetAttachments(ctx, noteID)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if err := m.DeleteAttachment(ctx, noteID, attachment.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MongoService) findAttachment(ctx context.Context, noteID string, attachmentID string) (*attachmentFile, error) {
+	fileID, err := primitive.ObjectIDFromHex(attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentNotFound, err)
+	}
+
+	var file attachmentFile
+	filter := bson.M{"_id": fileID, "metadata.note_id": noteID}
+	err = m.attachments.GetFilesCollection().FindOne(ctx, filter).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: %s", errors.ErrAttachmentNotFound, attachmentID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &file, nil
+}
+
+func (m *MongoService) attachmentsUsage(ctx context.Context, authorID int) (int64, error) {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"metadata.author_id": authorID}},
+		bson.M{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$length"}}},
+	}
+
+	cursor, err := m.attachments.GetFilesCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	var usage []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cursor.All(ctx, &usage); err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if len(usage) == 0 {
+		return 0, nil
+	}
+	return usage[0].Total, nil
+}
+
+type gridfsReader struct {
+	bucket *gridfs.Bucket
+	fileID primitive.ObjectID
+	size   int64
+	offset int64
+	stream *gridfs.DownloadStream
+}
+
+func (r *gridfsReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.stream == nil {
+		stream, err := r.bucket.OpenDownloadStream(r.fileID)
+		if err != nil {
+			return 0, err
+		}
+		if r.offset > 0 {
+			if _, err := stream.Skip(r.offset); err != nil {
+				stream.Close()
+				return 0, err
+			}
+		}
+		r.stream = stream
+	}
+
+	n, err := r.stream.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *gridfsReader) Seek(offset int64, whence int) (int64, error) {
+	target := offset
+	switch whence {
+	case io.SeekCurrent:
+		target += r.offset
+	case io.SeekEnd:
+		target += r.size
+	}
+
+	if target < 0 {
+		return 0, fmt.Errorf("%w: отрицательная позиция %d", errors.ErrInvalidData, target)
+	}
+
+	if target != r.offset && r.stream != nil {
+		r.stream.Close()
+		r.stream = nil
+	}
+	r.offset = target
+
+	return target, nil
+}
+
+func (r *gridfsReader) Close() error {
+	if r.stream == nil {
+		return nil
+	}
+	err := r.stream.Close()
+	r.stream = nil
+	return err
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type noteChange struct {
+	eventType string
+	note      models.Note
+}
+
+func (m *MongoService) Batch(ctx context.Context, authorId int, request models.BatchRequest) ([]models.BatchResult, error) {
+	if len(request.Operations) == 0 || len(request.Operations) > models.MaxBatchOperations {
+		return nil, fmt.Errorf("%w: операций %d, допустимо от 1 до %d", errors.ErrInvalidBatch, len(request.Operations), models.MaxBatchOperations)
+	}
+
+	if !request.Atomic {
+		results, changes := m.runBatch(ctx, authorId, request.Operations, false)
+		m.applyNoteChanges(changes)
+		return results, nil
+	}
+
+	if err := m.checkTransactions(ctx); err != nil {
+		return nil, err
+	}
+
+	session, err := m.db.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer session.EndSession(ctx)
+
+	var results []models.BatchResult
+	var changes []noteChange
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		results, changes = m.runBatch(sessionCtx, authorId, request.Operations, true)
+		for _, result := range results {
+			if result.Err != nil {
+				return nil, fmt.Errorf("%w: операция %d: %v", errors.ErrBatchAborted, result.Index, result.Err)
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		if results != nil && stdErrors.Is(err, errors.ErrBatchAborted) {
+			return results, err
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	m.applyNoteChanges(changes)
+
+	return results, nil
+}
+
+// checkTransactions проверяет, что сервер — член replica set или mongos:
+// одиночный mongod отклоняет транзакцию только на первой операции пакета.
+func (m *MongoService) checkTransactions(ctx context.Context) error {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := m.db.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return errors.ErrTransactionsUnsupported
+	}
+	return nil
+}
+
+func (m *MongoService) runBatch(ctx context.Context, authorID int, operations []models.BatchOperation, stopOnError bool) ([]models.BatchResult, []noteChange) {
+	results := make([]models.BatchResult, 0, len(operations))
+	changes := make([]noteChange, 0, len(operations))
+	for i, operation := range operations {
+		result := models.BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+
+		note, err := m.runBatchOperation(ctx, authorID, operation)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.ID = note.ID
+			if operation.Op != models.BatchOpDelete {
+				result.Note = note
+			}
+			changes = append(changes, noteChange{eventType: batchEventType(operation.Op), note: *note})
+		}
+
+		results = append(results, result)
+		if err != nil && stopOnError {
+			break
+		}
+	}
+	return results, changes
+}
+
+func (m *MongoService) runBatchOperation(ctx context.Context, authorID int, operation models.BatchOperation) (*models.Note, error) {
+	if !operation.Valid() {
+		return nil, fmt.Errorf("%w: op=%q, id=%q", errors.ErrInvalidBatch, operation.Op, operation.ID)
+	}
+
+	if operation.Op == models.BatchOpCreate {
+		note := operation.Note
+		note.AuthorID = authorID
+
+		tags, err := models.NormalizeTags(note.Tags)
+		if err != nil {
+			return nil, err
+		}
+		note.Tags = tags
+
+		return m.createNote(ctx, note)
+	}
+
+	existingNote, err := m.GetByID(ctx, operation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if operation.Op == models.BatchOpDelete {
+		if !existingNote.IsOwner(authorID) {
+			return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrNoteForbidden, operation.ID)
+		}
+		return m.deleteNote(ctx, operation.ID, operation.Version)
+	}
+
+	if !existingNote.CanWrite(authorID) {
+		return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrNoteForbidden, operation.ID)
+	}
+
+	note := operation.Note
+	note.ID = operation.ID
+	note.AuthorID = existingNote.AuthorID
+	note.Version = operation.Version
+
+	tags, err := models.NormalizeTags(note.Tags)
+	if err != nil {
+		return nil, err
+	}
+	note.Tags = tags
+
+	return m.updateNote(ctx, note)
+}
+
+// Кэш каждого затронутого пользователя сбрасывается один раз на весь пакет,
+// а события публикуются уже после успешной записи.
+func (m *MongoService) applyNoteChanges(changes []noteChange) {
+	affected := make(map[int]struct{})
+	noteIDs := make([]string, 0, len(changes))
+	for _, change := range changes {
+		noteIDs = append(noteIDs, change.note.ID)
+		affected[change.note.AuthorID] = struct{}{}
+		for _, userID := range change.note.SharedUserIDs() {
+			affected[userID] = struct{}{}
+		}
+	}
+
+	m.invalidateNotes(noteIDs...)
+	for userID := range affected {
+		m.invalidateAuthorCache(userID)
+	}
+
+	for _, change := range changes {
+		m.publishNoteEvent(change.eventType, change.note)
+	}
+}
+
+func batchEventType(op string) string {
+	switch op {
+	case models.BatchOpCreate:
+		return models.NoteEventCreated
+	case models.BatchOpDelete:
+		return models.NoteEventDeleted
+	default:
+		return models.NoteEventUpdated
+	}
+}
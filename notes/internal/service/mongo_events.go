@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/events"
+	"notes/internal/models"
+	"time"
+)
+
+func (m *MongoService) SubscribeEvents(userID int) *events.Subscription {
+	return m.events.Subscribe(userID)
+}
+
+func (m *MongoService) ReplayEvents(ctx context.Context, userID int, afterID int64) ([]models.NoteEvent, error) {
+	return m.events.Replay(userID, afterID)
+}
+
+func (m *MongoService) publishNoteEvent(eventType string, note models.Note) {
+	audience := append([]int{note.AuthorID}, note.SharedUserIDs()...)
+	m.publishNoteEventTo(eventType, note, audience)
+}
+
+func (m *MongoService) publishNoteEventTo(eventType string, note models.Note, audience []int) {
+	event := models.NoteEvent{
+		Type:       eventType,
+		NoteID:     note.ID,
+		AuthorID:   note.AuthorID,
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if eventType != models.NoteEventDeleted {
+		event.Note = &note
+	}
+
+	if m.events != nil {
+		published, err := m.events.Publish(event, audience)
+		if err != nil {
+			fmt.Printf("Ошибка публикации события %s для заметки %s: %v\n", eventType, note.ID, err)
+		}
+		event = published
+	}
+
+	m.enqueueWebhooks(event, audience)
+}
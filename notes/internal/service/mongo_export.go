@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const exportBatchSize = 100
+
+func (m *MongoService) ExportNotes(ctx context.Context, authorId int, write func(models.Note) error) error {
+	filter := bson.M{"author_id": authorId, "deleted_at": nil}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize)
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc noteDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		if err := write(doc.toNote()); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	importJobTTL       = 7 * 24 * time.Hour
+	importProgressStep = 100
+	importJobTimeout   = time.Hour
+)
+
+type importJobDocument struct {
+	ObjectID         primitive.ObjectID `bson:"_id"`
+	models.ImportJob `bson:",inline"`
+}
+
+func importJobIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(importJobTTL.Seconds())),
+	}
+}
+
+func (m *MongoService) ImportNotes(ctx context.Context, authorId int, request models.ImportRequest) (*models.ImportJob, error) {
+	if len(request.Items) == 0 || len(request.Items) > models.MaxImportItems {
+		return nil, fmt.Errorf("%w: заметок %d, допустимо от 1 до %d", errors.ErrInvalidImport, len(request.Items), models.MaxImportItems)
+	}
+
+	objectID := primitive.NewObjectID()
+	job := &models.ImportJob{
+		ID:        objectID.Hex(),
+		AuthorID:  authorId,
+		Format:    request.Format,
+		DryRun:    request.DryRun,
+		Status:    models.ImportStatusPending,
+		Total:     len(request.Items),
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if len(request.Items) <= m.importSyncLimit {
+		m.runImport(ctx, job, request.Items, false)
+		if err := m.saveImportJob(ctx, *job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	if err := m.saveImportJob(ctx, *job); err != nil {
+		return nil, err
+	}
+
+	background := *job
+	started := m.imports.Go(func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, importJobTimeout)
+		defer cancel()
+
+		m.runImport(ctx, &background, request.Items, true)
+
+		saveCtx, cancelSave := saveContext(ctx)
+		defer cancelSave()
+		if err := m.saveImportJob(saveCtx, background); err != nil {
+			fmt.Printf("Ошибка сохранения задачи импорта %s: %v\n", background.ID, err)
+		}
+	})
+	if !started {
+		failImport(job, errors.ErrImportInterrupted)
+		if err := m.saveImportJob(ctx, *job); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+// StopImports прерывает фоновые импорты и ждёт, пока они сохранят итог.
+func (m *MongoService) StopImports() {
+	m.imports.Stop()
+}
+
+// FailStaleImports помечает неудачными задачи, которые не обновлялись с
+// staleBefore: их выполнение оборвал перезапуск или падение сервера.
+func (m *MongoService) FailStaleImports(ctx context.Context, staleBefore time.Time) (int64, error) {
+	filter := bson.M{
+		"status": bson.M{"$in": []string{models.ImportStatusPending, models.ImportStatusRunning}},
+		"$or": []bson.M{
+			{"updated_at": bson.M{"$lt": staleBefore}},
+			{"updated_at": bson.M{"$exists": false}, "created_at": bson.M{"$lt": staleBefore}},
+		},
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	update := bson.M{"$set": bson.M{
+		"status":      models.ImportStatusFailed,
+		"error":       staleImportError(staleBefore).Error(),
+		"finished_at": now,
+		"updated_at":  now,
+	}}
+
+	result, err := m.importJobs.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (m *MongoService) GetImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrImportJobNotFound, err)
+	}
+
+	var doc importJobDocument
+	err = m.importJobs.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: задача с ID %s не найдена", errors.ErrImportJobNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	job := doc.ImportJob
+	job.ID = doc.ObjectID.Hex()
+	return &job, nil
+}
+
+func (m *MongoService) runImport(ctx context.Context, job *models.ImportJob, items []models.ImportItem, background bool) {
+	job.Status = models.ImportStatusRunning
+	job.Items = make([]models.ImportItemReport, 0, len(items))
+
+	known, err := m.noteHashes(ctx, job.AuthorID)
+	if err != nil {
+		failImport(job, err)
+		return
+	}
+
+	lastSave := time.Now()
+	for i, item := range items {
+		if background && interruptedImport(ctx, job) {
+			return
+		}
+
+		report := m.importItem(ctx, job, i, item, known)
+		switch report.Status {
+		case models.ImportItemCreated, models.ImportItemWouldCreate:
+			job.Created++
+		case models.ImportItemDuplicate:
+			job.Duplicates++
+		default:
+			job.Failed++
+		}
+
+		job.Items = append(job.Items, report)
+		job.Processed++
+
+		if background && importProgressDue(job, lastSave) {
+			if err := m.saveImportJob(ctx, *job); err != nil {
+				fmt.Printf("Ошибка сохранения прогресса импорта %s: %v\n", job.ID, err)
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	if !job.DryRun && job.Created > 0 {
+		m.invalidateAuthorCache(job.AuthorID)
+	}
+
+	job.Status = models.ImportStatusDone
+	job.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func (m *MongoService) importItem(ctx context.Context, job *models.ImportJob, index int, item models.ImportItem, known map[string]string) models.ImportItemReport {
+	report := models.ImportItemReport{Index: index, Source: item.Source, Name: item.Note.Name}
+	if item.Error != "" {
+		report.Status = models.ImportItemFailed
+		report.Error = item.Error
+		return report
+	}
+
+	note := item.Note
+	tags, err := models.NormalizeTags(note.Tags)
+	if err != nil {
+		report.Status = models.ImportItemFailed
+		report.Error = err.Error()
+		return report
+	}
+	note.Tags = tags
+
+	hash := noteContentHash(note)
+	if duplicateOf, found := known[hash]; found {
+		report.Status = models.ImportItemDuplicate
+		report.DuplicateOf = duplicateOf
+		return report
+	}
+
+	if job.DryRun {
+		known[hash] = ""
+		report.Status = models.ImportItemWouldCreate
+		return report
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	note.AuthorID = job.AuthorID
+	note.Shares = nil
+	note.DeletedAt = time.Time{}
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = now
+	}
+	if note.UpdatedAt.IsZero() {
+		note.UpdatedAt = note.CreatedAt
+	}
+	if m.checkNotebook(ctx, job.AuthorID, note.NotebookID) != nil {
+		note.NotebookID = ""
+	}
+
+	createdNote, err := m.insertNote(ctx, note)
+	if err != nil {
+		report.Status = models.ImportItemFailed
+		report.Error = err.Error()
+		return report
+	}
+
+	known[hash] = createdNote.ID
+	m.publishNoteEvent(models.NoteEventCreated, *createdNote)
+	report.Status = models.ImportItemCreated
+	report.NoteID = createdNote.ID
+	return report
+}
+
+func (m *MongoService) noteHashes(ctx context.Context, authorID int) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := m.ExportNotes(ctx, authorID, func(note models.Note) error {
+		hashes[noteContentHash(note)] = note.ID
+		return nil
+	})
+	return hashes, err
+}
+
+func (m *MongoService) saveImportJob(ctx context.Context, job models.ImportJob) error {
+	objectID, err := primitive.ObjectIDFromHex(job.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrImportJobNotFound, err)
+	}
+
+	job.ID = ""
+	job.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	_, err = m.importJobs.ReplaceOne(ctx, bson.M{"_id": objectID}, job, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return nil
+}
+
+func noteContentHash(note models.Note) string {
+	hash := sha256.New()
+	hash.Write([]byte(note.Name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(note.Content))
+	return hex.EncodeToString(hash.Sum(nil))
+}
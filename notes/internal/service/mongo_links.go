@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const linkTokenBytes = 32
+
+func linkIndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "note_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}
+}
+
+func (m *MongoService) CreateLink(ctx context.Context, note models.Note, request models.CreateLinkRequest) (*models.ShareLink, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if !request.ExpiresAt.IsZero() && !request.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: срок действия уже истёк", errors.ErrInvalidLink)
+	}
+
+	token, err := newLinkToken()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrLinkCreation, err)
+	}
+
+	link := models.ShareLink{
+		Token:     token,
+		NoteID:    note.ID,
+		AuthorID:  note.AuthorID,
+		ExpiresAt: request.ExpiresAt.UTC(),
+		CreatedAt: now,
+	}
+
+	if request.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidLink, err)
+		}
+		link.PasswordHash = string(hash)
+		link.Protected = true
+	}
+
+	if _, err := m.links.InsertOne(ctx, link); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrLinkCreation, err)
+	}
+
+	return &link, nil
+}
+
+func (m *MongoService) GetLinks(ctx context.Context, noteID string) ([]models.ShareLink, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := m.links.Find(ctx, bson.M{"note_id": noteID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	links := make([]models.ShareLink, 0)
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return links, nil
+}
+
+func (m *MongoService) RevokeLink(ctx context.Context, noteID string, token string) error {
+	result, err := m.links.DeleteOne(ctx, bson.M{"note_id": noteID, "token": token})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+	}
+
+	return nil
+}
+
+func (m *MongoService) OpenLink(ctx context.Context, token string, password string) (*models.Note, *models.ShareLink, error) {
+	var link models.ShareLink
+	err := m.links.FindOne(ctx, bson.M{"token": token}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if link.Expired(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkExpired, link.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if link.Protected {
+		if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+			return nil, nil, fmt.Errorf("%w", errors.ErrLinkPassword)
+		}
+	}
+
+	note, err := m.GetByID(ctx, link.NoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = m.links.FindOneAndUpdate(ctx, bson.M{"token": token}, bson.M{"$inc": bson.M{"views": 1}}, findOptions).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return note, &link, nil
+}
+
+func (m *MongoService) deleteLinks(ctx context.Context, noteID string) error {
+	if _, err := m.links.DeleteMany(ctx, bson.M{"note_id": noteID}); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func newLinkToken() (string, error) {
+	buffer := make([]byte, linkTokenBytes)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
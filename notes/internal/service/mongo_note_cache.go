@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/caching"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	noteCacheTTL         = 100 * time.Minute
+	noteNegativeCacheTTL = 30 * time.Second
+	noteCacheMissing     = "null"
+)
+
+func (m *MongoService) GetByID(ctx context.Context, id string) (*models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	// Внутри транзакции чтение видит её незафиксированные изменения: их нельзя
+	// ни брать из кэша, ни класть в него и отдавать другим через singleflight,
+	// иначе после отката кэш продолжит раздавать несуществующее состояние.
+	if mongo.SessionFromContext(ctx) != nil {
+		return m.findNote(ctx, objectID)
+	}
+
+	cacheKey := m.getNoteCacheKey(objectID.Hex())
+	if note, found, ok := m.getCachedNote(cacheKey); ok {
+		if !found {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
+		}
+		return note, nil
+	}
+
+	// Одновременные промахи по одной заметке сливаются в одно чтение из
+	// Mongo; отмена запроса первого из ждущих не должна сорвать остальных.
+	loaded, err, _ := m.noteLoads.Do(cacheKey, func() (any, error) {
+		return m.loadNote(context.WithoutCancel(ctx), objectID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneNote(loaded.(*models.Note)), nil
+}
+
+func (m *MongoService) loadNote(ctx context.Context, objectID primitive.ObjectID) (*models.Note, error) {
+	id := objectID.Hex()
+	cacheKey := m.getNoteCacheKey(id)
+	generation, cacheable := m.getNoteCacheGeneration(id)
+
+	note, err := m.findNote(ctx, objectID)
+	if err != nil {
+		if cacheable && stdErrors.Is(err, errors.ErrNoteNotFound) {
+			m.setNoteCache(id, generation, noteCacheMissing, noteNegativeCacheTTL)
+		}
+		return nil, err
+	}
+
+	if cacheable {
+		if noteJSON, err := json.Marshal(note); err == nil {
+			m.setNoteCache(id, generation, string(noteJSON), noteCacheTTL)
+		} else {
+			fmt.Printf("Ошибка сериализации заметки %s для кэша: %v\n", cacheKey, err)
+		}
+	}
+
+	return note, nil
+}
+
+// findNote читает заметку из коллекции в обход кэша.
+func (m *MongoService) findNote(ctx context.Context, objectID primitive.ObjectID) (*models.Note, error) {
+	var note models.Note
+	err := m.collection.FindOne(ctx, activeNoteFilter(objectID)).Decode(&note)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, objectID.Hex())
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	note.ID = objectID.Hex()
+	return &note, nil
+}
+
+// getCachedNote возвращает ok=false при промахе; found=false означает
+// закэшированное отсутствие заметки.
+func (m *MongoService) getCachedNote(cacheKey string) (note *models.Note, found bool, ok bool) {
+	cachedData, err := m.cache.Get(cacheKey)
+	if err != nil {
+		if err != caching.ErrMiss {
+			fmt.Printf("Ошибка при получении кэша заметки %s: %v\n", cacheKey, err)
+		}
+		return nil, false, false
+	}
+
+	if cachedData == noteCacheMissing {
+		return nil, false, true
+	}
+
+	var cachedNote models.Note
+	if err := json.Unmarshal([]byte(cachedData), &cachedNote); err != nil {
+		fmt.Printf("Ошибка при разборе кэша заметки %s: %v\n", cacheKey, err)
+		return nil, false, false
+	}
+	return &cachedNote, true, true
+}
+
+// Заметка кладётся в кэш, только если поколение ключа не изменилось с начала
+// чтения из Mongo: иначе чтение, начатое до записи, могло бы вернуть в кэш
+// старую версию уже после инвалидации.
+func (m *MongoService) getNoteCacheGeneration(id string) (string, bool) {
+	generation, err := m.cache.Generation(m.getNoteCacheKey(id))
+	if err != nil {
+		fmt.Printf("Ошибка при получении поколения кэша заметки %s: %v\n", id, err)
+		return "", false
+	}
+	return generation, true
+}
+
+func (m *MongoService) setNoteCache(id string, generation string, value string, ttl time.Duration) {
+	if _, err := m.cache.SetIfGeneration(m.getNoteCacheKey(id), value, ttl, generation); err != nil {
+		fmt.Printf("Ошибка при сохранении заметки %s в кэш: %v\n", id, err)
+	}
+}
+
+// invalidateNotes удаляет заметки из кэша и сдвигает их поколение, чтобы
+// уже идущие чтения не положили туда старые данные.
+func (m *MongoService) invalidateNotes(ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, m.getNoteCacheKey(id))
+	}
+	if err := m.cache.Delete(keys...); err != nil {
+		fmt.Printf("Ошибка при инвалидации кэша заметок %v: %v\n", ids, err)
+	}
+}
+
+func (m *MongoService) getNoteCacheKey(id string) string {
+	return fmt.Sprintf("note:%s", id)
+}
+
+// Заметку из singleflight получают все ждавшие её запросы, а обработчики
+// меняют полученную заметку на месте, поэтому каждому отдаётся копия.
+func cloneNote(note *models.Note) *models.Note {
+	clone := *note
+	clone.Tags = slices.Clone(note.Tags)
+	clone.Shares = slices.Clone(note.Shares)
+	return &clone
+}
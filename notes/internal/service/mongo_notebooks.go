@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notebookDocument struct {
+	ObjectID        primitive.ObjectID `bson:"_id"`
+	models.Notebook `bson:",inline"`
+}
+
+func (d notebookDocument) toNotebook() models.Notebook {
+	notebook := d.Notebook
+	notebook.ID = d.ObjectID.Hex()
+	return notebook
+}
+
+func notebookIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "parent_id", Value: 1}},
+	}
+}
+
+func (m *MongoService) CreateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error) {
+	if !notebook.Valid() {
+		return nil, fmt.Errorf("%w: название %q", errors.ErrInvalidNotebook, notebook.Name)
+	}
+
+	if err := m.checkNotebook(ctx, notebook.AuthorID, notebook.ParentID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	notebook.CreatedAt = now
+	notebook.UpdatedAt = now
+
+	result, err := m.notebooks.InsertOne(ctx, bson.M{
+		"name":       notebook.Name,
+		"parent_id":  notebook.ParentID,
+		"author_id":  notebook.AuthorID,
+		"created_at": notebook.CreatedAt,
+		"updated_at": notebook.UpdatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	notebook.ID = result.InsertedID.(primitive.ObjectID).Hex()
+
+	return &notebook, nil
+}
+
+func (m *MongoService) GetNotebooks(ctx context.Context, authorId int) ([]models.Notebook, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := m.notebooks.Find(ctx, bson.M{"author_id": authorId}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	notebooks := make([]models.Notebook, 0)
+	for cursor.Next(ctx) {
+		var doc notebookDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		notebooks = append(notebooks, doc.toNotebook())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return notebooks, nil
+}
+
+func (m *MongoService) GetNotebook(ctx context.Context, id string) (*models.Notebook, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNotebookNotFound, err)
+	}
+
+	var doc notebookDocument
+	err = m.notebooks.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	notebook := doc.toNotebook()
+	return &notebook, nil
+}
+
+func (m *MongoService) UpdateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error) {
+	if !notebook.Valid() {
+		return nil, fmt.Errorf("%w: название %q, родитель %q", errors.ErrInvalidNotebook, notebook.Name, notebook.ParentID)
+	}
+
+	if notebook.ParentID != "" {
+		if err := m.checkNotebook(ctx, notebook.AuthorID, notebook.ParentID); err != nil {
+			return nil, err
+		}
+
+		notebooks, err := m.GetNotebooks(ctx, notebook.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(models.NotebookDescendants(notebooks, notebook.ID), notebook.ParentID) {
+			return nil, fmt.Errorf("%w: блокнот нельзя вложить в собственный подблокнот", errors.ErrInvalidNotebook)
+		}
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(notebook.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNotebookNotFound, err)
+	}
+
+	notebook.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	update := bson.M{"$set": bson.M{
+		"name":       notebook.Name,
+		"parent_id":  notebook.ParentID,
+		"updated_at": notebook.UpdatedAt,
+	}}
+
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc notebookDocument
+	err = m.notebooks.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, findOptions).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, notebook.ID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	m.invalidateAuthorCache(notebook.AuthorID)
+
+	updated := doc.toNotebook()
+	return &updated, nil
+}
+
+func (m *MongoService) DeleteNotebook(ctx context.Context, notebook models.Notebook, mode string) error {
+	if !models.ValidNotebookDeleteMode(mode) {
+		return fmt.Errorf("%w: режим удаления %q", errors.ErrInvalidNotebook, mode)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(notebook.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrNotebookNotFound, err)
+	}
+
+	var movedNotes []string
+	var trashedNotes []models.Note
+	if mode == models.NotebookDeleteReparent {
+		movedNotes, err = m.reparentNotebook(ctx, notebook)
+	} else {
+		trashedNotes, err = m.cascadeNotebook(ctx, notebook)
+	}
+	m.invalidateNotes(movedNotes...)
+	for _, note := range trashedNotes {
+		m.invalidateNoteCache(note)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.notebooks.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	m.invalidateAuthorCache(notebook.AuthorID)
+	for _, note := range trashedNotes {
+		m.publishNoteEvent(models.NoteEventDeleted, note)
+	}
+
+	return nil
+}
+
+// MoveNote, как и другие изменения состояния, увеличивает версию и
+// updated_at; version > 0 — ожидаемая версия из If-Match.
+func (m *MongoService) MoveNote(ctx context.Context, noteID string, notebookID string, version int) (*models.Note, error) {
+	note, err := m.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.checkNotebook(ctx, note.AuthorID, notebookID); err != nil {
+		return nil, err
+	}
+
+	return m.updateNoteState(ctx, noteID, version, notebookUpdate(notebookID))
+}
+
+// notebookUpdate строит новый документ обновления на каждый вызов:
+// updateNoteState дополняет его версией и updated_at.
+func notebookUpdate(notebookID string) bson.M {
+	if notebookID == "" {
+		return bson.M{"$unset": bson.M{"notebook_id": ""}}
+	}
+	return bson.M{"$set": bson.M{"notebook_id": notebookID}}
+}
+
+// reparentNotebook переносит активные заметки по одной через
+// updateNoteState, как MoveNote: с ревизией, новой версией, сбросом кэша и
+// событием. У заметок в корзине меняется только блокнот; их ID возвращаются
+// для инвалидации. cascadeNotebook возвращает сами заметки, ушедшие в
+// корзину, чтобы сбросить их кэш (в том числе у тех, кому они открыты) и
+// разослать события удаления. Списки собираются до обновления и могут быть
+// шире фактически изменённого, что для инвалидации безопасно.
+func (m *MongoService) reparentNotebook(ctx context.Context, notebook models.Notebook) ([]string, error) {
+	noteFilter := bson.M{"author_id": notebook.AuthorID, "notebook_id": notebook.ID, "deleted_at": nil}
+	movedIDs, err := m.findNoteIDs(ctx, noteFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range movedIDs {
+		if _, err := m.updateNoteState(ctx, id, 0, notebookUpdate(notebook.ParentID)); err != nil && !stdErrors.Is(err, errors.ErrNoteNotFound) {
+			return nil, err
+		}
+	}
+
+	trashedFilter := bson.M{"author_id": notebook.AuthorID, "notebook_id": notebook.ID}
+	trashedIDs, err := m.findNoteIDs(ctx, trashedFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.collection.UpdateMany(ctx, trashedFilter, notebookUpdate(notebook.ParentID)); err != nil {
+		return trashedIDs, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	childFilter := bson.M{"author_id": notebook.AuthorID, "parent_id": notebook.ID}
+	childUpdate := bson.M{"$set": bson.M{"parent_id": notebook.ParentID}}
+	if _, err := m.notebooks.UpdateMany(ctx, childFilter, childUpdate); err != nil {
+		return trashedIDs, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return trashedIDs, nil
+}
+
+func (m *MongoService) cascadeNotebook(ctx context.Context, notebook models.Notebook) ([]models.Note, error) {
+	notebooks, err := m.GetNotebooks(ctx, notebook.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := models.NotebookDescendants(notebooks, notebook.ID)
+
+	noteFilter := bson.M{"author_id": notebook.AuthorID, "notebook_id": bson.M{"$in": ids}, "deleted_at": nil}
+	cursor, err := m.collection.Find(ctx, noteFilter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []noteDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	notes := make([]models.Note, 0, len(docs))
+	for _, doc := range docs {
+		notes = append(notes, doc.toNote())
+	}
+
+	noteUpdate := bson.M{
+		"$set":   bson.M{"deleted_at": time.Now().UTC().Truncate(time.Millisecond)},
+		"$unset": bson.M{"notebook_id": ""},
+	}
+	if _, err := m.collection.UpdateMany(ctx, noteFilter, noteUpdate); err != nil {
+		return notes, fmt.Errorf("%w: %v", errors.ErrNoteDeletion, err)
+	}
+
+	trashFilter := bson.M{"author_id": notebook.AuthorID, "notebook_id": bson.M{"$in": ids}}
+	if _, err := m.collection.UpdateMany(ctx, trashFilter, bson.M{"$unset": bson.M{"notebook_id": ""}}); err != nil {
+		return notes, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	if _, err := m.notebooks.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": objectIDs}}); err != nil {
+		return notes, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return notes, nil
+}
+
+func (m *MongoService) findNoteIDs(ctx context.Context, filter bson.M) ([]string, error) {
+	cursor, err := m.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []struct {
+		ObjectID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ObjectID.Hex())
+	}
+	return ids, nil
+}
+
+func (m *MongoService) checkNotebook(ctx context.Context, authorID int, notebookID string) error {
+	if notebookID == "" {
+		return nil
+	}
+
+	notebook, err := m.GetNotebook(ctx, notebookID)
+	if err != nil {
+		return err
+	}
+
+	if notebook.AuthorID != authorID {
+		return fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, notebookID)
+	}
+
+	return nil
+}
+
+func (m *MongoService) notebookFilterIDs(ctx context.Context, authorID int, query models.NoteQuery) ([]string, error) {
+	if err := m.checkNotebook(ctx, authorID, query.NotebookID); err != nil {
+		return nil, err
+	}
+
+	if !query.IncludeSubnotebooks {
+		return []string{query.NotebookID}, nil
+	}
+
+	notebooks, err := m.GetNotebooks(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NotebookDescendants(notebooks, query.NotebookID), nil
+}
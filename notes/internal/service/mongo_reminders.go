@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	reminderLeaseKey = "notes:reminders:lease"
+	reminderLeaseTTL = time.Minute
+	inboxTTL         = 30 * 24 * time.Hour
+
+	// reminderClaimTTL — сколько забранное напоминание считается доставляемым.
+	// Если за это время CompleteReminder не вызван (доставка упала или реплика
+	// остановилась), напоминание снова попадёт в ClaimDueReminders.
+	reminderClaimTTL = 2 * time.Minute
+)
+
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+
+type inboxDocument struct {
+	ObjectID         primitive.ObjectID `bson:"_id,omitempty"`
+	models.InboxItem `bson:",inline"`
+}
+
+func (d inboxDocument) toInboxItem() models.InboxItem {
+	item := d.InboxItem
+	item.ID = d.ObjectID.Hex()
+	return item
+}
+
+func inboxIndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(inboxTTL.Seconds())),
+		},
+	}
+}
+
+func (m *MongoService) SetReminder(ctx context.Context, noteID string, request models.ReminderRequest, version int) (*models.Note, error) {
+	reminder := models.Note{RemindAt: request.RemindAt, DueAt: request.DueAt, Recurrence: request.Recurrence}
+	if err := models.ValidateReminder(reminder); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReminder, err)
+	}
+
+	set := bson.M{}
+	unset := bson.M{}
+	setOrUnset := func(field string, value any, empty bool) {
+		if empty {
+			unset[field] = ""
+		} else {
+			set[field] = value
+		}
+	}
+	setOrUnset("remind_at", request.RemindAt.UTC().Truncate(time.Millisecond), request.RemindAt.IsZero())
+	setOrUnset("due_at", request.DueAt.UTC().Truncate(time.Millisecond), request.DueAt.IsZero())
+	setOrUnset("recurrence", request.Recurrence, request.Recurrence == "")
+	unset["reminder_claimed_until"] = ""
+	unset["reminder_claim_token"] = ""
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return m.updateNoteState(ctx, noteID, version, update)
+}
+
+func (m *MongoService) GetUpcomingReminders(ctx context.Context, authorId int, until time.Time, limit int) ([]models.Reminder, error) {
+	remindAt := bson.M{"$ne": nil}
+	if !until.IsZero() {
+		remindAt["$lte"] = until
+	}
+	filter := bson.M{
+		"author_id":  authorId,
+		"deleted_at": nil,
+		"remind_at":  remindAt,
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "remind_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	reminders := make([]models.Reminder, 0)
+	for cursor.Next(ctx) {
+		var doc noteDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+
+		note := doc.toNote()
+		reminder := models.ReminderFromNote(note)
+		if next, ok := nextReminder(note, note.RemindAt); ok {
+			reminder.NextRemindAt = next
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return reminders, nil
+}
+
+// ClaimDueReminders забирает напоминания, время которых наступило. Сканирует
+// только реплика, взявшая аренду в Redis, а каждое напоминание помечается
+// доставляемым условным обновлением reminder_claimed_until, поэтому даже при
+// истёкшей аренде одно срабатывание не достанется двум репликам. remind_at
+// переводится на следующее повторение только в CompleteReminder и только с
+// токеном из reminder_claim_token: если пометка истекла и напоминание забрала
+// другая реплика, запоздалое завершение его не тронет.
+func (m *MongoService) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]models.Reminder, error) {
+	release, acquired, err := m.acquireReminderLease()
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, nil
+	}
+	defer release()
+
+	unclaimed := bson.A{
+		bson.M{"reminder_claimed_until": nil},
+		bson.M{"reminder_claimed_until": bson.M{"$lte": now}},
+	}
+	filter := bson.M{
+		"deleted_at": nil,
+		"remind_at":  bson.M{"$lte": now},
+		"$or":        unclaimed,
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "remind_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []noteDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	claimedUntil := now.Add(reminderClaimTTL)
+	claimToken := primitive.NewObjectID().Hex()
+	reminders := make([]models.Reminder, 0, len(docs))
+	for _, doc := range docs {
+		note := doc.toNote()
+		reminder := models.ReminderFromNote(note)
+		if next, ok := nextReminder(note, now); ok {
+			reminder.NextRemindAt = next
+		}
+		reminder.ClaimToken = claimToken
+
+		claimFilter := bson.M{"_id": doc.ObjectID, "remind_at": note.RemindAt, "$or": unclaimed}
+		claim := bson.M{"$set": bson.M{"reminder_claimed_until": claimedUntil, "reminder_claim_token": claimToken}}
+		result, err := m.collection.UpdateOne(ctx, claimFilter, claim)
+		if err != nil {
+			fmt.Printf("Ошибка обновления напоминания заметки %s: %v\n", note.ID, err)
+			continue
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+// CompleteReminder вызывается после успешной доставки: переводит напоминание
+// на NextRemindAt или снимает его. Если remind_at за время доставки поменяли
+// или напоминание забрала другая реплика, оно не трогается.
+func (m *MongoService) CompleteReminder(ctx context.Context, reminder models.Reminder) error {
+	objectID, err := primitive.ObjectIDFromHex(reminder.NoteID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	unclaim := bson.M{"reminder_claimed_until": "", "reminder_claim_token": ""}
+	update := bson.M{"$unset": bson.M{"remind_at": "", "reminder_claimed_until": "", "reminder_claim_token": ""}}
+	if !reminder.NextRemindAt.IsZero() {
+		set := bson.M{"remind_at": reminder.NextRemindAt}
+		if !reminder.DueAt.IsZero() {
+			set["due_at"] = reminder.DueAt.Add(reminder.NextRemindAt.Sub(reminder.RemindAt))
+		}
+		update = bson.M{"$set": set, "$unset": unclaim}
+	}
+
+	filter := bson.M{"_id": objectID, "remind_at": reminder.RemindAt, "reminder_claim_token": reminder.ClaimToken}
+	var doc noteDocument
+	err = m.collection.FindOneAndUpdate(ctx, filter, update).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	m.invalidateNoteCache(doc.toNote())
+	return nil
+}
+
+func (m *MongoService) DeliverToInbox(ctx context.Context, reminder models.Reminder) error {
+	item := models.InboxItem{
+		UserID:    reminder.AuthorID,
+		Reminder:  reminder,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if _, err := m.inbox.InsertOne(ctx, inboxDocument{InboxItem: item}); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (m *MongoService) GetInbox(ctx context.Context, userID int, limit int) ([]models.InboxItem, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.inbox.Find(ctx, bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []inboxDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	items := make([]models.InboxItem, 0, len(docs))
+	for _, doc := range docs {
+		items = append(items, doc.toInboxItem())
+	}
+	return items, nil
+}
+
+// Пропущенные, пока сервис не работал, повторения не догоняются: следующее
+// напоминание ставится на первое повторение после after.
+func nextReminder(note models.Note, after time.Time) (time.Time, bool) {
+	if note.Recurrence == "" {
+		return time.Time{}, false
+	}
+	recurrence, err := models.ParseRecurrence(note.Recurrence)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return recurrence.Next(note.RemindAt, after)
+}
+
+// acquireReminderLease берёт аренду сканирования в Redis. Аренда лишь
+// избавляет реплики от одновременного сканирования, поэтому без Redis
+// сканирование идёт без неё и не ждёт таймаута соединения.
+func (m *MongoService) acquireReminderLease() (func(), bool, error) {
+	noLease := func() {}
+	if !m.redisHealth.Up() {
+		return noLease, true, nil
+	}
+
+	token, err := newLeaseToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	acquired, err := m.redis.SetNX(reminderLeaseKey, token, reminderLeaseTTL).Result()
+	if err != nil {
+		m.redisHealth.Report(err)
+		fmt.Printf("Ошибка получения аренды напоминаний, сканируем без неё: %v\n", err)
+		return noLease, true, nil
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	release := func() {
+		if err := releaseLeaseScript.Run(m.redis, []string{reminderLeaseKey}, token).Err(); err != nil {
+			m.redisHealth.Report(err)
+			fmt.Printf("Ошибка освобождения аренды напоминаний: %v\n", err)
+		}
+	}
+	return release, true, nil
+}
+
+func newLeaseToken() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
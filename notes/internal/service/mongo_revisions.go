@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func revisionIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "note_id", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	}
+}
+
+func (m *MongoService) GetRevisions(ctx context.Context, noteID string) ([]models.NoteRevision, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"content": 0})
+
+	cursor, err := m.revisions.Find(ctx, bson.M{"note_id": noteID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	revisions := make([]models.NoteRevision, 0)
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return revisions, nil
+}
+
+func (m *MongoService) GetRevision(ctx context.Context, noteID string, version int) (*models.NoteRevision, error) {
+	if version <= 0 {
+		return nil, fmt.Errorf("%w: %d", errors.ErrInvalidRevision, version)
+	}
+
+	note, err := m.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if note.Version == version {
+		revision := models.RevisionFromNote(*note)
+		return &revision, nil
+	}
+
+	var revision models.NoteRevision
+	err = m.revisions.FindOne(ctx, bson.M{"note_id": noteID, "version": version}).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: версия %d заметки %s", errors.ErrRevisionNotFound, version, noteID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &revision, nil
+}
+
+func (m *MongoService) RestoreRevision(ctx context.Context, noteID string, version int, expectedVersion int) (*models.Note, error) {
+	revision, err := m.GetRevision(ctx, noteID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Update(ctx, models.Note{
+		ID:       noteID,
+		Name:     revision.Name,
+		Content:  revision.Content,
+		Tags:     revision.Tags,
+		AuthorID: revision.AuthorID,
+		Version:  expectedVersion,
+	})
+}
+
+func (m *MongoService) saveRevision(ctx context.Context, note models.Note) error {
+	_, err := m.revisions.InsertOne(ctx, models.RevisionFromNote(note))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %v", errors.ErrRevisionSave, err)
+	}
+
+	if m.revisionsLimit <= 0 {
+		return nil
+	}
+
+	findOptions := options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetSkip(int64(m.revisionsLimit - 1)).
+		SetProjection(bson.M{"version": 1})
+
+	var oldest models.NoteRevision
+	err = m.revisions.FindOne(ctx, bson.M{"note_id": note.ID}, findOptions).Decode(&oldest)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	_, err = m.revisions.DeleteMany(ctx, bson.M{"note_id": note.ID, "version": bson.M{"$lt": oldest.Version}})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return nil
+}
+
+func (m *MongoService) deleteRevisions(ctx context.Context, noteID string) error {
+	if _, err := m.revisions.DeleteMany(ctx, bson.M{"note_id": noteID}); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	textIndexName     = "notes_text"
+	textIndexLanguage = "russian"
+)
+
+type searchDocument struct {
+	ObjectID    primitive.ObjectID `bson:"_id"`
+	models.Note `bson:",inline"`
+	Score       float64 `bson:"score"`
+}
+
+func textIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: "text"}, {Key: "content", Value: "text"}},
+		Options: options.Index().
+			SetName(textIndexName).
+			SetDefaultLanguage(textIndexLanguage).
+			SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "content", Value: 1}}),
+	}
+}
+
+func (m *MongoService) Search(ctx context.Context, authorId int, query models.SearchQuery) ([]models.SearchResult, error) {
+	query.Normalize()
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: пустой поисковый запрос", errors.ErrInvalidQuery)
+	}
+
+	filter := bson.M{
+		"author_id":  authorId,
+		"deleted_at": nil,
+		"$text": bson.M{
+			"$search":   query.Text,
+			"$language": textIndexLanguage,
+		},
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit))
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	terms := searchTerms(query.Text)
+	results := make([]models.SearchResult, 0, query.Limit)
+	for cursor.Next(ctx) {
+		var doc searchDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+
+		note := doc.Note
+		note.ID = doc.ObjectID.Hex()
+		results = append(results, models.SearchResult{
+			Note:       note,
+			Score:      doc.Score,
+			Highlights: highlightFields(note, terms),
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return results, nil
+}
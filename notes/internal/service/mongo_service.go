@@ -2,231 +2,668 @@ package service
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"notes/internal/caching"
 	"notes/internal/config"
 	"notes/internal/database"
 	"notes/internal/errors"
+	"notes/internal/events"
 	"notes/internal/models"
+	"notes/internal/webhooks"
+	"regexp"
 	"time"
 
 	"github.com/go-redis/redis"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
 )
 
 type MongoService struct {
-	db         *mongo.Client
-	collection *mongo.Collection
-	caching    *redis.Client
+	db             *mongo.Client
+	collection     *mongo.Collection
+	revisions      *mongo.Collection
+	links          *mongo.Collection
+	notebooks      *mongo.Collection
+	importJobs     *mongo.Collection
+	inbox          *mongo.Collection
+	webhooks       *mongo.Collection
+	deliveries     *mongo.Collection
+	attachments    *gridfs.Bucket
+	cache          caching.Cache
+	redis          *redis.Client
+	redisHealth    *caching.RedisHealth
+	events         *events.Broker
+	revisionsLimit int
+
+	attachmentMaxSize int64
+	attachmentQuota   int64
+	importSyncLimit   int
+	imports           *importRunner
+
+	noteLoads singleflight.Group
+
+	webhookQueue        *webhooks.Queue
+	webhookMaxAttempts  int
+	webhookDisableAfter int
+}
+
+type noteDocument struct {
+	ObjectID    primitive.ObjectID `bson:"_id"`
+	models.Note `bson:",inline"`
+}
+
+func (d noteDocument) toNote() models.Note {
+	note := d.Note
+	note.ID = d.ObjectID.Hex()
+	return note
 }
 
 var _ Service = (*MongoService)(nil)
 
-func NewService(cfg *config.Config) (Service, error) {
+func newMongoService(cfg *config.Config) (*MongoService, error) {
 	db, err := database.NewDatabase(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseConnection, err)
 	}
 
-	cache, err := caching.NewCaching(cfg)
+	redisClient, redisHealth := caching.NewCaching(cfg)
+	cache, err := caching.NewCache(cfg, redisClient, redisHealth.Up())
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrCacheConnection, err)
 	}
 
 	collection := db.Database(cfg.DB_NAME).Collection(cfg.DB_COLLECTION)
+	revisions := db.Database(cfg.DB_NAME).Collection(cfg.DBRevisionsCollection)
+	links := db.Database(cfg.DB_NAME).Collection(cfg.DBLinksCollection)
+	notebooks := db.Database(cfg.DB_NAME).Collection(cfg.DBNotebooksCollection)
+	importJobs := db.Database(cfg.DB_NAME).Collection(cfg.DBImportJobsCollection)
+	inbox := db.Database(cfg.DB_NAME).Collection(cfg.DBInboxCollection)
+	webhookCollection := db.Database(cfg.DB_NAME).Collection(cfg.DBWebhooksCollection)
+	deliveries := db.Database(cfg.DB_NAME).Collection(cfg.DBDeliveriesCollection)
+
+	attachments, err := gridfs.NewBucket(db.Database(cfg.DB_NAME), options.GridFSBucket().SetName(cfg.AttachmentsBucket))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrServiceCreation, err)
+	}
+
+	service := &MongoService{
+		db:             db,
+		collection:     collection,
+		revisions:      revisions,
+		links:          links,
+		notebooks:      notebooks,
+		importJobs:     importJobs,
+		inbox:          inbox,
+		webhooks:       webhookCollection,
+		deliveries:     deliveries,
+		cache:          cache,
+		redis:          redisClient,
+		redisHealth:    redisHealth,
+		events:         events.NewBroker(redisClient, redisHealth, cfg.EventsReplayLimit),
+		revisionsLimit: cfg.RevisionsLimit,
+		attachments:    attachments,
+
+		attachmentMaxSize: int64(cfg.AttachmentMaxSizeMB) << 20,
+		attachmentQuota:   int64(cfg.AttachmentsUserQuotaMB) << 20,
+		importSyncLimit:   cfg.ImportSyncLimit,
+		imports:           newImportRunner(),
+
+		webhookQueue:        webhooks.NewQueue(redisClient, redisHealth),
+		webhookMaxAttempts:  cfg.WebhookMaxAttempts,
+		webhookDisableAfter: cfg.WebhookDisableAfterFailures,
+	}
+
+	if err := service.ensureIndexes(cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrServiceCreation, err)
+	}
+
+	if err := service.backfillMetadata(cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrServiceCreation, err)
+	}
 
-	return &MongoService{
-		db:         db,
-		collection: collection,
-		caching:    cache,
-	}, nil
+	return service, nil
 }
-func (m *MongoService) Create(ctx context.Context, note models.Note) (*models.Note, error) {
-	result, err := m.collection.InsertOne(ctx, bson.M{
-		"name":      note.Name,
-		"content":   note.Content,
-		"author_id": note.AuthorID,
+
+func (m *MongoService) ensureIndexes(cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DBTimeout)*time.Second)
+	defer cancel()
+
+	_, err := m.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "pinned", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "pinned", Value: 1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "pinned", Value: 1}, {Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "notebook_id", Value: 1}}},
+		{Keys: bson.D{{Key: "shares.user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "remind_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "remind_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		textIndexModel(),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", errors.ErrNoteCreation, err)
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
 	}
 
-	insertedID := result.InsertedID.(primitive.ObjectID)
-	note.ID = insertedID.Hex()
+	if _, err := m.revisions.Indexes().CreateOne(ctx, revisionIndexModel()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
 
-	m.invalidateAuthorCache(note.AuthorID)
+	if _, err := m.links.Indexes().CreateMany(ctx, linkIndexModels()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
 
-	return &note, nil
+	if _, err := m.notebooks.Indexes().CreateOne(ctx, notebookIndexModel()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := m.importJobs.Indexes().CreateOne(ctx, importJobIndexModel()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := m.inbox.Indexes().CreateMany(ctx, inboxIndexModels()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := m.webhooks.Indexes().CreateOne(ctx, webhookIndexModel()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := m.deliveries.Indexes().CreateMany(ctx, deliveryIndexModels()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := m.attachments.GetFilesCollection().Indexes().CreateMany(ctx, attachmentIndexModels()); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return nil
 }
+func (m *MongoService) backfillMetadata(cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DBTimeout)*time.Second)
+	defer cancel()
+
+	result, err := m.collection.UpdateMany(ctx,
+		bson.M{"created_at": bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{
+			"created_at": bson.M{"$toDate": "$_id"},
+			"updated_at": bson.M{"$ifNull": bson.A{"$updated_at", bson.M{"$toDate": "$_id"}}},
+			"version":    bson.M{"$ifNull": bson.A{"$version", 1}},
+		}}},
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
 
-func (m *MongoService) GetByID(ctx context.Context, id string) (*models.Note, error) {
+	if result.ModifiedCount > 0 {
+		fmt.Println("Метаданные заполнены для заметок:", result.ModifiedCount)
+	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	return nil
+}
+
+func (m *MongoService) Create(ctx context.Context, note models.Note) (*models.Note, error) {
+	createdNote, err := m.createNote(ctx, note)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+		return nil, err
+	}
+
+	m.invalidateAuthorCache(createdNote.AuthorID)
+	m.publishNoteEvent(models.NoteEventCreated, *createdNote)
+
+	return createdNote, nil
+}
+
+func (m *MongoService) createNote(ctx context.Context, note models.Note) (*models.Note, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	note.Shares = nil
+	note.DeletedAt = time.Time{}
+	note.CreatedAt = now
+	note.UpdatedAt = now
+
+	return m.insertNote(ctx, note)
+}
+
+func (m *MongoService) insertNote(ctx context.Context, note models.Note) (*models.Note, error) {
+	note.Version = 1
+
+	if err := models.ValidateReminder(note); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReminder, err)
+	}
+
+	if err := m.checkNotebook(ctx, note.AuthorID, note.NotebookID); err != nil {
+		return nil, err
+	}
+
+	document := bson.M{
+		"name":       note.Name,
+		"content":    note.Content,
+		"author_id":  note.AuthorID,
+		"tags":       note.Tags,
+		"created_at": note.CreatedAt,
+		"updated_at": note.UpdatedAt,
+		"version":    note.Version,
+	}
+	if note.NotebookID != "" {
+		document["notebook_id"] = note.NotebookID
+	}
+	if note.Archived {
+		note.Pinned = false
+		document["archived"] = true
+	}
+	if note.Pinned {
+		document["pinned"] = true
+	}
+	if !note.RemindAt.IsZero() {
+		document["remind_at"] = note.RemindAt
+	}
+	if !note.DueAt.IsZero() {
+		document["due_at"] = note.DueAt
+	}
+	if note.Recurrence != "" {
+		document["recurrence"] = note.Recurrence
 	}
 
-	var note models.Note
-	err = m.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&note)
+	result, err := m.collection.InsertOne(ctx, document)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
-		}
-		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteCreation, err)
 	}
 
-	note.ID = objectID.Hex()
+	insertedID := result.InsertedID.(primitive.ObjectID)
+	note.ID = insertedID.Hex()
 
 	return &note, nil
 }
 
-func (m *MongoService) GetAll(ctx context.Context, authorId int) ([]models.Note, error) {
-	if cachedNotes, found := m.getCachedNotes(authorId); found {
+func (m *MongoService) GetAll(ctx context.Context, authorId int, query models.NoteQuery) (*models.NotesPage, error) {
+	query.Normalize()
+	if !query.Valid() {
+		return nil, fmt.Errorf("%w: сортировка %s %s", errors.ErrInvalidQuery, query.SortBy, query.Order)
+	}
+
+	if cachedPage, found := m.getCachedNotes(authorId, query); found {
 		fmt.Println("Заметки получены из кэша")
-		return cachedNotes, nil
+		return cachedPage, nil
 	}
 
-	filter := bson.M{"author_id": authorId}
+	filter, err := listFilter(authorId, query)
+	if err != nil {
+		return nil, err
+	}
 
-	cursor, err := m.collection.Find(ctx, filter)
+	if query.NotebookID != "" {
+		notebookIDs, err := m.notebookFilterIDs(ctx, authorId, query)
+		if err != nil {
+			return nil, err
+		}
+		filter["notebook_id"] = bson.M{"$in": notebookIDs}
+	}
+
+	findOptions := options.Find().
+		SetSort(listSort(query)).
+		SetLimit(int64(query.Limit + 1))
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
 	}
 	defer cursor.Close(ctx)
 
-	var notes []models.Note
+	notes := make([]models.Note, 0, query.Limit+1)
 	for cursor.Next(ctx) {
-		var note models.Note
-		if err := cursor.Decode(&note); err != nil {
+		var doc noteDocument
+		if err := cursor.Decode(&doc); err != nil {
 			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
 		}
-		notes = append(notes, note)
+		notes = append(notes, doc.toNote())
 	}
 
 	if err := cursor.Err(); err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
 	}
 
-	m.cacheNotes(authorId, notes)
+	page := &models.NotesPage{Notes: notes}
+	if len(notes) > query.Limit {
+		page.Notes = notes[:query.Limit]
+		page.NextCursor = encodeCursor(cursorForNote(page.Notes[query.Limit-1], query.SortBy))
+	}
+
+	m.cacheNotes(authorId, query, page)
 
-	return notes, nil
+	return page, nil
 }
 
 func (m *MongoService) Update(ctx context.Context, note models.Note) (*models.Note, error) {
+	updatedNote, err := m.updateNote(ctx, note)
+	if err != nil {
+		return nil, err
+	}
+
+	m.invalidateNoteCache(*updatedNote)
+	m.publishNoteEvent(models.NoteEventUpdated, *updatedNote)
+
+	return updatedNote, nil
+}
+
+// updateNote сначала сохраняет текущую версию в ревизии и только потом
+// меняет заметку с условием на эту версию: ошибка записи ревизии возвращается
+// до изменения, поэтому история не теряется. Если без If-Match заметку успели
+// изменить между чтением и записью, попытка повторяется с новой версией.
+func (m *MongoService) updateNote(ctx context.Context, note models.Note) (*models.Note, error) {
 	objectID, err := primitive.ObjectIDFromHex(note.ID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
 	}
 
 	var existingNote models.Note
-	err = m.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&existingNote)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, note.ID)
+	for {
+		err = m.collection.FindOne(ctx, versionedNoteFilter(objectID, note.Version)).Decode(&existingNote)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, m.missedWriteError(ctx, objectID, note.ID, errors.ErrNoteUpdate)
+			}
+			return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
 		}
-		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
-	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"name":    note.Name,
-			"content": note.Content,
-		},
-	}
+		existingNote.ID = note.ID
+		if err := m.saveRevision(ctx, existingNote); err != nil {
+			return nil, err
+		}
 
-	result, err := m.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
-	}
+		note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+		update := bson.M{
+			"$set": bson.M{
+				"name":       note.Name,
+				"content":    note.Content,
+				"tags":       note.Tags,
+				"updated_at": note.UpdatedAt,
+			},
+			"$inc": bson.M{"version": 1},
+		}
 
-	if result.MatchedCount == 0 {
-		return nil, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, note.ID)
+		result, err := m.collection.UpdateOne(ctx, versionedNoteFilter(objectID, existingNote.Version), update)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+		}
+		if result.MatchedCount > 0 {
+			break
+		}
+		if note.Version > 0 || existingNote.Version <= 0 {
+			return nil, m.missedWriteError(ctx, objectID, note.ID, errors.ErrNoteUpdate)
+		}
 	}
 
-	m.invalidateAuthorCache(existingNote.AuthorID)
-
 	note.AuthorID = existingNote.AuthorID
+	note.Shares = existingNote.Shares
+	note.NotebookID = existingNote.NotebookID
+	note.Pinned = existingNote.Pinned
+	note.Archived = existingNote.Archived
+	note.RemindAt = existingNote.RemindAt
+	note.DueAt = existingNote.DueAt
+	note.Recurrence = existingNote.Recurrence
+	note.CreatedAt = existingNote.CreatedAt
+	note.Version = existingNote.Version + 1
+	note.DeletedAt = time.Time{}
 
 	return &note, nil
 }
 
-func (m *MongoService) Delete(ctx context.Context, id string) error {
+func (m *MongoService) Delete(ctx context.Context, id string, version int) error {
+	deletedNote, err := m.deleteNote(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	m.invalidateNoteCache(*deletedNote)
+	m.publishNoteEvent(models.NoteEventDeleted, *deletedNote)
+
+	return nil
+}
+
+func (m *MongoService) deleteNote(ctx context.Context, id string, version int) (*models.Note, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{"deleted_at": time.Now().UTC().Truncate(time.Millisecond)},
 	}
 
 	var existingNote models.Note
-	err = m.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&existingNote)
+	err = m.collection.FindOneAndUpdate(ctx, versionedNoteFilter(objectID, version), update).Decode(&existingNote)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
+			return nil, m.missedWriteError(ctx, objectID, id, errors.ErrNoteDeletion)
 		}
-		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteDeletion, err)
 	}
 
-	result, err := m.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	existingNote.ID = id
+	return &existingNote, nil
+}
+
+func (m *MongoService) missedWriteError(ctx context.Context, objectID primitive.ObjectID, id string, operationErr error) error {
+	count, err := m.collection.CountDocuments(ctx, activeNoteFilter(objectID))
 	if err != nil {
-		return fmt.Errorf("%w: %v", errors.ErrNoteDeletion, err)
+		return fmt.Errorf("%w: %v", operationErr, err)
 	}
 
-	if result.DeletedCount == 0 {
+	if count == 0 {
 		return fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
 	}
 
-	m.invalidateAuthorCache(existingNote.AuthorID)
-
-	return nil
+	return fmt.Errorf("%w: заметка с ID %s была изменена", errors.ErrVersionConflict, id)
 }
 
 func (m *MongoService) Close() error {
-	if m.caching != nil {
-		if err := m.caching.Close(); err != nil {
+	if m.events != nil {
+		if err := m.events.Close(); err != nil {
+			fmt.Printf("Ошибка закрытия подписки на события: %v\n", err)
+		}
+	}
+	if m.cache != nil {
+		m.cache.Close()
+	}
+	if m.redisHealth != nil {
+		m.redisHealth.Close()
+	}
+	if m.redis != nil {
+		if err := m.redis.Close(); err != nil {
 			return fmt.Errorf("%w: %v", errors.ErrCacheClose, err)
 		}
 	}
 	return database.CloseDB(m.db, &config.Config{Timeout: 10})
 }
 
-func (m *MongoService) getCachedNotes(authorID int) ([]models.Note, bool) {
-	cacheKey := m.getCacheKey(authorID)
-	cachedData, err := m.caching.Get(cacheKey).Result()
+func (m *MongoService) getCachedNotes(authorID int, query models.NoteQuery) (*models.NotesPage, bool) {
+	cachedData, err := m.cache.Get(m.getPageCacheKey(authorID, query))
 	if err != nil {
-		fmt.Printf("Ошибка при получении кэша для автора с ID %d: %v\n", authorID, err)
+		if err != caching.ErrMiss {
+			fmt.Printf("Ошибка при получении кэша для автора с ID %d: %v\n", authorID, err)
+		}
 		return nil, false
 	}
-	var cachedNotes []models.Note
-	if err := json.Unmarshal([]byte(cachedData), &cachedNotes); err != nil {
+	var cachedPage models.NotesPage
+	if err := json.Unmarshal([]byte(cachedData), &cachedPage); err != nil {
 		fmt.Printf("Ошибка при разборе кэша для автора с ID %d: %v\n", authorID, err)
 		return nil, false
 	}
 	fmt.Println("Заметки для автора с ID", authorID, "успешно получены из кэша")
-	return cachedNotes, true
+	return &cachedPage, true
 }
 
 func (m *MongoService) getCacheKey(authorID int) string {
-	return fmt.Sprintf("notes:author:%d", authorID)
+	return fmt.Sprintf("author:%d", authorID)
+}
+
+// Все страницы списка автора — одна группа кэша с общим префиксом.
+func (m *MongoService) getPagesGroup(authorID int) string {
+	return fmt.Sprintf("%s:page:", m.getCacheKey(authorID))
+}
+
+func (m *MongoService) getTagsCacheKey(authorID int) string {
+	return fmt.Sprintf("%s:tags", m.getCacheKey(authorID))
+}
+
+func (m *MongoService) getSharedCacheKey(userID int) string {
+	return fmt.Sprintf("%s:shared", m.getCacheKey(userID))
+}
+
+func (m *MongoService) getPageCacheKey(authorID int, query models.NoteQuery) string {
+	queryJSON, _ := json.Marshal(query)
+	hash := sha1.Sum(queryJSON)
+	return m.getPagesGroup(authorID) + hex.EncodeToString(hash[:])
 }
 
 func (m *MongoService) invalidateAuthorCache(authorID int) {
-	if m.caching != nil {
-		cacheKey := m.getCacheKey(authorID)
-		m.caching.Del(cacheKey)
-		fmt.Println("Кэш для автора с ID", authorID, "был успешно инвалидирован")
+	if err := m.cache.InvalidateGroups(m.getPagesGroup(authorID)); err != nil {
+		fmt.Printf("Ошибка при инвалидации кэша для автора с ID %d: %v\n", authorID, err)
+	}
+	if err := m.cache.Delete(m.getTagsCacheKey(authorID), m.getSharedCacheKey(authorID)); err != nil {
+		fmt.Printf("Ошибка при инвалидации кэша для автора с ID %d: %v\n", authorID, err)
 	}
-	fmt.Println("Кэш для автора с ID", authorID, "не найден или не был инвалидирован")
+	fmt.Println("Кэш для автора с ID", authorID, "был успешно инвалидирован")
 }
 
-func (m *MongoService) cacheNotes(authorID int, notes []models.Note) {
-	if m.caching != nil {
-		cacheKey := m.getCacheKey(authorID)
-		notesJSON, err := json.Marshal(notes)
-		if err == nil {
-			m.caching.Set(cacheKey, notesJSON, 100*time.Minute)
-			fmt.Println("Заметки для автора с ID", authorID, "успешно сохранены в кэш")
+func (m *MongoService) invalidateNoteCache(note models.Note) {
+	m.invalidateNotes(note.ID)
+	m.invalidateAuthorCache(note.AuthorID)
+	for _, userID := range note.SharedUserIDs() {
+		m.invalidateAuthorCache(userID)
+	}
+}
+
+func (m *MongoService) cacheNotes(authorID int, query models.NoteQuery, page *models.NotesPage) {
+	pageJSON, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	if err := m.cache.Set(m.getPageCacheKey(authorID, query), string(pageJSON), 100*time.Minute, m.getPagesGroup(authorID)); err != nil {
+		fmt.Printf("Ошибка при сохранении кэша для автора с ID %d: %v\n", authorID, err)
+		return
+	}
+	fmt.Println("Заметки для автора с ID", authorID, "успешно сохранены в кэш")
+}
+
+func activeNoteFilter(objectID primitive.ObjectID) bson.M {
+	return bson.M{"_id": objectID, "deleted_at": nil}
+}
+
+func versionedNoteFilter(objectID primitive.ObjectID, version int) bson.M {
+	filter := activeNoteFilter(objectID)
+	if version > 0 {
+		filter["version"] = version
+	}
+	return filter
+}
+
+func listFilter(authorID int, query models.NoteQuery) (bson.M, error) {
+	filter := bson.M{"author_id": authorID, "deleted_at": nil}
+
+	if query.Archived {
+		filter["archived"] = true
+	} else {
+		filter["archived"] = bson.M{"$ne": true}
+	}
+
+	if query.Name != "" {
+		filter["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(query.Name), Options: "i"}
+	}
+
+	if len(query.Tags) > 0 {
+		if query.TagMode == models.TagModeAny {
+			filter["tags"] = bson.M{"$in": query.Tags}
+		} else {
+			filter["tags"] = bson.M{"$all": query.Tags}
+		}
+	}
+
+	if query.Cursor == "" {
+		return filter, nil
+	}
+
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	cursorID, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+	}
+
+	op := "$gt"
+	if query.Order == models.SortOrderDesc {
+		op = "$lt"
+	}
+
+	field := sortField(query.SortBy)
+	var value interface{} = cursor.Value
+	if query.SortBy != models.SortByName {
+		parsed, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+		}
+		value = parsed
+	}
+
+	after := bson.A{
+		bson.M{field: bson.M{op: value}},
+		bson.M{field: value, "_id": bson.M{op: cursorID}},
+	}
+
+	// Закреплённые заметки идут первыми при любом порядке сортировки,
+	// поэтому после закреплённой страницы продолжаем и по незакреплённым.
+	if cursor.Pinned {
+		filter["$or"] = bson.A{
+			bson.M{"pinned": true, "$or": after},
+			bson.M{"pinned": bson.M{"$ne": true}},
 		}
+	} else {
+		filter["pinned"] = bson.M{"$ne": true}
+		filter["$or"] = after
+	}
+
+	return filter, nil
+}
+
+func listSort(query models.NoteQuery) bson.D {
+	direction := 1
+	if query.Order == models.SortOrderDesc {
+		direction = -1
+	}
+
+	field := sortField(query.SortBy)
+	return bson.D{{Key: "pinned", Value: -1}, {Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+func sortField(sortBy string) string {
+	switch sortBy {
+	case models.SortByName:
+		return "name"
+	case models.SortByUpdated:
+		return "updated_at"
+	default:
+		return "created_at"
+	}
+}
+
+func cursorForNote(note models.Note, sortBy string) pageCursor {
+	cursor := pageCursor{ID: note.ID, Pinned: note.Pinned}
+	switch sortBy {
+	case models.SortByName:
+		cursor.Value = note.Name
+	case models.SortByUpdated:
+		cursor.Value = note.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = note.CreatedAt.Format(time.RFC3339Nano)
 	}
+	return cursor
 }
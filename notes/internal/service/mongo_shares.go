@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (m *MongoService) ShareNote(ctx context.Context, noteID string, share models.NoteShare) (*models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	if !share.Valid() {
+		return nil, fmt.Errorf("%w: пользователь %d, роль %q", errors.ErrInvalidShare, share.UserID, share.Role)
+	}
+
+	// Смена доступа меняет версию, чтобы ETag, выданные до неё, перестали
+	// подходить. Запись идёт с условием на прочитанную версию, поэтому два
+	// параллельных запроса не добавят пользователя дважды: проигравший
+	// перечитывает заметку и повторяет попытку.
+	for {
+		existingNote, err := m.findNote(ctx, objectID)
+		if err != nil {
+			return nil, err
+		}
+
+		update := bson.M{"$push": bson.M{"shares": share}}
+		index := slices.IndexFunc(existingNote.Shares, func(existing models.NoteShare) bool {
+			return existing.UserID == share.UserID
+		})
+		if index >= 0 {
+			update = bson.M{"$set": bson.M{fmt.Sprintf("shares.%d.role", index): share.Role}}
+		}
+
+		note, err := m.updateNoteState(ctx, noteID, existingNote.Version, update)
+		if stdErrors.Is(err, errors.ErrVersionConflict) {
+			continue
+		}
+		return note, err
+	}
+}
+
+func (m *MongoService) UnshareNote(ctx context.Context, noteID string, userID int) (*models.Note, error) {
+	existingNote, err := m.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$pull": bson.M{"shares": bson.M{"user_id": userID}}}
+	note, err := m.updateNoteState(ctx, noteID, 0, update)
+	if err != nil {
+		return nil, err
+	}
+
+	// Кэш общих заметок сбрасывается и у пользователя, которого убрали.
+	m.invalidateNoteCache(*existingNote)
+	m.publishNoteEventTo(models.NoteEventDeleted, *note, []int{userID})
+
+	return note, nil
+}
+
+func (m *MongoService) GetShared(ctx context.Context, userID int) ([]models.Note, error) {
+	if cachedNotes, found := m.getCachedShared(userID); found {
+		return cachedNotes, nil
+	}
+
+	filter := bson.M{"shares.user_id": userID, "deleted_at": nil}
+	findOptions := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	notes := make([]models.Note, 0)
+	for cursor.Next(ctx) {
+		var doc noteDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		notes = append(notes, doc.toNote())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	m.cacheShared(userID, notes)
+
+	return notes, nil
+}
+
+func (m *MongoService) getCachedShared(userID int) ([]models.Note, bool) {
+	cachedData, err := m.cache.Get(m.getSharedCacheKey(userID))
+	if err != nil {
+		return nil, false
+	}
+
+	var notes []models.Note
+	if err := json.Unmarshal([]byte(cachedData), &notes); err != nil {
+		fmt.Printf("Ошибка при разборе кэша общих заметок для пользователя с ID %d: %v\n", userID, err)
+		return nil, false
+	}
+	return notes, true
+}
+
+func (m *MongoService) cacheShared(userID int, notes []models.Note) {
+	notesJSON, err := json.Marshal(notes)
+	if err == nil {
+		m.cache.Set(m.getSharedCacheKey(userID), string(notesJSON), 100*time.Minute)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (m *MongoService) SetPinned(ctx context.Context, noteID string, pinned bool, version int) (*models.Note, error) {
+	update := bson.M{"$unset": bson.M{"pinned": ""}}
+	if pinned {
+		update = bson.M{"$set": bson.M{"pinned": true}}
+	}
+
+	return m.updateNoteState(ctx, noteID, version, update)
+}
+
+func (m *MongoService) SetArchived(ctx context.Context, noteID string, archived bool, version int) (*models.Note, error) {
+	update := bson.M{"$unset": bson.M{"archived": ""}}
+	if archived {
+		update = bson.M{
+			"$set":   bson.M{"archived": true},
+			"$unset": bson.M{"pinned": ""},
+		}
+	}
+
+	return m.updateNoteState(ctx, noteID, version, update)
+}
+
+// updateNoteState меняет закрепление, архив, напоминание или блокнот заметки.
+// Версия и updated_at растут, как при обновлении содержимого, поэтому ETag
+// меняется и такие записи тоже можно защитить If-Match; version <= 0 — без
+// проверки. Как и updateNote, сначала сохраняет текущую версию в ревизии,
+// чтобы в истории не было пропусков.
+func (m *MongoService) updateNoteState(ctx context.Context, noteID string, version int, update bson.M) (*models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+	}
+	update["$set"] = set
+	update["$inc"] = bson.M{"version": 1}
+
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc noteDocument
+	for {
+		var existing noteDocument
+		err = m.collection.FindOne(ctx, versionedNoteFilter(objectID, version)).Decode(&existing)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, m.missedWriteError(ctx, objectID, noteID, errors.ErrNoteUpdate)
+			}
+			return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+		}
+
+		// Архив снимает закрепление, поэтому закрепить архивную заметку
+		// нельзя. Архивирование меняет версию, так что гонку отсечёт фильтр
+		// записи ниже.
+		if set["pinned"] == true && existing.Archived {
+			return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrPinArchived, noteID)
+		}
+
+		if err := m.saveRevision(ctx, existing.toNote()); err != nil {
+			return nil, err
+		}
+
+		set["updated_at"] = time.Now().UTC().Truncate(time.Millisecond)
+		err = m.collection.FindOneAndUpdate(ctx, versionedNoteFilter(objectID, existing.Version), update, findOptions).Decode(&doc)
+		if err == nil {
+			break
+		}
+		if err != mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+		}
+		if version > 0 || existing.Version <= 0 {
+			return nil, m.missedWriteError(ctx, objectID, noteID, errors.ErrNoteUpdate)
+		}
+	}
+
+	note := doc.toNote()
+	m.invalidateNoteCache(note)
+	m.publishNoteEvent(models.NoteEventUpdated, note)
+
+	return &note, nil
+}
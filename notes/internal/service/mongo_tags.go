@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (m *MongoService) GetTags(ctx context.Context, authorId int) ([]models.TagCount, error) {
+	if cachedTags, found := m.getCachedTags(authorId); found {
+		return cachedTags, nil
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"author_id": authorId, "deleted_at": nil}},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+
+	cursor, err := m.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	tags := make([]models.TagCount, 0)
+	if err := cursor.All(ctx, &tags); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	m.cacheTags(authorId, tags)
+
+	return tags, nil
+}
+
+func (m *MongoService) getCachedTags(authorID int) ([]models.TagCount, bool) {
+	cachedData, err := m.cache.Get(m.getTagsCacheKey(authorID))
+	if err != nil {
+		return nil, false
+	}
+
+	var tags []models.TagCount
+	if err := json.Unmarshal([]byte(cachedData), &tags); err != nil {
+		fmt.Printf("Ошибка при разборе кэша тегов для автора с ID %d: %v\n", authorID, err)
+		return nil, false
+	}
+	fmt.Println("Теги для автора с ID", authorID, "успешно получены из кэша")
+	return tags, true
+}
+
+func (m *MongoService) cacheTags(authorID int, tags []models.TagCount) {
+	tagsJSON, err := json.Marshal(tags)
+	if err == nil {
+		m.cache.Set(m.getTagsCacheKey(authorID), string(tagsJSON), 100*time.Minute)
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func trashedNoteFilter(objectID primitive.ObjectID) bson.M {
+	return bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}
+}
+
+func (m *MongoService) GetTrash(ctx context.Context, authorId int) ([]models.Note, error) {
+	filter := bson.M{"author_id": authorId, "deleted_at": bson.M{"$ne": nil}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := m.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	notes := make([]models.Note, 0)
+	for cursor.Next(ctx) {
+		var doc noteDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		notes = append(notes, doc.toNote())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return notes, nil
+}
+
+func (m *MongoService) GetTrashedByID(ctx context.Context, id string) (*models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	var doc noteDocument
+	err = m.collection.FindOne(ctx, trashedNoteFilter(objectID)).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	note := doc.toNote()
+	return &note, nil
+}
+
+func (m *MongoService) RestoreFromTrash(ctx context.Context, id string) (*models.Note, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	var trashed noteDocument
+	err = m.collection.FindOne(ctx, trashedNoteFilter(objectID)).Decode(&trashed)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	// Восстановление меняет версию, чтобы ETag, выданный до удаления, не
+	// позволил перезаписать заметку без 412.
+	if err := m.saveRevision(ctx, trashed.toNote()); err != nil {
+		return nil, err
+	}
+
+	filter := trashedNoteFilter(objectID)
+	filter["version"] = trashed.Version
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	var doc noteDocument
+	err = m.collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	note := doc.toNote()
+	m.invalidateNoteCache(note)
+	m.publishNoteEvent(models.NoteEventCreated, note)
+
+	return &note, nil
+}
+
+func (m *MongoService) DeletePermanently(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	result, err := m.collection.DeleteOne(ctx, trashedNoteFilter(objectID))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrNoteDeletion, err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+	}
+
+	if err := m.deleteRevisions(ctx, id); err != nil {
+		fmt.Printf("Ошибка удаления ревизий заметки %s: %v\n", id, err)
+	}
+
+	if err := m.deleteLinks(ctx, id); err != nil {
+		fmt.Printf("Ошибка удаления публичных ссылок заметки %s: %v\n", id, err)
+	}
+
+	if err := m.deleteAttachments(ctx, id); err != nil {
+		fmt.Printf("Ошибка удаления вложений заметки %s: %v\n", id, err)
+	}
+
+	return nil
+}
+
+func (m *MongoService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$ne": nil, "$lte": deletedBefore}}
+
+	cursor, err := m.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer cursor.Close(ctx)
+
+	var purged int64
+	for cursor.Next(ctx) {
+		var doc struct {
+			ObjectID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return purged, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+
+		if err := m.DeletePermanently(ctx, doc.ObjectID.Hex()); err != nil {
+			fmt.Printf("Ошибка удаления заметки %s из корзины: %v\n", doc.ObjectID.Hex(), err)
+			continue
+		}
+		purged++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return purged, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"notes/internal/webhooks"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	webhookEnqueueTimeout = 5 * time.Second
+	webhookDeliveriesTTL  = 7 * 24 * time.Hour
+)
+
+type webhookDocument struct {
+	ObjectID       primitive.ObjectID `bson:"_id,omitempty"`
+	models.Webhook `bson:",inline"`
+}
+
+func (d webhookDocument) toWebhook() models.Webhook {
+	webhook := d.Webhook
+	webhook.ID = d.ObjectID.Hex()
+	return webhook
+}
+
+type deliveryDocument struct {
+	ObjectID               primitive.ObjectID `bson:"_id,omitempty"`
+	models.WebhookDelivery `bson:",inline"`
+}
+
+func (d deliveryDocument) toDelivery() models.WebhookDelivery {
+	delivery := d.WebhookDelivery
+	delivery.ID = d.ObjectID.Hex()
+	return delivery
+}
+
+func webhookIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "active", Value: 1}},
+	}
+}
+
+func deliveryIndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(webhookDeliveriesTTL.Seconds())),
+		},
+	}
+}
+
+func (m *MongoService) CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	count, err := m.webhooks.CountDocuments(ctx, bson.M{"author_id": webhook.AuthorID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if count >= models.MaxWebhooksPerUser {
+		return nil, fmt.Errorf("%w: не больше %d на пользователя", errors.ErrWebhookLimit, models.MaxWebhooksPerUser)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebhookCreation, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	webhook.ID = ""
+	webhook.Secret = secret
+	webhook.Active = true
+	webhook.ConsecutiveFailures = 0
+	webhook.LastDeliveryAt = time.Time{}
+	webhook.DisabledAt = time.Time{}
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	result, err := m.webhooks.InsertOne(ctx, webhookDocument{Webhook: webhook})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	webhook.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return &webhook, nil
+}
+
+func (m *MongoService) GetWebhooks(ctx context.Context, authorId int) ([]models.Webhook, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := m.webhooks.Find(ctx, bson.M{"author_id": authorId}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []webhookDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	result := make([]models.Webhook, 0, len(docs))
+	for _, doc := range docs {
+		result = append(result, doc.toWebhook().Redacted())
+	}
+	return result, nil
+}
+
+func (m *MongoService) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebhookNotFound, err)
+	}
+
+	var doc webhookDocument
+	if err := m.webhooks.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	webhook := doc.toWebhook().Redacted()
+	return &webhook, nil
+}
+
+// Включение webhook обнуляет счётчик ошибок, поэтому отключённый
+// автоматически адрес возвращается в работу через active: true.
+func (m *MongoService) UpdateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(webhook.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebhookNotFound, err)
+	}
+
+	set := bson.M{
+		"url":        webhook.URL,
+		"active":     webhook.Active,
+		"updated_at": time.Now().UTC().Truncate(time.Millisecond),
+	}
+	unset := bson.M{}
+	if len(webhook.Events) > 0 {
+		set["events"] = webhook.Events
+	} else {
+		unset["events"] = ""
+	}
+	if webhook.Active {
+		set["consecutive_failures"] = 0
+		unset["disabled_at"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var doc webhookDocument
+	err = m.webhooks.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, webhook.ID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	updated := doc.toWebhook().Redacted()
+	return &updated, nil
+}
+
+// Задачи удалённого webhook остаются в очереди и отбрасываются при выдаче.
+func (m *MongoService) DeleteWebhook(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrWebhookNotFound, err)
+	}
+
+	result, err := m.webhooks.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, id)
+	}
+
+	if _, err := m.deliveries.DeleteMany(ctx, bson.M{"webhook_id": id}); err != nil {
+		fmt.Printf("Ошибка удаления журнала доставок webhook %s: %v\n", id, err)
+	}
+	return nil
+}
+
+func (m *MongoService) GetWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.deliveries.Find(ctx, bson.M{"webhook_id": webhookID}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []deliveryDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	result := make([]models.WebhookDelivery, 0, len(docs))
+	for _, doc := range docs {
+		result = append(result, doc.toDelivery())
+	}
+	return result, nil
+}
+
+// ClaimWebhookJobs выдаёт готовые к отправке доставки вместе с адресом и
+// секретом webhook. Задачи удалённых и отключённых webhook отбрасываются.
+func (m *MongoService) ClaimWebhookJobs(ctx context.Context, now time.Time, limit int) ([]models.WebhookJob, error) {
+	jobs, err := m.webhookQueue.Claim(now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrCacheGet, err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(jobs))
+	for _, job := range jobs {
+		if objectID, err := primitive.ObjectIDFromHex(job.WebhookID); err == nil {
+			ids = append(ids, objectID)
+		}
+	}
+
+	cursor, err := m.webhooks.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var docs []webhookDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	byID := make(map[string]models.Webhook, len(docs))
+	for _, doc := range docs {
+		byID[doc.ObjectID.Hex()] = doc.toWebhook()
+	}
+
+	claimed := make([]models.WebhookJob, 0, len(jobs))
+	for _, job := range jobs {
+		webhook, ok := byID[job.WebhookID]
+		if !ok || !webhook.Active {
+			if err := m.webhookQueue.Ack(job.ID); err != nil {
+				fmt.Printf("Ошибка удаления доставки webhook: %v\n", err)
+			}
+			continue
+		}
+		job.Webhook = webhook
+		claimed = append(claimed, job)
+	}
+
+	return claimed, nil
+}
+
+// CompleteWebhookJob записывает попытку в журнал и решает судьбу задачи:
+// после ошибки она возвращается в очередь с экспоненциальной паузой, пока не
+// кончатся попытки, а webhook, у которого подряд не прошло
+// webhookDisableAfter попыток, отключается.
+func (m *MongoService) CompleteWebhookJob(ctx context.Context, job models.WebhookJob, delivery models.WebhookDelivery) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	retry := !delivery.Success && job.Attempt < m.webhookMaxAttempts
+
+	objectID, err := primitive.ObjectIDFromHex(job.WebhookID)
+	if err != nil {
+		return m.webhookQueue.Ack(job.ID)
+	}
+
+	if delivery.Success {
+		_, err = m.webhooks.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{
+			"consecutive_failures": 0,
+			"last_delivery_at":     now,
+		}})
+	} else {
+		var doc webhookDocument
+		err = m.webhooks.FindOneAndUpdate(ctx, bson.M{"_id": objectID},
+			bson.M{
+				"$inc": bson.M{"consecutive_failures": 1},
+				"$set": bson.M{"last_delivery_at": now},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+		if err == nil && m.webhookDisableAfter > 0 && doc.ConsecutiveFailures >= m.webhookDisableAfter {
+			retry = false
+			if err := m.disableWebhook(ctx, objectID, now); err != nil {
+				fmt.Printf("Ошибка отключения webhook %s: %v\n", job.WebhookID, err)
+			}
+		}
+		if err == mongo.ErrNoDocuments {
+			retry = false
+			err = nil
+		}
+	}
+	if err != nil {
+		fmt.Printf("Ошибка обновления состояния webhook %s: %v\n", job.WebhookID, err)
+	}
+
+	if retry {
+		delivery.NextRetry = now.Add(webhooks.Backoff(job.Attempt))
+	}
+	if _, err := m.deliveries.InsertOne(ctx, deliveryDocument{WebhookDelivery: delivery}); err != nil {
+		fmt.Printf("Ошибка записи журнала доставки webhook %s: %v\n", job.WebhookID, err)
+	}
+
+	if !retry {
+		if err := m.webhookQueue.Ack(job.ID); err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrCacheSet, err)
+		}
+		return nil
+	}
+
+	job.Attempt++
+	job.Webhook = models.Webhook{}
+	if err := m.webhookQueue.Retry(job, delivery.NextRetry); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrCacheSet, err)
+	}
+	return nil
+}
+
+func (m *MongoService) disableWebhook(ctx context.Context, objectID primitive.ObjectID, now time.Time) error {
+	result, err := m.webhooks.UpdateOne(ctx, bson.M{"_id": objectID, "active": true}, bson.M{"$set": bson.M{
+		"active":      false,
+		"disabled_at": now,
+		"updated_at":  now,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		fmt.Printf("Webhook %s отключён после %d ошибок доставки подряд\n", objectID.Hex(), m.webhookDisableAfter)
+	}
+	return nil
+}
+
+// Доставки ставятся в очередь всем активным webhook аудитории события,
+// подписанным на его тип; заметка в событии урезается так же, как в SSE.
+func (m *MongoService) enqueueWebhooks(event models.NoteEvent, audience []int) {
+	if m.webhookQueue == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookEnqueueTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"author_id": bson.M{"$in": audience},
+		"active":    true,
+		"$or": bson.A{
+			bson.M{"events": event.Type},
+			bson.M{"events": bson.M{"$exists": false}},
+		},
+	}
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1, "author_id": 1})
+
+	cursor, err := m.webhooks.Find(ctx, filter, findOptions)
+	if err != nil {
+		fmt.Printf("Ошибка поиска webhook для события %s: %v\n", event.Type, err)
+		return
+	}
+
+	var docs []webhookDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		fmt.Printf("Ошибка поиска webhook для события %s: %v\n", event.Type, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	jobs := make([]models.WebhookJob, 0, len(docs))
+	for _, doc := range docs {
+		jobs = append(jobs, models.WebhookJob{
+			ID:         primitive.NewObjectID().Hex(),
+			WebhookID:  doc.ObjectID.Hex(),
+			Attempt:    1,
+			Event:      event.VisibleTo(doc.AuthorID),
+			EnqueuedAt: now,
+		})
+	}
+
+	if err := m.webhookQueue.Enqueue(jobs...); err != nil {
+		fmt.Printf("Ошибка постановки webhook для заметки %s: %v\n", event.NoteID, err)
+	}
+}
+
+// validateWebhook проверяет поля webhook и то, что его адрес публичный.
+func validateWebhook(webhook models.Webhook) error {
+	if err := models.ValidateWebhook(webhook); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidWebhook, err)
+	}
+	if err := webhooks.CheckURL(webhook.URL); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidWebhook, err)
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCreateRootAndChildNotebook(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	root, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: 1, Name: "Работа"})
+	if err != nil {
+		t.Fatalf("CreateNotebook корневого блокнота: %v", err)
+	}
+	if root.ID == "" || root.ParentID != "" {
+		t.Fatalf("корневой блокнот %+v", root)
+	}
+
+	child, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: 1, Name: "Проекты", ParentID: root.ID})
+	if err != nil {
+		t.Fatalf("CreateNotebook вложенного блокнота: %v", err)
+	}
+	if child.ParentID != root.ID {
+		t.Fatalf("родитель вложенного блокнота %q, ожидался %q", child.ParentID, root.ID)
+	}
+}
+
+func TestMoveNoteBumpsVersion(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	notebook, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: 1, Name: "Работа"})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	note, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "План"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	moved, err := service.MoveNote(ctx, note.ID, notebook.ID, note.Version)
+	if err != nil {
+		t.Fatalf("MoveNote: %v", err)
+	}
+	if moved.NotebookID != notebook.ID || moved.Version != note.Version+1 {
+		t.Fatalf("после переноса заметка в %q версии %d", moved.NotebookID, moved.Version)
+	}
+
+	if _, err := service.MoveNote(ctx, note.ID, "", note.Version); !stdErrors.Is(err, errors.ErrVersionConflict) {
+		t.Fatalf("MoveNote со старой версией: %v, ожидалась ErrVersionConflict", err)
+	}
+}
+
+func TestCascadeDeletePublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	service := NewMemoryService(config.NewConfig())
+
+	notebook, err := service.CreateNotebook(ctx, models.Notebook{AuthorID: 1, Name: "Работа"})
+	if err != nil {
+		t.Fatalf("CreateNotebook: %v", err)
+	}
+	note, err := service.Create(ctx, models.Note{AuthorID: 1, Name: "План", NotebookID: notebook.ID})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := service.ShareNote(ctx, note.ID, models.NoteShare{UserID: 2, Role: models.ShareRoleViewer}); err != nil {
+		t.Fatalf("ShareNote: %v", err)
+	}
+
+	subscription := service.SubscribeEvents(2)
+	defer subscription.Close()
+
+	if err := service.DeleteNotebook(ctx, *notebook, models.NotebookDeleteCascade); err != nil {
+		t.Fatalf("DeleteNotebook: %v", err)
+	}
+
+	select {
+	case event := <-subscription.Events():
+		if event.Type != models.NoteEventDeleted || event.NoteID != note.ID {
+			t.Fatalf("получено событие %s по заметке %s", event.Type, event.NoteID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("пользователь с доступом не получил событие удаления")
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const attachmentColumns = "id, note_id, author_id, filename, content_type, size, uploaded_at"
+
+// Первая половина ключа advisory-блокировки квоты вложений, вторая — автор.
+const attachmentQuotaLockKey = 7_263_102
+
+func (p *PostgresService) UploadAttachment(ctx context.Context, note models.Note, filename string, contentType string, content io.Reader) (*models.Attachment, error) {
+	var used int64
+	err := p.db.QueryRow(ctx, "SELECT COALESCE(SUM(size), 0)::bigint FROM note_attachments WHERE author_id = $1", note.AuthorID).Scan(&used)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	limit := p.attachmentMaxSize
+	limitErr := errors.ErrAttachmentTooLarge
+	if remaining := p.attachmentQuota - used; remaining < limit {
+		limit = remaining
+		limitErr = errors.ErrAttachmentQuota
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: использовано %d из %d байт", errors.ErrAttachmentQuota, used, p.attachmentQuota)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: допустимо не больше %d байт", limitErr, limit)
+	}
+
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID().Hex(),
+		NoteID:      note.ID,
+		AuthorID:    note.AuthorID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		UploadedAt:  time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	// Квота проверялась до чтения файла; перед вставкой объём пересчитывается
+	// под advisory-блокировкой автора, чтобы параллельные загрузки не
+	// превысили её вместе.
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1::integer, $2::integer)", attachmentQuotaLockKey, note.AuthorID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	err = tx.QueryRow(ctx, "SELECT COALESCE(SUM(size), 0)::bigint FROM note_attachments WHERE author_id = $1", note.AuthorID).Scan(&used)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if used+attachment.Size > p.attachmentQuota {
+		return nil, fmt.Errorf("%w: использовано %d из %d байт", errors.ErrAttachmentQuota, used, p.attachmentQuota)
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO note_attachments ("+attachmentColumns+", data) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		attachment.ID, attachment.NoteID, attachment.AuthorID, attachment.Filename, attachment.ContentType,
+		attachment.Size, attachment.UploadedAt, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrAttachmentUpload, err)
+	}
+
+	return &attachment, nil
+}
+
+func (p *PostgresService) GetAttachments(ctx context.Context, noteID string) ([]models.Attachment, error) {
+	rows, err := p.db.Query(ctx, "SELECT "+attachmentColumns+" FROM note_attachments WHERE note_id = $1 ORDER BY uploaded_at, id", noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	attachments := make([]models.Attachment, 0)
+	for rows.Next() {
+		var attachment models.Attachment
+		if err := scanAttachment(rows, &attachment); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return attachments, nil
+}
+
+func (p *PostgresService) OpenAttachment(ctx context.Context, noteID string, attachmentID string) (*models.Attachment, io.ReadSeekCloser, error) {
+	var attachment models.Attachment
+	var data []byte
+	row := p.db.QueryRow(ctx, "SELECT "+attachmentColumns+", data FROM note_attachments WHERE id = $1 AND note_id = $2", attachmentID, noteID)
+	if err := scanAttachment(row, &attachment, &data); err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: %s", errors.ErrAttachmentNotFound, attachmentID)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &attachment, nopCloser{bytes.NewReader(data)}, nil
+}
+
+func (p *PostgresService) DeleteAttachment(ctx context.Context, noteID string, attachmentID string) error {
+	result, err := p.db.Exec(ctx, "DELETE FROM note_attachments WHERE id = $1 AND note_id = $2", attachmentID, noteID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", errors.ErrAttachmentNotFound, attachmentID)
+	}
+
+	return nil
+}
+
+func scanAttachment(row pgx.Row, attachment *models.Attachment, extra ...any) error {
+	dest := []any{&attachment.ID, &attachment.NoteID, &attachment.AuthorID, &attachment.Filename,
+		&attachment.ContentType, &attachment.Size, &attachment.UploadedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	attachment.UploadedAt = attachment.UploadedAt.UTC()
+	return nil
+}
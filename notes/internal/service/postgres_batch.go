@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (p *PostgresService) Batch(ctx context.Context, authorId int, request models.BatchRequest) ([]models.BatchResult, error) {
+	if len(request.Operations) == 0 || len(request.Operations) > models.MaxBatchOperations {
+		return nil, fmt.Errorf("%w: операций %d, допустимо от 1 до %d", errors.ErrInvalidBatch, len(request.Operations), models.MaxBatchOperations)
+	}
+
+	if !request.Atomic {
+		results, changes := p.runBatch(request.Operations, false, func(operation models.BatchOperation) (*models.Note, error) {
+			return p.runBatchOperationTx(ctx, authorId, operation)
+		})
+		p.publishNoteChanges(changes)
+		return results, nil
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	results, changes := p.runBatch(request.Operations, true, func(operation models.BatchOperation) (*models.Note, error) {
+		return p.runBatchOperation(ctx, tx, authorId, operation)
+	})
+	for _, result := range results {
+		if result.Err != nil {
+			return results, fmt.Errorf("%w: операция %d: %v", errors.ErrBatchAborted, result.Index, result.Err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	p.publishNoteChanges(changes)
+
+	return results, nil
+}
+
+func (p *PostgresService) runBatch(operations []models.BatchOperation, stopOnError bool, run func(models.BatchOperation) (*models.Note, error)) ([]models.BatchResult, []noteChange) {
+	results := make([]models.BatchResult, 0, len(operations))
+	changes := make([]noteChange, 0, len(operations))
+	for i, operation := range operations {
+		result := models.BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+
+		note, err := run(operation)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.ID = note.ID
+			if operation.Op != models.BatchOpDelete {
+				result.Note = note
+			}
+			changes = append(changes, noteChange{eventType: batchEventType(operation.Op), note: *note})
+		}
+
+		results = append(results, result)
+		if err != nil && stopOnError {
+			break
+		}
+	}
+	return results, changes
+}
+
+// runBatchOperationTx выполняет операцию неатомарного пакета в отдельной
+// транзакции, чтобы изменение заметки и её ревизия сохранялись вместе.
+func (p *PostgresService) runBatchOperationTx(ctx context.Context, authorID int, operation models.BatchOperation) (*models.Note, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	note, err := p.runBatchOperation(ctx, tx, authorID, operation)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return note, nil
+}
+
+func (p *PostgresService) runBatchOperation(ctx context.Context, q postgresQuerier, authorID int, operation models.BatchOperation) (*models.Note, error) {
+	if !operation.Valid() {
+		return nil, fmt.Errorf("%w: op=%q, id=%q", errors.ErrInvalidBatch, operation.Op, operation.ID)
+	}
+
+	if operation.Op == models.BatchOpCreate {
+		note := operation.Note
+		note.AuthorID = authorID
+
+		tags, err := models.NormalizeTags(note.Tags)
+		if err != nil {
+			return nil, err
+		}
+		note.Tags = tags
+
+		return p.createNote(ctx, q, note)
+	}
+
+	existingNote, err := p.activeNote(ctx, q, operation.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if operation.Op == models.BatchOpDelete {
+		if !existingNote.IsOwner(authorID) {
+			return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrNoteForbidden, operation.ID)
+		}
+		return p.deleteNote(ctx, q, operation.ID, operation.Version)
+	}
+
+	if !existingNote.CanWrite(authorID) {
+		return nil, fmt.Errorf("%w: заметка с ID %s", errors.ErrNoteForbidden, operation.ID)
+	}
+
+	note := operation.Note
+	note.ID = operation.ID
+	note.AuthorID = existingNote.AuthorID
+	note.Version = operation.Version
+
+	tags, err := models.NormalizeTags(note.Tags)
+	if err != nil {
+		return nil, err
+	}
+	note.Tags = tags
+
+	return p.updateNote(ctx, q, note)
+}
+
+func (p *PostgresService) ImportNotes(ctx context.Context, authorId int, request models.ImportRequest) (*models.ImportJob, error) {
+	if len(request.Items) == 0 || len(request.Items) > models.MaxImportItems {
+		return nil, fmt.Errorf("%w: заметок %d, допустимо от 1 до %d", errors.ErrInvalidImport, len(request.Items), models.MaxImportItems)
+	}
+
+	job := &models.ImportJob{
+		ID:        primitive.NewObjectID().Hex(),
+		AuthorID:  authorId,
+		Format:    request.Format,
+		DryRun:    request.DryRun,
+		Status:    models.ImportStatusPending,
+		Total:     len(request.Items),
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if _, err := p.db.Exec(ctx, "DELETE FROM import_jobs WHERE created_at < $1", job.CreatedAt.Add(-importJobTTL)); err != nil {
+		fmt.Printf("Ошибка удаления устаревших задач импорта: %v\n", err)
+	}
+
+	if len(request.Items) <= p.importSyncLimit {
+		p.runImport(ctx, job, request.Items, false)
+		if err := p.saveImportJob(ctx, *job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	if err := p.saveImportJob(ctx, *job); err != nil {
+		return nil, err
+	}
+
+	background := *job
+	started := p.imports.Go(func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, importJobTimeout)
+		defer cancel()
+
+		p.runImport(ctx, &background, request.Items, true)
+
+		saveCtx, cancelSave := saveContext(ctx)
+		defer cancelSave()
+		if err := p.saveImportJob(saveCtx, background); err != nil {
+			fmt.Printf("Ошибка сохранения задачи импорта %s: %v\n", background.ID, err)
+		}
+	})
+	if !started {
+		failImport(job, errors.ErrImportInterrupted)
+		if err := p.saveImportJob(ctx, *job); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+func (p *PostgresService) StopImports() {
+	p.imports.Stop()
+}
+
+func (p *PostgresService) FailStaleImports(ctx context.Context, staleBefore time.Time) (int64, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+	tag, err := p.db.Exec(ctx, `UPDATE import_jobs
+		SET job = job || jsonb_build_object('status', $2::text, 'error', $3::text, 'finished_at', $4::text, 'updated_at', $4::text)
+		WHERE job->>'status' IN ($5, $6)
+		AND COALESCE((job->>'updated_at')::timestamptz, created_at) < $1`,
+		staleBefore, models.ImportStatusFailed, staleImportError(staleBefore).Error(), now,
+		models.ImportStatusPending, models.ImportStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *PostgresService) GetImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	var raw []byte
+	err := p.db.QueryRow(ctx, "SELECT job FROM import_jobs WHERE id = $1 AND created_at >= $2",
+		id, time.Now().Add(-importJobTTL)).Scan(&raw)
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: задача с ID %s не найдена", errors.ErrImportJobNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	var job models.ImportJob
+	if err := json.Unmarshal(raw, &job); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return &job, nil
+}
+
+func (p *PostgresService) runImport(ctx context.Context, job *models.ImportJob, items []models.ImportItem, background bool) {
+	job.Status = models.ImportStatusRunning
+	job.Items = make([]models.ImportItemReport, 0, len(items))
+
+	known := make(map[string]string)
+	err := p.ExportNotes(ctx, job.AuthorID, func(note models.Note) error {
+		known[noteContentHash(note)] = note.ID
+		return nil
+	})
+	if err != nil {
+		failImport(job, err)
+		return
+	}
+
+	lastSave := time.Now()
+	for i, item := range items {
+		if background && interruptedImport(ctx, job) {
+			return
+		}
+
+		report := p.importItem(ctx, job, i, item, known)
+		switch report.Status {
+		case models.ImportItemCreated, models.ImportItemWouldCreate:
+			job.Created++
+		case models.ImportItemDuplicate:
+			job.Duplicates++
+		default:
+			job.Failed++
+		}
+
+		job.Items = append(job.Items, report)
+		job.Processed++
+
+		if background && importProgressDue(job, lastSave) {
+			if err := p.saveImportJob(ctx, *job); err != nil {
+				fmt.Printf("Ошибка сохранения прогресса импорта %s: %v\n", job.ID, err)
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	job.Status = models.ImportStatusDone
+	job.FinishedAt = time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func (p *PostgresService) importItem(ctx context.Context, job *models.ImportJob, index int, item models.ImportItem, known map[string]string) models.ImportItemReport {
+	report := models.ImportItemReport{Index: index, Source: item.Source, Name: item.Note.Name}
+	if item.Error != "" {
+		report.Status = models.ImportItemFailed
+		report.Error = item.Error
+		return report
+	}
+
+	note := item.Note
+	tags, err := models.NormalizeTags(note.Tags)
+	if err != nil {
+		report.Status = models.ImportItemFailed
+		report.Error = err.Error()
+		return report
+	}
+	note.Tags = tags
+
+	hash := noteContentHash(note)
+	if duplicateOf, found := known[hash]; found {
+		report.Status = models.ImportItemDuplicate
+		report.DuplicateOf = duplicateOf
+		return report
+	}
+
+	if job.DryRun {
+		known[hash] = ""
+		report.Status = models.ImportItemWouldCreate
+		return report
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	note.AuthorID = job.AuthorID
+	note.Shares = nil
+	note.DeletedAt = time.Time{}
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = now
+	}
+	if note.UpdatedAt.IsZero() {
+		note.UpdatedAt = note.CreatedAt
+	}
+	if p.checkNotebook(ctx, p.db, job.AuthorID, note.NotebookID) != nil {
+		note.NotebookID = ""
+	}
+
+	createdNote, err := p.insertNote(ctx, p.db, note)
+	if err != nil {
+		report.Status = models.ImportItemFailed
+		report.Error = err.Error()
+		return report
+	}
+
+	known[hash] = createdNote.ID
+	p.publishNoteEvent(models.NoteEventCreated, *createdNote)
+	report.Status = models.ImportItemCreated
+	report.NoteID = createdNote.ID
+	return report
+}
+
+func (p *PostgresService) saveImportJob(ctx context.Context, job models.ImportJob) error {
+	job.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	_, err = p.db.Exec(ctx, `INSERT INTO import_jobs (id, author_id, job, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET job = excluded.job`,
+		job.ID, job.AuthorID, string(jobJSON), job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/events"
+	"notes/internal/models"
+	"time"
+)
+
+func (p *PostgresService) SubscribeEvents(userID int) *events.Subscription {
+	return p.events.Subscribe(userID)
+}
+
+func (p *PostgresService) ReplayEvents(ctx context.Context, userID int, afterID int64) ([]models.NoteEvent, error) {
+	return p.events.Replay(userID, afterID)
+}
+
+func (p *PostgresService) publishNoteEvent(eventType string, note models.Note) {
+	audience := append([]int{note.AuthorID}, note.SharedUserIDs()...)
+	p.publishNoteEventTo(eventType, note, audience)
+}
+
+func (p *PostgresService) publishNoteEventTo(eventType string, note models.Note, audience []int) {
+	event := models.NoteEvent{
+		Type:       eventType,
+		NoteID:     note.ID,
+		AuthorID:   note.AuthorID,
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if eventType != models.NoteEventDeleted {
+		event.Note = &note
+	}
+
+	published, err := p.events.Publish(event, audience)
+	if err != nil {
+		fmt.Printf("Ошибка публикации события %s для заметки %s: %v\n", eventType, note.ID, err)
+	}
+
+	p.enqueueWebhooks(published, audience)
+}
+
+func (p *PostgresService) publishNoteChanges(changes []noteChange) {
+	for _, change := range changes {
+		p.publishNoteEvent(change.eventType, change.note)
+	}
+}
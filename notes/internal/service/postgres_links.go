@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const linkColumns = "token, note_id, author_id, password_hash, protected, expires_at, views, created_at"
+
+func (p *PostgresService) CreateLink(ctx context.Context, note models.Note, request models.CreateLinkRequest) (*models.ShareLink, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if !request.ExpiresAt.IsZero() && !request.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: срок действия уже истёк", errors.ErrInvalidLink)
+	}
+
+	token, err := newLinkToken()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrLinkCreation, err)
+	}
+
+	link := models.ShareLink{
+		Token:     token,
+		NoteID:    note.ID,
+		AuthorID:  note.AuthorID,
+		ExpiresAt: request.ExpiresAt.UTC(),
+		CreatedAt: now,
+	}
+
+	if request.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidLink, err)
+		}
+		link.PasswordHash = string(hash)
+		link.Protected = true
+	}
+
+	_, err = p.db.Exec(ctx, "INSERT INTO note_links ("+linkColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		link.Token, link.NoteID, link.AuthorID, link.PasswordHash, link.Protected,
+		nullTime(link.ExpiresAt), link.Views, link.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrLinkCreation, err)
+	}
+
+	return &link, nil
+}
+
+// Истёкшие ссылки в Mongo удаляет TTL-индекс, здесь они просто не выбираются.
+func (p *PostgresService) GetLinks(ctx context.Context, noteID string) ([]models.ShareLink, error) {
+	rows, err := p.db.Query(ctx, "SELECT "+linkColumns+` FROM note_links
+		WHERE note_id = $1 AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY created_at DESC`, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	links := make([]models.ShareLink, 0)
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return links, nil
+}
+
+func (p *PostgresService) RevokeLink(ctx context.Context, noteID string, token string) error {
+	result, err := p.db.Exec(ctx, "DELETE FROM note_links WHERE note_id = $1 AND token = $2", noteID, token)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+	}
+
+	return nil
+}
+
+func (p *PostgresService) OpenLink(ctx context.Context, token string, password string) (*models.Note, *models.ShareLink, error) {
+	link, err := scanLink(p.db.QueryRow(ctx, "SELECT "+linkColumns+" FROM note_links WHERE token = $1", token))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if link.Expired(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkExpired, link.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if link.Protected {
+		if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+			return nil, nil, fmt.Errorf("%w", errors.ErrLinkPassword)
+		}
+	}
+
+	note, err := p.GetByID(ctx, link.NoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = p.db.QueryRow(ctx, "UPDATE note_links SET views = views + 1 WHERE token = $1 RETURNING views", token).Scan(&link.Views)
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: %s", errors.ErrLinkNotFound, token)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return note, &link, nil
+}
+
+func scanLink(row pgx.Row) (models.ShareLink, error) {
+	var link models.ShareLink
+	var expiresAt *time.Time
+	err := row.Scan(&link.Token, &link.NoteID, &link.AuthorID, &link.PasswordHash, &link.Protected,
+		&expiresAt, &link.Views, &link.CreatedAt)
+	if err != nil {
+		return link, err
+	}
+
+	link.ExpiresAt = timeValue(expiresAt)
+	link.CreatedAt = link.CreatedAt.UTC()
+	return link, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const notebookColumns = "id, name, parent_id, author_id, created_at, updated_at"
+
+func (p *PostgresService) CreateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error) {
+	if !notebook.Valid() {
+		return nil, fmt.Errorf("%w: название %q", errors.ErrInvalidNotebook, notebook.Name)
+	}
+
+	if err := p.checkNotebook(ctx, p.db, notebook.AuthorID, notebook.ParentID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	notebook.ID = primitive.NewObjectID().Hex()
+	notebook.CreatedAt = now
+	notebook.UpdatedAt = now
+
+	_, err := p.db.Exec(ctx, "INSERT INTO notebooks ("+notebookColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+		notebook.ID, notebook.Name, notebook.ParentID, notebook.AuthorID, notebook.CreatedAt, notebook.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &notebook, nil
+}
+
+func (p *PostgresService) GetNotebooks(ctx context.Context, authorId int) ([]models.Notebook, error) {
+	return p.authorNotebooks(ctx, p.db, authorId)
+}
+
+func (p *PostgresService) GetNotebook(ctx context.Context, id string) (*models.Notebook, error) {
+	return p.findNotebook(ctx, p.db, id)
+}
+
+func (p *PostgresService) UpdateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error) {
+	if !notebook.Valid() {
+		return nil, fmt.Errorf("%w: название %q, родитель %q", errors.ErrInvalidNotebook, notebook.Name, notebook.ParentID)
+	}
+
+	if notebook.ParentID != "" {
+		if err := p.checkNotebook(ctx, p.db, notebook.AuthorID, notebook.ParentID); err != nil {
+			return nil, err
+		}
+
+		notebooks, err := p.authorNotebooks(ctx, p.db, notebook.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(models.NotebookDescendants(notebooks, notebook.ID), notebook.ParentID) {
+			return nil, fmt.Errorf("%w: блокнот нельзя вложить в собственный подблокнот", errors.ErrInvalidNotebook)
+		}
+	}
+
+	updated, err := scanNotebook(p.db.QueryRow(ctx, `UPDATE notebooks SET name = $2, parent_id = $3, updated_at = $4
+		WHERE id = $1 RETURNING `+notebookColumns,
+		notebook.ID, notebook.Name, notebook.ParentID, time.Now().UTC().Truncate(time.Millisecond)))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, notebook.ID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &updated, nil
+}
+
+func (p *PostgresService) DeleteNotebook(ctx context.Context, notebook models.Notebook, mode string) error {
+	if !models.ValidNotebookDeleteMode(mode) {
+		return fmt.Errorf("%w: режим удаления %q", errors.ErrInvalidNotebook, mode)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var movedNotes, trashedNotes []models.Note
+	if mode == models.NotebookDeleteReparent {
+		movedNotes, err = p.reparentNotebook(ctx, tx, notebook)
+	} else {
+		trashedNotes, err = p.cascadeNotebook(ctx, tx, notebook)
+	}
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrRevisionSave) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM notebooks WHERE id = $1", notebook.ID); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	for _, note := range movedNotes {
+		p.publishNoteEvent(models.NoteEventUpdated, note)
+	}
+	for _, note := range trashedNotes {
+		p.publishNoteEvent(models.NoteEventDeleted, note)
+	}
+
+	return nil
+}
+
+func (p *PostgresService) MoveNote(ctx context.Context, noteID string, notebookID string, version int) (*models.Note, error) {
+	note, err := p.updateNoteState(ctx, noteID, version, func(q postgresQuerier, note *models.Note) error {
+		if err := p.checkNotebook(ctx, q, note.AuthorID, notebookID); err != nil {
+			return err
+		}
+		note.NotebookID = notebookID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.publishNoteEvent(models.NoteEventUpdated, *note)
+	return note, nil
+}
+
+// reparentNotebook переносит заметки в родительский блокнот так же, как
+// MoveNote: с ревизией, новой версией и updated_at. Возвращает перенесённые
+// заметки для событий; у заметок в корзине меняется только блокнот.
+func (p *PostgresService) reparentNotebook(ctx context.Context, q postgresQuerier, notebook models.Notebook) ([]models.Note, error) {
+	moved, err := p.queryNotes(ctx, q, "SELECT "+noteColumns+` FROM notes
+		WHERE author_id = $1 AND notebook_id = $2 AND deleted_at IS NULL
+		FOR UPDATE`, notebook.AuthorID, notebook.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, note := range moved {
+		if err := p.saveRevision(ctx, q, note); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	_, err = q.Exec(ctx, `UPDATE notes SET notebook_id = $3, version = version + 1, updated_at = $4
+		WHERE author_id = $1 AND notebook_id = $2 AND deleted_at IS NULL`,
+		notebook.AuthorID, notebook.ID, notebook.ParentID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.Exec(ctx, "UPDATE notes SET notebook_id = $3 WHERE author_id = $1 AND notebook_id = $2",
+		notebook.AuthorID, notebook.ID, notebook.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.Exec(ctx, "UPDATE notebooks SET parent_id = $3 WHERE author_id = $1 AND parent_id = $2",
+		notebook.AuthorID, notebook.ID, notebook.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range moved {
+		moved[i].NotebookID = notebook.ParentID
+		moved[i].Version++
+		moved[i].UpdatedAt = now
+	}
+	return moved, nil
+}
+
+// Заметки из удаляемых блокнотов уходят в корзину, а у заметок, уже лежащих
+// в корзине, блокнот сбрасывается. Возвращает заметки, ушедшие в корзину, для
+// событий удаления.
+func (p *PostgresService) cascadeNotebook(ctx context.Context, q postgresQuerier, notebook models.Notebook) ([]models.Note, error) {
+	notebooks, err := p.authorNotebooks(ctx, q, notebook.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	ids := models.NotebookDescendants(notebooks, notebook.ID)
+
+	trashed, err := p.queryNotes(ctx, q, "SELECT "+noteColumns+` FROM notes
+		WHERE author_id = $1 AND notebook_id = ANY($2) AND deleted_at IS NULL
+		FOR UPDATE`, notebook.AuthorID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.Exec(ctx, `UPDATE notes SET deleted_at = COALESCE(deleted_at, $3), notebook_id = ''
+		WHERE author_id = $1 AND notebook_id = ANY($2)`,
+		notebook.AuthorID, ids, time.Now().UTC().Truncate(time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.Exec(ctx, "DELETE FROM notebooks WHERE id = ANY($1)", ids)
+	return trashed, err
+}
+
+func (p *PostgresService) findNotebook(ctx context.Context, q postgresQuerier, id string) (*models.Notebook, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNotebookNotFound, err)
+	}
+
+	notebook, err := scanNotebook(q.QueryRow(ctx, "SELECT "+notebookColumns+" FROM notebooks WHERE id = $1", id))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return &notebook, nil
+}
+
+func (p *PostgresService) authorNotebooks(ctx context.Context, q postgresQuerier, authorID int) ([]models.Notebook, error) {
+	rows, err := q.Query(ctx, "SELECT "+notebookColumns+` FROM notebooks
+		WHERE author_id = $1
+		ORDER BY name COLLATE "C", id`, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	notebooks := make([]models.Notebook, 0)
+	for rows.Next() {
+		notebook, err := scanNotebook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+		}
+		notebooks = append(notebooks, notebook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return notebooks, nil
+}
+
+func (p *PostgresService) checkNotebook(ctx context.Context, q postgresQuerier, authorID int, notebookID string) error {
+	if notebookID == "" {
+		return nil
+	}
+
+	notebook, err := p.findNotebook(ctx, q, notebookID)
+	if err != nil {
+		return err
+	}
+
+	if notebook.AuthorID != authorID {
+		return fmt.Errorf("%w: блокнот с ID %s не найден", errors.ErrNotebookNotFound, notebookID)
+	}
+
+	return nil
+}
+
+func (p *PostgresService) notebookFilterIDs(ctx context.Context, authorID int, query models.NoteQuery) ([]string, error) {
+	if err := p.checkNotebook(ctx, p.db, authorID, query.NotebookID); err != nil {
+		return nil, err
+	}
+
+	if !query.IncludeSubnotebooks {
+		return []string{query.NotebookID}, nil
+	}
+
+	notebooks, err := p.authorNotebooks(ctx, p.db, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NotebookDescendants(notebooks, query.NotebookID), nil
+}
+
+func scanNotebook(row pgx.Row) (models.Notebook, error) {
+	var notebook models.Notebook
+	err := row.Scan(&notebook.ID, &notebook.Name, &notebook.ParentID, &notebook.AuthorID, &notebook.CreatedAt, &notebook.UpdatedAt)
+	notebook.CreatedAt = notebook.CreatedAt.UTC()
+	notebook.UpdatedAt = notebook.UpdatedAt.UTC()
+	return notebook, err
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (p *PostgresService) SetReminder(ctx context.Context, noteID string, request models.ReminderRequest, version int) (*models.Note, error) {
+	reminder := models.Note{RemindAt: request.RemindAt, DueAt: request.DueAt, Recurrence: request.Recurrence}
+	if err := models.ValidateReminder(reminder); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReminder, err)
+	}
+
+	return p.setNoteState(ctx, noteID, version, func(note *models.Note) error {
+		note.RemindAt = reminderTime(request.RemindAt)
+		note.DueAt = reminderTime(request.DueAt)
+		note.Recurrence = request.Recurrence
+		return nil
+	})
+}
+
+func (p *PostgresService) GetUpcomingReminders(ctx context.Context, authorId int, until time.Time, limit int) ([]models.Reminder, error) {
+	where := &sqlConditions{}
+	where.add("author_id = ?", authorId)
+	where.add("deleted_at IS NULL")
+	where.add("remind_at IS NOT NULL")
+	if !until.IsZero() {
+		where.add("remind_at <= ?", until)
+	}
+
+	sql := "SELECT " + noteColumns + " FROM notes WHERE " + where.sql() + " ORDER BY remind_at, id"
+	if limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	notes, err := p.queryNotes(ctx, p.db, sql, where.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]models.Reminder, 0, len(notes))
+	for _, note := range notes {
+		reminder := models.ReminderFromNote(note)
+		if next, ok := nextReminder(note, note.RemindAt); ok {
+			reminder.NextRemindAt = next
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+// ClaimDueReminders забирает наступившие напоминания в транзакции: строки
+// блокируются через SKIP LOCKED, поэтому параллельные реплики получают разные
+// напоминания и аренда, как в Mongo, не нужна. Забранное напоминание
+// помечается доставляемым вместе с токеном, а remind_at сдвигает
+// CompleteReminder, если токен не сменился.
+func (p *PostgresService) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]models.Reminder, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	due, err := p.queryNotes(ctx, tx, "SELECT "+noteColumns+` FROM notes
+		WHERE deleted_at IS NULL AND remind_at <= $1
+			AND (reminder_claimed_until IS NULL OR reminder_claimed_until <= $1)
+		ORDER BY remind_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	claimToken := primitive.NewObjectID().Hex()
+	reminders := make([]models.Reminder, 0, len(due))
+	ids := make([]string, 0, len(due))
+	for _, note := range due {
+		reminder := models.ReminderFromNote(note)
+		if next, ok := nextReminder(note, now); ok {
+			reminder.NextRemindAt = next
+		}
+		reminder.ClaimToken = claimToken
+
+		reminders = append(reminders, reminder)
+		ids = append(ids, note.ID)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE notes SET reminder_claimed_until = $2, reminder_claim_token = $3 WHERE id = ANY($1)",
+		ids, now.Add(reminderClaimTTL), claimToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return reminders, nil
+}
+
+func (p *PostgresService) CompleteReminder(ctx context.Context, reminder models.Reminder) error {
+	dueAt := reminder.DueAt
+	if !dueAt.IsZero() && !reminder.NextRemindAt.IsZero() {
+		dueAt = dueAt.Add(reminder.NextRemindAt.Sub(reminder.RemindAt))
+	}
+
+	_, err := p.db.Exec(ctx, `UPDATE notes SET remind_at = $3, due_at = $4,
+		reminder_claimed_until = NULL, reminder_claim_token = NULL
+		WHERE id = $1 AND remind_at = $2 AND reminder_claim_token = $5`,
+		reminder.NoteID, reminder.RemindAt, nullTime(reminder.NextRemindAt), nullTime(dueAt), reminder.ClaimToken)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (p *PostgresService) DeliverToInbox(ctx context.Context, reminder models.Reminder) error {
+	item := models.InboxItem{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    reminder.AuthorID,
+		Reminder:  reminder,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	reminderJSON, err := json.Marshal(item.Reminder)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	if _, err := p.db.Exec(ctx, "DELETE FROM reminder_inbox WHERE created_at < $1", item.CreatedAt.Add(-inboxTTL)); err != nil {
+		fmt.Printf("Ошибка удаления устаревших напоминаний: %v\n", err)
+	}
+
+	_, err = p.db.Exec(ctx, "INSERT INTO reminder_inbox (id, user_id, reminder, created_at) VALUES ($1, $2, $3, $4)",
+		item.ID, item.UserID, string(reminderJSON), item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (p *PostgresService) GetInbox(ctx context.Context, userID int, limit int) ([]models.InboxItem, error) {
+	sql := `SELECT id, user_id, reminder, created_at FROM reminder_inbox
+		WHERE user_id = $1 AND created_at >= $2
+		ORDER BY created_at DESC, id DESC`
+	if limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := p.db.Query(ctx, sql, userID, time.Now().Add(-inboxTTL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	items := make([]models.InboxItem, 0)
+	for rows.Next() {
+		var item models.InboxItem
+		var reminder []byte
+		if err := rows.Scan(&item.ID, &item.UserID, &reminder, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+		}
+		if err := json.Unmarshal(reminder, &item.Reminder); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+		}
+		item.CreatedAt = item.CreatedAt.UTC()
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const revisionColumns = "note_id, version, name, content, tags, author_id, updated_at"
+
+func (p *PostgresService) GetRevisions(ctx context.Context, noteID string) ([]models.NoteRevision, error) {
+	rows, err := p.db.Query(ctx, `SELECT note_id, version, name, '', tags, author_id, updated_at
+		FROM note_revisions WHERE note_id = $1 ORDER BY version DESC`, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.NoteRevision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return revisions, nil
+}
+
+func (p *PostgresService) GetRevision(ctx context.Context, noteID string, version int) (*models.NoteRevision, error) {
+	if version <= 0 {
+		return nil, fmt.Errorf("%w: %d", errors.ErrInvalidRevision, version)
+	}
+
+	note, err := p.GetByID(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if note.Version == version {
+		revision := models.RevisionFromNote(*note)
+		return &revision, nil
+	}
+
+	revision, err := scanRevision(p.db.QueryRow(ctx,
+		"SELECT "+revisionColumns+" FROM note_revisions WHERE note_id = $1 AND version = $2", noteID, version))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: версия %d заметки %s", errors.ErrRevisionNotFound, version, noteID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &revision, nil
+}
+
+func (p *PostgresService) RestoreRevision(ctx context.Context, noteID string, version int, expectedVersion int) (*models.Note, error) {
+	revision, err := p.GetRevision(ctx, noteID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Update(ctx, models.Note{
+		ID:       noteID,
+		Name:     revision.Name,
+		Content:  revision.Content,
+		Tags:     revision.Tags,
+		AuthorID: revision.AuthorID,
+		Version:  expectedVersion,
+	})
+}
+
+// saveRevision сохраняет прежнюю версию заметки и оставляет не больше
+// revisionsLimit последних ревизий.
+func (p *PostgresService) saveRevision(ctx context.Context, q postgresQuerier, note models.Note) error {
+	revision := models.RevisionFromNote(note)
+	_, err := q.Exec(ctx, `INSERT INTO note_revisions (`+revisionColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (note_id, version) DO NOTHING`,
+		revision.NoteID, revision.Version, revision.Name, revision.Content, textArray(revision.Tags), revision.AuthorID, revision.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrRevisionSave, err)
+	}
+
+	if p.revisionsLimit <= 0 {
+		return nil
+	}
+
+	_, err = q.Exec(ctx, `DELETE FROM note_revisions WHERE note_id = $1 AND version < (
+			SELECT version FROM note_revisions WHERE note_id = $1 ORDER BY version DESC OFFSET $2 LIMIT 1
+		)`, note.ID, p.revisionsLimit-1)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return nil
+}
+
+func scanRevision(row pgx.Row) (models.NoteRevision, error) {
+	var revision models.NoteRevision
+	err := row.Scan(&revision.NoteID, &revision.Version, &revision.Name, &revision.Content,
+		&revision.Tags, &revision.AuthorID, &revision.UpdatedAt)
+	if len(revision.Tags) == 0 {
+		revision.Tags = nil
+	}
+	revision.UpdatedAt = revision.UpdatedAt.UTC()
+	return revision, err
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"strings"
+)
+
+const postgresTextConfig = "russian"
+
+// Search ищет по search_vector. Как и $text в Mongo, заметке достаточно
+// одного из слов, а слова с минусом исключают её; оба списка передаются в
+// websearch_to_tsquery через or, поэтому стоп-слова и пунктуацию разбирает
+// сам Postgres.
+func (p *PostgresService) Search(ctx context.Context, authorId int, query models.SearchQuery) ([]models.SearchResult, error) {
+	query.Normalize()
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: пустой поисковый запрос", errors.ErrInvalidQuery)
+	}
+
+	included, excluded := splitSearchText(query.Text)
+
+	rows, err := p.db.Query(ctx, `SELECT `+noteColumns+`, ts_rank(search_vector, query)::float8 AS score
+		FROM notes, (SELECT CASE WHEN $2 = '' THEN websearch_to_tsquery('`+postgresTextConfig+`', $3)
+			ELSE websearch_to_tsquery('`+postgresTextConfig+`', $3) && !! websearch_to_tsquery('`+postgresTextConfig+`', $2)
+			END AS query) AS search
+		WHERE author_id = $1 AND deleted_at IS NULL AND search_vector @@ query
+		ORDER BY score DESC, id DESC
+		LIMIT $4`,
+		authorId, excluded, included, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	terms := searchTerms(query.Text)
+	results := make([]models.SearchResult, 0, query.Limit)
+	for rows.Next() {
+		var result models.SearchResult
+		note, err := scanNote(scoredRow{row: rows, score: &result.Score})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+
+		result.Note = note
+		result.Highlights = highlightFields(note, terms)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return results, nil
+}
+
+func (p *PostgresService) GetTags(ctx context.Context, authorId int) ([]models.TagCount, error) {
+	rows, err := p.db.Query(ctx, `SELECT tag, count(*) FROM notes, unnest(tags) AS tag
+		WHERE author_id = $1 AND deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY count(*) DESC, tag COLLATE "C"`, authorId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.TagCount, 0)
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+
+	return tags, nil
+}
+
+// splitSearchText делит запрос на искомые слова и слова с минусом, каждые
+// через or. Кавычки убираются, поэтому фраза ищется как отдельные слова.
+func splitSearchText(text string) (string, string) {
+	var included, excluded []string
+	for _, field := range strings.Fields(strings.ReplaceAll(text, `"`, " ")) {
+		if word, ok := strings.CutPrefix(field, "-"); ok {
+			if word != "" {
+				excluded = append(excluded, word)
+			}
+			continue
+		}
+		included = append(included, field)
+	}
+	return strings.Join(included, " or "), strings.Join(excluded, " or ")
+}
+
+// scoredRow дочитывает колонку score после колонок заметки.
+type scoredRow struct {
+	row   interface{ Scan(dest ...any) error }
+	score *float64
+}
+
+func (r scoredRow) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, r.score)...)
+}
@@ -0,0 +1,517 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/config"
+	"notes/internal/database"
+	"notes/internal/errors"
+	"notes/internal/events"
+	"notes/internal/models"
+	"notes/internal/webhooks"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostgresService хранит заметки в PostgreSQL. Поведение то же, что у
+// MongoService: ID в формате ObjectID, те же ошибки и правила доступа, а
+// поиск идёт по tsvector с теми же весами названия и содержимого.
+//
+// Кэш не используется: точечные чтения по первичному ключу и так дёшевы.
+// События SSE живут в памяти реплики (Redis не нужен), а очередь webhook и
+// выдача напоминаний работают через таблицы и FOR UPDATE SKIP LOCKED, поэтому
+// безопасны и при нескольких репликах.
+type PostgresService struct {
+	db             *pgxpool.Pool
+	events         *events.Broker
+	revisionsLimit int
+
+	attachmentMaxSize int64
+	attachmentQuota   int64
+	importSyncLimit   int
+	imports           *importRunner
+
+	webhookQueue        *webhooks.PostgresQueue
+	webhookMaxAttempts  int
+	webhookDisableAfter int
+}
+
+// postgresQuerier — общее у пула и транзакции: атомарный пакет выполняет те
+// же запросы внутри транзакции.
+type postgresQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const noteColumns = "id, author_id, name, content, tags, notebook_id, pinned, archived, shares, remind_at, due_at, recurrence, created_at, updated_at, version, deleted_at"
+
+var _ Service = (*PostgresService)(nil)
+
+func newPostgresService(cfg *config.Config) (*PostgresService, error) {
+	db, err := database.NewPostgres(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresService{
+		db:             db,
+		events:         events.NewLocalBroker(cfg.EventsReplayLimit),
+		revisionsLimit: cfg.RevisionsLimit,
+
+		attachmentMaxSize: int64(cfg.AttachmentMaxSizeMB) << 20,
+		attachmentQuota:   int64(cfg.AttachmentsUserQuotaMB) << 20,
+		importSyncLimit:   cfg.ImportSyncLimit,
+		imports:           newImportRunner(),
+
+		webhookQueue:        webhooks.NewPostgresQueue(db),
+		webhookMaxAttempts:  cfg.WebhookMaxAttempts,
+		webhookDisableAfter: cfg.WebhookDisableAfterFailures,
+	}, nil
+}
+
+func (p *PostgresService) Create(ctx context.Context, note models.Note) (*models.Note, error) {
+	createdNote, err := p.createNote(ctx, p.db, note)
+	if err != nil {
+		return nil, err
+	}
+
+	p.publishNoteEvent(models.NoteEventCreated, *createdNote)
+
+	return createdNote, nil
+}
+
+func (p *PostgresService) createNote(ctx context.Context, q postgresQuerier, note models.Note) (*models.Note, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	note.Shares = nil
+	note.DeletedAt = time.Time{}
+	note.CreatedAt = now
+	note.UpdatedAt = now
+
+	return p.insertNote(ctx, q, note)
+}
+
+func (p *PostgresService) insertNote(ctx context.Context, q postgresQuerier, note models.Note) (*models.Note, error) {
+	note.Version = 1
+
+	if err := models.ValidateReminder(note); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReminder, err)
+	}
+
+	if err := p.checkNotebook(ctx, q, note.AuthorID, note.NotebookID); err != nil {
+		return nil, err
+	}
+
+	if note.Archived {
+		note.Pinned = false
+	}
+
+	note.ID = primitive.NewObjectID().Hex()
+	_, err := q.Exec(ctx, `INSERT INTO notes
+		(id, author_id, name, content, tags, notebook_id, pinned, archived, remind_at, due_at, recurrence, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		note.ID, note.AuthorID, note.Name, note.Content, textArray(note.Tags), note.NotebookID, note.Pinned, note.Archived,
+		nullTime(note.RemindAt), nullTime(note.DueAt), note.Recurrence, note.CreatedAt, note.UpdatedAt, note.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteCreation, err)
+	}
+
+	return &note, nil
+}
+
+func (p *PostgresService) GetByID(ctx context.Context, id string) (*models.Note, error) {
+	note, err := p.activeNote(ctx, p.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// activeNote возвращает заметку не из корзины; forUpdate блокирует строку
+// до конца транзакции.
+func (p *PostgresService) activeNote(ctx context.Context, q postgresQuerier, id string, forUpdate bool) (models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return models.Note{}, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	query := "SELECT " + noteColumns + " FROM notes WHERE id = $1 AND deleted_at IS NULL"
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	note, err := scanNote(q.QueryRow(ctx, query, id))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return models.Note{}, fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
+		}
+		return models.Note{}, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return note, nil
+}
+
+func (p *PostgresService) GetAll(ctx context.Context, authorId int, query models.NoteQuery) (*models.NotesPage, error) {
+	query.Normalize()
+	if !query.Valid() {
+		return nil, fmt.Errorf("%w: сортировка %s %s", errors.ErrInvalidQuery, query.SortBy, query.Order)
+	}
+
+	where := &sqlConditions{}
+	where.add("author_id = ?", authorId)
+	where.add("deleted_at IS NULL")
+	where.add("archived = ?", query.Archived)
+
+	if query.Name != "" {
+		where.add(`name ILIKE ? ESCAPE '\'`, "%"+escapeLike(query.Name)+"%")
+	}
+
+	if len(query.Tags) > 0 {
+		if query.TagMode == models.TagModeAny {
+			where.add("tags && ?", query.Tags)
+		} else {
+			where.add("tags @> ?", query.Tags)
+		}
+	}
+
+	if query.NotebookID != "" {
+		notebookIDs, err := p.notebookFilterIDs(ctx, authorId, query)
+		if err != nil {
+			return nil, err
+		}
+		where.add("notebook_id = ANY(?)", notebookIDs)
+	}
+
+	if query.Cursor != "" {
+		if err := addCursorCondition(where, query); err != nil {
+			return nil, err
+		}
+	}
+
+	direction := "ASC"
+	if query.Order == models.SortOrderDesc {
+		direction = "DESC"
+	}
+	field := postgresSortField(query.SortBy)
+	sql := fmt.Sprintf("SELECT %s FROM notes WHERE %s ORDER BY pinned DESC, %s %s, id %s LIMIT %d",
+		noteColumns, where.sql(), field, direction, direction, query.Limit+1)
+
+	notes, err := p.queryNotes(ctx, p.db, sql, where.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotesPage{Notes: notes}
+	if len(notes) > query.Limit {
+		page.Notes = notes[:query.Limit]
+		page.NextCursor = encodeCursor(cursorForNote(page.Notes[query.Limit-1], query.SortBy))
+	}
+
+	return page, nil
+}
+
+func (p *PostgresService) Update(ctx context.Context, note models.Note) (*models.Note, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	updatedNote, err := p.updateNote(ctx, tx, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	p.publishNoteEvent(models.NoteEventUpdated, *updatedNote)
+
+	return updatedNote, nil
+}
+
+// updateNote меняет заметку и сохраняет прежнюю версию в ревизии. Ошибка
+// ревизии возвращается, а не логируется: внутри транзакции после неё
+// PostgreSQL всё равно отвергнет остальные запросы, поэтому вызывающий
+// откатывает транзакцию целиком.
+func (p *PostgresService) updateNote(ctx context.Context, q postgresQuerier, note models.Note) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(note.ID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	existingNote, err := scanNote(q.QueryRow(ctx, `WITH existing AS (
+			SELECT `+noteColumns+` FROM notes
+			WHERE id = $1 AND deleted_at IS NULL AND ($2 <= 0 OR version = $2)
+			FOR UPDATE
+		)
+		UPDATE notes SET name = $3, content = $4, tags = $5, updated_at = $6, version = notes.version + 1
+		FROM existing WHERE notes.id = existing.id
+		RETURNING `+prefixedColumns("existing", noteColumns),
+		note.ID, note.Version, note.Name, note.Content, textArray(note.Tags), note.UpdatedAt))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, p.missedWriteError(ctx, q, note.ID, errors.ErrNoteUpdate)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	if err := p.saveRevision(ctx, q, existingNote); err != nil {
+		return nil, err
+	}
+
+	updatedNote := existingNote
+	updatedNote.Name = note.Name
+	updatedNote.Content = note.Content
+	updatedNote.Tags = note.Tags
+	updatedNote.UpdatedAt = note.UpdatedAt
+	updatedNote.Version = existingNote.Version + 1
+
+	return &updatedNote, nil
+}
+
+func (p *PostgresService) Delete(ctx context.Context, id string, version int) error {
+	deletedNote, err := p.deleteNote(ctx, p.db, id, version)
+	if err != nil {
+		return err
+	}
+
+	p.publishNoteEvent(models.NoteEventDeleted, *deletedNote)
+
+	return nil
+}
+
+func (p *PostgresService) deleteNote(ctx context.Context, q postgresQuerier, id string, version int) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	deletedNote, err := scanNote(q.QueryRow(ctx, `UPDATE notes SET deleted_at = $3
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 <= 0 OR version = $2)
+		RETURNING `+noteColumns,
+		id, version, time.Now().UTC().Truncate(time.Millisecond)))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, p.missedWriteError(ctx, q, id, errors.ErrNoteDeletion)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteDeletion, err)
+	}
+
+	return &deletedNote, nil
+}
+
+func (p *PostgresService) missedWriteError(ctx context.Context, q postgresQuerier, id string, operationErr error) error {
+	var exists bool
+	err := q.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM notes WHERE id = $1 AND deleted_at IS NULL)", id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%w: %v", operationErr, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: заметка с ID %s не найдена", errors.ErrNoteNotFound, id)
+	}
+
+	return fmt.Errorf("%w: заметка с ID %s была изменена", errors.ErrVersionConflict, id)
+}
+
+func (p *PostgresService) ExportNotes(ctx context.Context, authorId int, write func(models.Note) error) error {
+	rows, err := p.db.Query(ctx, "SELECT "+noteColumns+" FROM notes WHERE author_id = $1 AND deleted_at IS NULL ORDER BY id", authorId)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		if err := write(note); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+	return nil
+}
+
+func (p *PostgresService) Close() error {
+	if err := p.events.Close(); err != nil {
+		fmt.Printf("Ошибка закрытия подписки на события: %v\n", err)
+	}
+	p.db.Close()
+	return nil
+}
+
+func (p *PostgresService) queryNotes(ctx context.Context, q postgresQuerier, sql string, args ...any) ([]models.Note, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	notes := make([]models.Note, 0)
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDecodeNote, err)
+		}
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrIterationNotes, err)
+	}
+	return notes, nil
+}
+
+// scanNote читает строку с колонками noteColumns.
+func scanNote(row pgx.Row) (models.Note, error) {
+	var note models.Note
+	var shares []byte
+	var remindAt, dueAt, deletedAt *time.Time
+
+	err := row.Scan(&note.ID, &note.AuthorID, &note.Name, &note.Content, &note.Tags, &note.NotebookID,
+		&note.Pinned, &note.Archived, &shares, &remindAt, &dueAt, &note.Recurrence,
+		&note.CreatedAt, &note.UpdatedAt, &note.Version, &deletedAt)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	if err := json.Unmarshal(shares, &note.Shares); err != nil {
+		return models.Note{}, err
+	}
+	if len(note.Tags) == 0 {
+		note.Tags = nil
+	}
+	if len(note.Shares) == 0 {
+		note.Shares = nil
+	}
+
+	note.RemindAt = timeValue(remindAt)
+	note.DueAt = timeValue(dueAt)
+	note.DeletedAt = timeValue(deletedAt)
+	note.CreatedAt = note.CreatedAt.UTC()
+	note.UpdatedAt = note.UpdatedAt.UTC()
+
+	return note, nil
+}
+
+// addCursorCondition повторяет условие курсора из listFilter: закреплённые
+// заметки идут первыми, поэтому после закреплённой страницы продолжаем и по
+// незакреплённым.
+func addCursorCondition(where *sqlConditions, query models.NoteQuery) error {
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return err
+	}
+
+	if _, err := primitive.ObjectIDFromHex(cursor.ID); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+	}
+
+	var value any = cursor.Value
+	if query.SortBy != models.SortByName {
+		parsed, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrInvalidCursor, err)
+		}
+		value = parsed
+	}
+
+	op := ">"
+	if query.Order == models.SortOrderDesc {
+		op = "<"
+	}
+
+	field := postgresSortField(query.SortBy)
+	after := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", field, op, field, op)
+	if cursor.Pinned {
+		where.add("((pinned AND "+after+") OR NOT pinned)", value, value, cursor.ID)
+	} else {
+		where.add("NOT pinned AND "+after, value, value, cursor.ID)
+	}
+
+	return nil
+}
+
+// Название сравнивается побайтно, как в Mongo, а не по правилам локали базы.
+func postgresSortField(sortBy string) string {
+	switch sortBy {
+	case models.SortByName:
+		return `(name COLLATE "C")`
+	case models.SortByUpdated:
+		return "updated_at"
+	default:
+		return "created_at"
+	}
+}
+
+// sqlConditions собирает WHERE из условий с плейсхолдерами ?, которые
+// нумеруются по порядку аргументов.
+type sqlConditions struct {
+	parts []string
+	args  []any
+}
+
+func (c *sqlConditions) add(condition string, args ...any) {
+	var builder strings.Builder
+	next := 0
+	for _, char := range condition {
+		if char == '?' && next < len(args) {
+			c.args = append(c.args, args[next])
+			next++
+			builder.WriteString("$" + strconv.Itoa(len(c.args)))
+			continue
+		}
+		builder.WriteRune(char)
+	}
+	c.parts = append(c.parts, builder.String())
+}
+
+func (c *sqlConditions) sql() string {
+	return strings.Join(c.parts, " AND ")
+}
+
+func prefixedColumns(alias string, columns string) string {
+	names := strings.Split(columns, ", ")
+	for i, name := range names {
+		names[i] = alias + "." + name
+	}
+	return strings.Join(names, ", ")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// textArray не даёт nil-срезу превратиться в NULL в колонке text[] NOT NULL.
+func textArray(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nullTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
+
+func timeValue(value *time.Time) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	return value.UTC()
+}
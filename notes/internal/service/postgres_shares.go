@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (p *PostgresService) ShareNote(ctx context.Context, noteID string, share models.NoteShare) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(noteID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	if !share.Valid() {
+		return nil, fmt.Errorf("%w: пользователь %d, роль %q", errors.ErrInvalidShare, share.UserID, share.Role)
+	}
+
+	note, err := p.updateNoteState(ctx, noteID, 0, func(q postgresQuerier, note *models.Note) error {
+		index := slices.IndexFunc(note.Shares, func(existing models.NoteShare) bool {
+			return existing.UserID == share.UserID
+		})
+		if index >= 0 {
+			note.Shares[index].Role = share.Role
+		} else {
+			note.Shares = append(note.Shares, share)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.publishNoteEvent(models.NoteEventUpdated, *note)
+
+	return note, nil
+}
+
+func (p *PostgresService) UnshareNote(ctx context.Context, noteID string, userID int) (*models.Note, error) {
+	note, err := p.updateNoteState(ctx, noteID, 0, func(q postgresQuerier, note *models.Note) error {
+		note.Shares = slices.DeleteFunc(note.Shares, func(share models.NoteShare) bool {
+			return share.UserID == userID
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.publishNoteEvent(models.NoteEventUpdated, *note)
+	p.publishNoteEventTo(models.NoteEventDeleted, *note, []int{userID})
+
+	return note, nil
+}
+
+func (p *PostgresService) GetShared(ctx context.Context, userID int) ([]models.Note, error) {
+	return p.queryNotes(ctx, p.db, "SELECT "+noteColumns+` FROM notes
+		WHERE shares @> jsonb_build_array(jsonb_build_object('user_id', $1::integer)) AND deleted_at IS NULL
+		ORDER BY updated_at DESC, id DESC`, userID)
+}
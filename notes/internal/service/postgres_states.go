@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (p *PostgresService) SetPinned(ctx context.Context, noteID string, pinned bool, version int) (*models.Note, error) {
+	return p.setNoteState(ctx, noteID, version, func(note *models.Note) error {
+		if pinned && note.Archived {
+			return fmt.Errorf("%w: заметка с ID %s", errors.ErrPinArchived, noteID)
+		}
+		note.Pinned = pinned
+		return nil
+	})
+}
+
+func (p *PostgresService) SetArchived(ctx context.Context, noteID string, archived bool, version int) (*models.Note, error) {
+	return p.setNoteState(ctx, noteID, version, func(note *models.Note) error {
+		note.Archived = archived
+		if archived {
+			note.Pinned = false
+		}
+		return nil
+	})
+}
+
+// setNoteState меняет закрепление, архив или напоминание: версия и
+// updated_at растут, как в Mongo.
+func (p *PostgresService) setNoteState(ctx context.Context, noteID string, version int, update func(note *models.Note) error) (*models.Note, error) {
+	note, err := p.updateNoteState(ctx, noteID, version, func(q postgresQuerier, note *models.Note) error {
+		return update(note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.publishNoteEvent(models.NoteEventUpdated, *note)
+
+	return note, nil
+}
+
+// updateNoteState меняет служебные поля заметки (доступ, блокнот, закрепление,
+// архив, напоминание) под блокировкой строки. version > 0 — ожидаемая версия,
+// как в Update. Версия и updated_at растут, прежняя версия сохраняется в
+// ревизии.
+func (p *PostgresService) updateNoteState(ctx context.Context, noteID string, version int, update func(q postgresQuerier, note *models.Note) error) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(noteID); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	note, err := p.activeNote(ctx, tx, noteID, true)
+	if err != nil {
+		return nil, err
+	}
+	if version > 0 && note.Version != version {
+		return nil, fmt.Errorf("%w: заметка с ID %s была изменена", errors.ErrVersionConflict, noteID)
+	}
+
+	if err := p.saveRevision(ctx, tx, note); err != nil {
+		return nil, err
+	}
+
+	if err := update(tx, &note); err != nil {
+		return nil, err
+	}
+	note.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	note.Version++
+
+	shares, err := json.Marshal(note.Shares)
+	if err != nil || note.Shares == nil {
+		shares = []byte("[]")
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE notes SET shares = $2, notebook_id = $3, pinned = $4, archived = $5,
+		remind_at = $6, due_at = $7, recurrence = $8,
+		reminder_claimed_until = CASE WHEN remind_at IS DISTINCT FROM $6 THEN NULL ELSE reminder_claimed_until END,
+		reminder_claim_token = CASE WHEN remind_at IS DISTINCT FROM $6 THEN NULL ELSE reminder_claim_token END,
+		updated_at = $9, version = $10
+		WHERE id = $1`,
+		note.ID, string(shares), note.NotebookID, note.Pinned, note.Archived,
+		nullTime(note.RemindAt), nullTime(note.DueAt), note.Recurrence, note.UpdatedAt, note.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	if len(note.Shares) == 0 {
+		note.Shares = nil
+	}
+	return &note, nil
+}
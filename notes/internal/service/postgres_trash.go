@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Заметка удаляется из корзины вместе с ревизиями, ссылками и вложениями
+// одним запросом.
+const purgeNotesQuery = `WITH purged AS (
+		DELETE FROM notes WHERE deleted_at IS NOT NULL AND %s RETURNING id
+	), revisions AS (
+		DELETE FROM note_revisions WHERE note_id IN (SELECT id FROM purged)
+	), links AS (
+		DELETE FROM note_links WHERE note_id IN (SELECT id FROM purged)
+	), attachments AS (
+		DELETE FROM note_attachments WHERE note_id IN (SELECT id FROM purged)
+	)
+	SELECT count(*) FROM purged`
+
+func (p *PostgresService) GetTrash(ctx context.Context, authorId int) ([]models.Note, error) {
+	return p.queryNotes(ctx, p.db, "SELECT "+noteColumns+` FROM notes
+		WHERE author_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC`, authorId)
+}
+
+func (p *PostgresService) GetTrashedByID(ctx context.Context, id string) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	note, err := scanNote(p.db.QueryRow(ctx, "SELECT "+noteColumns+" FROM notes WHERE id = $1 AND deleted_at IS NOT NULL", id))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &note, nil
+}
+
+func (p *PostgresService) RestoreFromTrash(ctx context.Context, id string) (*models.Note, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback(ctx)
+
+	trashed, err := scanNote(tx.QueryRow(ctx, "SELECT "+noteColumns+" FROM notes WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE", id))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	// Восстановление меняет версию, как в Mongo.
+	if err := p.saveRevision(ctx, tx, trashed); err != nil {
+		return nil, err
+	}
+
+	note, err := scanNote(tx.QueryRow(ctx, "UPDATE notes SET deleted_at = NULL, version = version + 1 WHERE id = $1 RETURNING "+noteColumns, id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrNoteUpdate, err)
+	}
+
+	p.publishNoteEvent(models.NoteEventCreated, note)
+
+	return &note, nil
+}
+
+func (p *PostgresService) DeletePermanently(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidNoteID, err)
+	}
+
+	var purged int64
+	if err := p.db.QueryRow(ctx, fmt.Sprintf(purgeNotesQuery, "id = $1"), id).Scan(&purged); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrNoteDeletion, err)
+	}
+
+	if purged == 0 {
+		return fmt.Errorf("%w: заметка с ID %s не найдена в корзине", errors.ErrNoteNotFound, id)
+	}
+
+	return nil
+}
+
+func (p *PostgresService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	if err := p.db.QueryRow(ctx, fmt.Sprintf(purgeNotesQuery, "deleted_at <= $1"), deletedBefore).Scan(&purged); err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return purged, nil
+}
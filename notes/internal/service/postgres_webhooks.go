@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"notes/internal/errors"
+	"notes/internal/models"
+	"notes/internal/webhooks"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const webhookColumns = "id, author_id, url, events, secret, active, consecutive_failures, last_delivery_at, disabled_at, created_at, updated_at"
+
+func (p *PostgresService) CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := p.db.QueryRow(ctx, "SELECT count(*) FROM webhooks WHERE author_id = $1", webhook.AuthorID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if count >= models.MaxWebhooksPerUser {
+		return nil, fmt.Errorf("%w: не больше %d на пользователя", errors.ErrWebhookLimit, models.MaxWebhooksPerUser)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebhookCreation, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.Secret = secret
+	webhook.Active = true
+	webhook.ConsecutiveFailures = 0
+	webhook.LastDeliveryAt = time.Time{}
+	webhook.DisabledAt = time.Time{}
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	_, err = p.db.Exec(ctx, "INSERT INTO webhooks ("+webhookColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		webhook.ID, webhook.AuthorID, webhook.URL, textArray(webhook.Events), webhook.Secret, webhook.Active,
+		webhook.ConsecutiveFailures, nil, nil, webhook.CreatedAt, webhook.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return &webhook, nil
+}
+
+func (p *PostgresService) GetWebhooks(ctx context.Context, authorId int) ([]models.Webhook, error) {
+	rows, err := p.db.Query(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE author_id = $1 ORDER BY created_at, id", authorId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	result := make([]models.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		}
+		result = append(result, webhook.Redacted())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return result, nil
+}
+
+func (p *PostgresService) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	webhook, err := scanWebhook(p.db.QueryRow(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	webhook = webhook.Redacted()
+	return &webhook, nil
+}
+
+// Как и в Mongo, включение webhook обнуляет счётчик ошибок.
+func (p *PostgresService) UpdateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	updated, err := scanWebhook(p.db.QueryRow(ctx, `UPDATE webhooks SET url = $2, active = $3, events = $4, updated_at = $5,
+			consecutive_failures = CASE WHEN $3 THEN 0 ELSE consecutive_failures END,
+			disabled_at = CASE WHEN $3 THEN NULL ELSE disabled_at END
+		WHERE id = $1 RETURNING `+webhookColumns,
+		webhook.ID, webhook.URL, webhook.Active, textArray(webhook.Events), time.Now().UTC().Truncate(time.Millisecond)))
+	if err != nil {
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, webhook.ID)
+		}
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	updated = updated.Redacted()
+	return &updated, nil
+}
+
+// Задачи удалённого webhook остаются в очереди и отбрасываются при выдаче.
+func (p *PostgresService) DeleteWebhook(ctx context.Context, id string) error {
+	result, err := p.db.Exec(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: webhook с ID %s не найден", errors.ErrWebhookNotFound, id)
+	}
+
+	if _, err := p.db.Exec(ctx, "DELETE FROM webhook_deliveries WHERE webhook_id = $1", id); err != nil {
+		fmt.Printf("Ошибка удаления журнала доставок webhook %s: %v\n", id, err)
+	}
+	return nil
+}
+
+func (p *PostgresService) GetWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	sql := `SELECT id, delivery FROM webhook_deliveries
+		WHERE webhook_id = $1 AND created_at >= $2
+		ORDER BY created_at DESC, id DESC`
+	if limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := p.db.Query(ctx, sql, webhookID, time.Now().Add(-webhookDeliveriesTTL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	result := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var id string
+		var raw []byte
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		}
+
+		var delivery models.WebhookDelivery
+		if err := json.Unmarshal(raw, &delivery); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		}
+		delivery.ID = id
+		result = append(result, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	return result, nil
+}
+
+// ClaimWebhookJobs выдаёт готовые к отправке доставки вместе с адресом и
+// секретом webhook. Задачи удалённых и отключённых webhook отбрасываются.
+func (p *PostgresService) ClaimWebhookJobs(ctx context.Context, now time.Time, limit int) ([]models.WebhookJob, error) {
+	jobs, err := p.webhookQueue.Claim(now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.WebhookID)
+	}
+
+	rows, err := p.db.Query(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = ANY($1)", ids)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]models.Webhook, len(ids))
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		}
+		byID[webhook.ID] = webhook
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+
+	claimed := make([]models.WebhookJob, 0, len(jobs))
+	for _, job := range jobs {
+		webhook, ok := byID[job.WebhookID]
+		if !ok || !webhook.Active {
+			if err := p.webhookQueue.Ack(job.ID); err != nil {
+				fmt.Printf("Ошибка удаления доставки webhook: %v\n", err)
+			}
+			continue
+		}
+		job.Webhook = webhook
+		claimed = append(claimed, job)
+	}
+
+	return claimed, nil
+}
+
+func (p *PostgresService) CompleteWebhookJob(ctx context.Context, job models.WebhookJob, delivery models.WebhookDelivery) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	retry := !delivery.Success && job.Attempt < p.webhookMaxAttempts
+
+	var err error
+	if delivery.Success {
+		_, err = p.db.Exec(ctx, "UPDATE webhooks SET consecutive_failures = 0, last_delivery_at = $2 WHERE id = $1",
+			job.WebhookID, now)
+	} else {
+		var failures int
+		err = p.db.QueryRow(ctx, `UPDATE webhooks SET consecutive_failures = consecutive_failures + 1, last_delivery_at = $2
+			WHERE id = $1 RETURNING consecutive_failures`, job.WebhookID, now).Scan(&failures)
+		if err == nil && p.webhookDisableAfter > 0 && failures >= p.webhookDisableAfter {
+			retry = false
+			if err := p.disableWebhook(ctx, job.WebhookID, now); err != nil {
+				fmt.Printf("Ошибка отключения webhook %s: %v\n", job.WebhookID, err)
+			}
+		}
+		if stdErrors.Is(err, pgx.ErrNoRows) {
+			retry = false
+			err = nil
+		}
+	}
+	if err != nil {
+		fmt.Printf("Ошибка обновления состояния webhook %s: %v\n", job.WebhookID, err)
+	}
+
+	if retry {
+		delivery.NextRetry = now.Add(webhooks.Backoff(job.Attempt))
+	}
+	if err := p.saveWebhookDelivery(ctx, delivery, now); err != nil {
+		fmt.Printf("Ошибка записи журнала доставки webhook %s: %v\n", job.WebhookID, err)
+	}
+
+	if !retry {
+		if err := p.webhookQueue.Ack(job.ID); err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+		}
+		return nil
+	}
+
+	job.Attempt++
+	job.Webhook = models.Webhook{}
+	if err := p.webhookQueue.Retry(job, delivery.NextRetry); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (p *PostgresService) disableWebhook(ctx context.Context, id string, now time.Time) error {
+	result, err := p.db.Exec(ctx, `UPDATE webhooks SET active = false, disabled_at = $2, updated_at = $2
+		WHERE id = $1 AND active`, id, now)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() > 0 {
+		fmt.Printf("Webhook %s отключён после %d ошибок доставки подряд\n", id, p.webhookDisableAfter)
+	}
+	return nil
+}
+
+func (p *PostgresService) saveWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery, now time.Time) error {
+	delivery.ID = primitive.NewObjectID().Hex()
+	deliveryJSON, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.db.Exec(ctx, "DELETE FROM webhook_deliveries WHERE created_at < $1", now.Add(-webhookDeliveriesTTL)); err != nil {
+		fmt.Printf("Ошибка удаления устаревшего журнала доставок webhook: %v\n", err)
+	}
+
+	_, err = p.db.Exec(ctx, "INSERT INTO webhook_deliveries (id, webhook_id, delivery, created_at) VALUES ($1, $2, $3, $4)",
+		delivery.ID, delivery.WebhookID, string(deliveryJSON), delivery.CreatedAt)
+	return err
+}
+
+// Доставки ставятся в очередь всем активным webhook аудитории события,
+// подписанным на его тип; заметка в событии урезается так же, как в SSE.
+func (p *PostgresService) enqueueWebhooks(event models.NoteEvent, audience []int) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookEnqueueTimeout)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, `SELECT id, author_id FROM webhooks
+		WHERE author_id = ANY($1) AND active AND (events = '{}' OR $2 = ANY(events))`, audience, event.Type)
+	if err != nil {
+		fmt.Printf("Ошибка поиска webhook для события %s: %v\n", event.Type, err)
+		return
+	}
+	defer rows.Close()
+
+	now := time.Now().UTC()
+	jobs := make([]models.WebhookJob, 0)
+	for rows.Next() {
+		var webhookID string
+		var authorID int
+		if err := rows.Scan(&webhookID, &authorID); err != nil {
+			fmt.Printf("Ошибка поиска webhook для события %s: %v\n", event.Type, err)
+			return
+		}
+		jobs = append(jobs, models.WebhookJob{
+			ID:         primitive.NewObjectID().Hex(),
+			WebhookID:  webhookID,
+			Attempt:    1,
+			Event:      event.VisibleTo(authorID),
+			EnqueuedAt: now,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Ошибка поиска webhook для события %s: %v\n", event.Type, err)
+		return
+	}
+
+	if err := p.webhookQueue.Enqueue(jobs...); err != nil {
+		fmt.Printf("Ошибка постановки webhook для заметки %s: %v\n", event.NoteID, err)
+	}
+}
+
+func scanWebhook(row pgx.Row) (models.Webhook, error) {
+	var webhook models.Webhook
+	var lastDeliveryAt, disabledAt *time.Time
+	err := row.Scan(&webhook.ID, &webhook.AuthorID, &webhook.URL, &webhook.Events, &webhook.Secret, &webhook.Active,
+		&webhook.ConsecutiveFailures, &lastDeliveryAt, &disabledAt, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return webhook, err
+	}
+
+	if len(webhook.Events) == 0 {
+		webhook.Events = nil
+	}
+	webhook.LastDeliveryAt = timeValue(lastDeliveryAt)
+	webhook.DisabledAt = timeValue(disabledAt)
+	webhook.CreatedAt = webhook.CreatedAt.UTC()
+	webhook.UpdatedAt = webhook.UpdatedAt.UTC()
+	return webhook, nil
+}
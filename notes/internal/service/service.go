@@ -2,14 +2,101 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"notes/internal/config"
+	"notes/internal/errors"
+	"notes/internal/events"
 	"notes/internal/models"
+	"time"
+)
+
+const (
+	BackendMongo    = "mongo"
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
 )
 
 type Service interface {
 	Close() error
 	Create(ctx context.Context, note models.Note) (*models.Note, error)
 	GetByID(ctx context.Context, id string) (*models.Note, error)
-	GetAll(ctx context.Context, authorId int) ([]models.Note, error)
+	GetAll(ctx context.Context, authorId int, query models.NoteQuery) (*models.NotesPage, error)
 	Update(ctx context.Context, note models.Note) (*models.Note, error)
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string, version int) error
+	Batch(ctx context.Context, authorId int, request models.BatchRequest) ([]models.BatchResult, error)
+	ExportNotes(ctx context.Context, authorId int, write func(models.Note) error) error
+	ImportNotes(ctx context.Context, authorId int, request models.ImportRequest) (*models.ImportJob, error)
+	GetImportJob(ctx context.Context, id string) (*models.ImportJob, error)
+	StopImports()
+	FailStaleImports(ctx context.Context, staleBefore time.Time) (int64, error)
+	SubscribeEvents(userID int) *events.Subscription
+	ReplayEvents(ctx context.Context, userID int, afterID int64) ([]models.NoteEvent, error)
+	Search(ctx context.Context, authorId int, query models.SearchQuery) ([]models.SearchResult, error)
+	GetTags(ctx context.Context, authorId int) ([]models.TagCount, error)
+	GetRevisions(ctx context.Context, noteID string) ([]models.NoteRevision, error)
+	GetRevision(ctx context.Context, noteID string, version int) (*models.NoteRevision, error)
+	RestoreRevision(ctx context.Context, noteID string, version int, expectedVersion int) (*models.Note, error)
+	GetTrash(ctx context.Context, authorId int) ([]models.Note, error)
+	GetTrashedByID(ctx context.Context, id string) (*models.Note, error)
+	RestoreFromTrash(ctx context.Context, id string) (*models.Note, error)
+	DeletePermanently(ctx context.Context, id string) error
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ShareNote(ctx context.Context, noteID string, share models.NoteShare) (*models.Note, error)
+	UnshareNote(ctx context.Context, noteID string, userID int) (*models.Note, error)
+	GetShared(ctx context.Context, userID int) ([]models.Note, error)
+	CreateLink(ctx context.Context, note models.Note, request models.CreateLinkRequest) (*models.ShareLink, error)
+	GetLinks(ctx context.Context, noteID string) ([]models.ShareLink, error)
+	RevokeLink(ctx context.Context, noteID string, token string) error
+	OpenLink(ctx context.Context, token string, password string) (*models.Note, *models.ShareLink, error)
+	UploadAttachment(ctx context.Context, note models.Note, filename string, contentType string, content io.Reader) (*models.Attachment, error)
+	GetAttachments(ctx context.Context, noteID string) ([]models.Attachment, error)
+	OpenAttachment(ctx context.Context, noteID string, attachmentID string) (*models.Attachment, io.ReadSeekCloser, error)
+	DeleteAttachment(ctx context.Context, noteID string, attachmentID string) error
+	CreateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error)
+	GetNotebooks(ctx context.Context, authorId int) ([]models.Notebook, error)
+	GetNotebook(ctx context.Context, id string) (*models.Notebook, error)
+	UpdateNotebook(ctx context.Context, notebook models.Notebook) (*models.Notebook, error)
+	DeleteNotebook(ctx context.Context, notebook models.Notebook, mode string) error
+	MoveNote(ctx context.Context, noteID string, notebookID string, version int) (*models.Note, error)
+	SetPinned(ctx context.Context, noteID string, pinned bool, version int) (*models.Note, error)
+	SetArchived(ctx context.Context, noteID string, archived bool, version int) (*models.Note, error)
+	SetReminder(ctx context.Context, noteID string, request models.ReminderRequest, version int) (*models.Note, error)
+	GetUpcomingReminders(ctx context.Context, authorId int, until time.Time, limit int) ([]models.Reminder, error)
+	ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]models.Reminder, error)
+	CompleteReminder(ctx context.Context, reminder models.Reminder) error
+	DeliverToInbox(ctx context.Context, reminder models.Reminder) error
+	GetInbox(ctx context.Context, userID int, limit int) ([]models.InboxItem, error)
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	GetWebhooks(ctx context.Context, authorId int) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	GetWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error)
+	ClaimWebhookJobs(ctx context.Context, now time.Time, limit int) ([]models.WebhookJob, error)
+	CompleteWebhookJob(ctx context.Context, job models.WebhookJob, delivery models.WebhookDelivery) error
+}
+
+// NewService выбирает хранилище по STORAGE_BACKEND: memory не требует ни
+// Mongo, ни Redis и подходит для тестов и локальной разработки, postgres
+// хранит всё в PostgreSQL и тоже обходится без Redis.
+func NewService(cfg *config.Config) (Service, error) {
+	switch cfg.StorageBackend {
+	case BackendMongo:
+		service, err := newMongoService(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return service, nil
+	case BackendMemory:
+		return NewMemoryService(cfg), nil
+	case BackendPostgres:
+		service, err := newPostgresService(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return service, nil
+	default:
+		return nil, fmt.Errorf("%w: неизвестное хранилище %q", errors.ErrServiceCreation, cfg.StorageBackend)
+	}
 }
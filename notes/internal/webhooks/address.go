@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const resolveTimeout = 5 * time.Second
+
+// CheckURL не даёт зарегистрировать webhook на внутренний адрес: хост
+// резолвится, и каждый его адрес должен быть публичным. При доставке адрес
+// проверяется ещё раз в dialControl, поэтому смена DNS-записи после
+// регистрации не помогает.
+func CheckURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес: %v", err)
+	}
+
+	host := parsed.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return checkAddr(addr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("не удалось найти хост %s: %v", host, err)
+	}
+	for _, addr := range addrs {
+		if err := checkAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	// CGNAT: на этих адресах бывают и служебные адреса облаков.
+	sharedPrefix = netip.MustParsePrefix("100.64.0.0/10")
+	// NAT64 (RFC 6052) и 6to4 (RFC 3056) несут IPv4-адрес внутри IPv6.
+	nat64Prefix      = netip.MustParsePrefix("64:ff9b::/96")
+	nat64LocalPrefix = netip.MustParsePrefix("64:ff9b:1::/48")
+	sixToFourPrefix  = netip.MustParsePrefix("2002::/16")
+)
+
+func checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() ||
+		sharedPrefix.Contains(addr) || nat64LocalPrefix.Contains(addr) {
+		return fmt.Errorf("адрес %s недоступен для webhook: разрешены только публичные адреса", addr)
+	}
+
+	// Встроенный IPv4 проверяется по тем же правилам: 64:ff9b::7f00:1 ведёт
+	// на 127.0.0.1.
+	raw := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return checkEmbedded(addr, netip.AddrFrom4([4]byte(raw[12:16])))
+	case sixToFourPrefix.Contains(addr):
+		return checkEmbedded(addr, netip.AddrFrom4([4]byte(raw[2:6])))
+	}
+	return nil
+}
+
+func checkEmbedded(addr, embedded netip.Addr) error {
+	if err := checkAddr(embedded); err != nil {
+		return fmt.Errorf("адрес %s недоступен для webhook: внутри него внутренний адрес %s", addr, embedded)
+	}
+	return nil
+}
+
+// dialControl проверяет уже выбранный IP перед соединением.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес %s: %v", address, err)
+	}
+	return checkAddr(addrPort.Addr())
+}
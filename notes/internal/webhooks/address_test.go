@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"notes/internal/models"
+	"testing"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://93.184.216.34/hook", true},
+		{"http://[2606:4700::1111]/hook", true},
+		{"http://127.0.0.1:6379/", false},
+		{"http://localhost:27017/", false},
+		{"http://10.0.0.5/", false},
+		{"http://172.16.3.4/", false},
+		{"http://192.168.1.1/", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://0.0.0.0:8080/", false},
+		{"http://[::1]/", false},
+		{"http://[fd00::1]/", false},
+		{"http://[::ffff:127.0.0.1]/", false},
+		{"http://100.64.0.1/", false},
+		{"http://100.127.255.254/", false},
+		{"http://100.128.0.1/", true},
+		{"http://[64:ff9b::7f00:1]/", false},
+		{"http://[64:ff9b::a9fe:a9fe]/", false},
+		{"http://[64:ff9b::5db8:d822]/", true},
+		{"http://[64:ff9b:1::a00:5]/", false},
+		{"http://[2002:7f00:1::]/", false},
+		{"http://[2002:a00:5::1]/", false},
+		{"http://[2002:5db8:d822::1]/", true},
+	}
+	for _, test := range tests {
+		err := CheckURL(test.url)
+		if allowed := err == nil; allowed != test.allowed {
+			t.Errorf("CheckURL(%q) = %v, ожидалось разрешено=%v", test.url, err, test.allowed)
+		}
+	}
+}
+
+// Даже если адрес прошёл проверку при регистрации, доставка на внутренний
+// IP блокируется при соединении.
+func TestSenderRefusesInternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	delivery := NewSender().Deliver(context.Background(), models.WebhookJob{
+		ID:      "job",
+		Attempt: 1,
+		Webhook: models.Webhook{URL: server.URL, Secret: "secret"},
+	})
+	if delivery.Success || called {
+		t.Fatalf("доставка на %s прошла, ожидался отказ", server.URL)
+	}
+	if delivery.Error == "" {
+		t.Fatal("в журнале доставки нет ошибки")
+	}
+}
@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"notes/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryQueue — очередь доставок в памяти процесса с теми же правилами
+// выдачи, что и Queue: взятая и не подтверждённая задача возвращается в
+// очередь через visibilityTimeout. Задачи не переживают перезапуск.
+type MemoryQueue struct {
+	mu   sync.Mutex
+	jobs map[string]*memoryJob
+}
+
+type memoryJob struct {
+	job   models.WebhookJob
+	due   time.Time
+	lease time.Time
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{jobs: make(map[string]*memoryJob)}
+}
+
+func (q *MemoryQueue) Enqueue(jobs ...models.WebhookJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range jobs {
+		q.jobs[job.ID] = &memoryJob{job: job, due: job.EnqueuedAt}
+	}
+	return nil
+}
+
+func (q *MemoryQueue) Claim(now time.Time, limit int) ([]models.WebhookJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ready := make([]*memoryJob, 0)
+	for _, queued := range q.jobs {
+		if !queued.lease.IsZero() && queued.lease.After(now) {
+			continue
+		}
+		if !queued.lease.IsZero() {
+			queued.due = now
+			queued.lease = time.Time{}
+		}
+		if !queued.due.After(now) {
+			ready = append(ready, queued)
+		}
+	}
+
+	slices.SortFunc(ready, func(a, b *memoryJob) int {
+		return a.due.Compare(b.due)
+	})
+	if len(ready) > limit {
+		ready = ready[:limit]
+	}
+
+	jobs := make([]models.WebhookJob, 0, len(ready))
+	for _, queued := range ready {
+		queued.lease = now.Add(visibilityTimeout)
+		jobs = append(jobs, queued.job)
+	}
+	return jobs, nil
+}
+
+func (q *MemoryQueue) Ack(jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.jobs, jobID)
+	return nil
+}
+
+func (q *MemoryQueue) Retry(job models.WebhookJob, at time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs[job.ID] = &memoryJob{job: job, due: at}
+	return nil
+}
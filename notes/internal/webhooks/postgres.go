@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"notes/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresQueueTimeout = 5 * time.Second
+
+// Готовые задачи забираются через FOR UPDATE SKIP LOCKED и сразу
+// откладываются на время аренды, поэтому одну задачу не заберут две реплики.
+const claimQuery = `
+UPDATE webhook_jobs SET visible_at = $3
+WHERE id IN (
+	SELECT id FROM webhook_jobs
+	WHERE visible_at <= $1
+	ORDER BY visible_at
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING job`
+
+// PostgresQueue — очередь доставок в таблице webhook_jobs с теми же
+// правилами, что и Queue: задача видна с момента visible_at, а взятая и не
+// подтверждённая возвращается в очередь через visibilityTimeout.
+type PostgresQueue struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresQueue(pool *pgxpool.Pool) *PostgresQueue {
+	return &PostgresQueue{pool: pool}
+}
+
+func (q *PostgresQueue) Enqueue(jobs ...models.WebhookJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueueTimeout)
+	defer cancel()
+
+	batch := &pgx.Batch{}
+	for _, job := range jobs {
+		jobJSON, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		batch.Queue("INSERT INTO webhook_jobs (id, job, visible_at) VALUES ($1, $2, $3)", job.ID, string(jobJSON), job.EnqueuedAt)
+	}
+
+	if err := q.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("не удалось поставить доставки webhook в очередь: %w", err)
+	}
+	return nil
+}
+
+func (q *PostgresQueue) Claim(now time.Time, limit int) ([]models.WebhookJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueueTimeout)
+	defer cancel()
+
+	rows, err := q.pool.Query(ctx, claimQuery, now, limit, now.Add(visibilityTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить доставки webhook из очереди: %w", err)
+	}
+
+	raws, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить доставки webhook из очереди: %w", err)
+	}
+
+	jobs := make([]models.WebhookJob, 0, len(raws))
+	for _, raw := range raws {
+		var job models.WebhookJob
+		if err := json.Unmarshal(raw, &job); err != nil {
+			fmt.Printf("Ошибка разбора доставки webhook: %v\n", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Ack удаляет задачу из очереди: доставка прошла или попытки кончились.
+func (q *PostgresQueue) Ack(jobID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueueTimeout)
+	defer cancel()
+
+	if _, err := q.pool.Exec(ctx, "DELETE FROM webhook_jobs WHERE id = $1", jobID); err != nil {
+		return fmt.Errorf("не удалось подтвердить доставку webhook %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// Retry возвращает задачу в очередь с новым номером попытки на время at.
+func (q *PostgresQueue) Retry(job models.WebhookJob, at time.Time) error {
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueueTimeout)
+	defer cancel()
+
+	_, err = q.pool.Exec(ctx, `INSERT INTO webhook_jobs (id, job, visible_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET job = excluded.job, visible_at = excluded.visible_at`,
+		job.ID, string(jobJSON), at)
+	if err != nil {
+		return fmt.Errorf("не удалось запланировать повтор доставки webhook %s: %w", job.ID, err)
+	}
+	return nil
+}
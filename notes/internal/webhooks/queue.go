@@ -0,0 +1,215 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"notes/internal/caching"
+	"notes/internal/models"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	queueKey      = "notes:webhooks:queue"
+	processingKey = "notes:webhooks:processing"
+	jobsKey       = "notes:webhooks:jobs"
+
+	// Задача, которую реплика взяла и не подтвердила за это время (упала
+	// посреди доставки), возвращается в очередь.
+	visibilityTimeout = time.Minute
+
+	// Столько задач копится в памяти, пока Redis недоступен; сверх этого
+	// самые старые отбрасываются.
+	maxPendingJobs = 10000
+)
+
+// Задачи с истёкшей арендой возвращаются в очередь, затем готовые к отправке
+// переносятся в processing с новым сроком аренды — атомарно, поэтому одну
+// задачу не заберут две реплики.
+var claimScript = redis.NewScript(`
+local expired = redis.call("zrangebyscore", KEYS[2], "-inf", ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call("zrem", KEYS[2], id)
+	redis.call("zadd", KEYS[1], ARGV[1], id)
+end
+local ids = redis.call("zrangebyscore", KEYS[1], "-inf", ARGV[1], "limit", 0, tonumber(ARGV[2]))
+local jobs = {}
+for _, id in ipairs(ids) do
+	redis.call("zrem", KEYS[1], id)
+	local job = redis.call("hget", KEYS[3], id)
+	if job then
+		redis.call("zadd", KEYS[2], ARGV[3], id)
+		table.insert(jobs, job)
+	end
+end
+return jobs`)
+
+// Queue — очередь доставок webhook в Redis: тела задач лежат в хеше, а
+// время следующей попытки — в отсортированном множестве, поэтому задачи
+// переживают перезапуск сервиса.
+//
+// Enqueue вызывается при изменении заметки и не должен ждать недоступный
+// Redis: пока он помечен недоступным, задачи копятся в памяти реплики и
+// переносятся в Redis при первом Claim после восстановления.
+type Queue struct {
+	client *redis.Client
+	health *caching.RedisHealth
+
+	mu      sync.Mutex
+	pending []models.WebhookJob
+}
+
+func NewQueue(client *redis.Client, health *caching.RedisHealth) *Queue {
+	return &Queue{client: client, health: health}
+}
+
+func (q *Queue) Enqueue(jobs ...models.WebhookJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	if !q.health.Up() {
+		q.hold(jobs)
+		return nil
+	}
+
+	if err := q.push(jobs); err != nil {
+		q.health.Report(err)
+		q.hold(jobs)
+		fmt.Printf("Доставки webhook отложены до восстановления Redis: %v\n", err)
+	}
+	return nil
+}
+
+func (q *Queue) hold(jobs []models.WebhookJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = append(q.pending, jobs...)
+	if dropped := len(q.pending) - maxPendingJobs; dropped > 0 {
+		fmt.Printf("Очередь webhook в памяти переполнена, отброшено доставок: %d\n", dropped)
+		q.pending = append([]models.WebhookJob(nil), q.pending[dropped:]...)
+	}
+}
+
+// flushPending переносит отложенные задачи в Redis.
+func (q *Queue) flushPending() error {
+	q.mu.Lock()
+	jobs := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+
+	if len(jobs) == 0 {
+		return nil
+	}
+	if err := q.push(jobs); err != nil {
+		q.mu.Lock()
+		q.pending = append(jobs, q.pending...)
+		q.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (q *Queue) push(jobs []models.WebhookJob) error {
+	_, err := q.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, job := range jobs {
+			jobJSON, err := json.Marshal(job)
+			if err != nil {
+				return err
+			}
+			pipe.HSet(jobsKey, job.ID, jobJSON)
+			pipe.ZAdd(queueKey, redis.Z{Score: score(job.EnqueuedAt), Member: job.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("не удалось поставить доставки webhook в очередь: %w", err)
+	}
+	return nil
+}
+
+// Claim ничего не выдаёт, пока Redis недоступен: доставки подождут.
+func (q *Queue) Claim(now time.Time, limit int) ([]models.WebhookJob, error) {
+	if !q.health.Up() {
+		return nil, nil
+	}
+	if err := q.flushPending(); err != nil {
+		q.health.Report(err)
+		return nil, err
+	}
+
+	result, err := claimScript.Run(q.client,
+		[]string{queueKey, processingKey, jobsKey},
+		strconv.FormatFloat(score(now), 'f', 0, 64),
+		limit,
+		strconv.FormatFloat(score(now.Add(visibilityTimeout)), 'f', 0, 64),
+	).Result()
+	if err != nil {
+		q.health.Report(err)
+		return nil, fmt.Errorf("не удалось получить доставки webhook из очереди: %w", err)
+	}
+
+	values, _ := result.([]any)
+	jobs := make([]models.WebhookJob, 0, len(values))
+	for _, value := range values {
+		raw, _ := value.(string)
+
+		var job models.WebhookJob
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			fmt.Printf("Ошибка разбора доставки webhook: %v\n", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Ack удаляет задачу из очереди: доставка прошла или попытки кончились.
+func (q *Queue) Ack(jobID string) error {
+	if err := q.health.Check(); err != nil {
+		return fmt.Errorf("не удалось подтвердить доставку webhook %s: %w", jobID, err)
+	}
+
+	_, err := q.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.ZRem(processingKey, jobID)
+		pipe.HDel(jobsKey, jobID)
+		return nil
+	})
+	if err != nil {
+		q.health.Report(err)
+		return fmt.Errorf("не удалось подтвердить доставку webhook %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// Retry возвращает задачу в очередь с новым номером попытки на время at.
+func (q *Queue) Retry(job models.WebhookJob, at time.Time) error {
+	if err := q.health.Check(); err != nil {
+		return fmt.Errorf("не удалось запланировать повтор доставки webhook %s: %w", job.ID, err)
+	}
+
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.ZRem(processingKey, job.ID)
+		pipe.HSet(jobsKey, job.ID, jobJSON)
+		pipe.ZAdd(queueKey, redis.Z{Score: score(at), Member: job.ID})
+		return nil
+	})
+	if err != nil {
+		q.health.Report(err)
+		return fmt.Errorf("не удалось запланировать повтор доставки webhook %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
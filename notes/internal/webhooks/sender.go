@@ -0,0 +1,144 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"notes/internal/models"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Notes-Event"
+	HeaderDelivery  = "X-Notes-Delivery"
+	HeaderTimestamp = "X-Notes-Timestamp"
+	HeaderSignature = "X-Notes-Signature"
+
+	deliveryTimeout  = 10 * time.Second
+	maxResponseBytes = 64 << 10
+
+	backoffBase = 10 * time.Second
+	backoffMax  = time.Hour
+)
+
+type payload struct {
+	DeliveryID string           `json:"delivery_id"`
+	WebhookID  string           `json:"webhook_id"`
+	Event      models.NoteEvent `json:"event"`
+}
+
+// Sender отправляет события на адреса webhook. Перенаправления не
+// выполняются: ответ 3xx считается ошибкой доставки. Соединения с
+// внутренними адресами запрещены, прокси из окружения не используется.
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender() *Sender {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: dialControl,
+	}
+
+	return &Sender{
+		client: &http.Client{
+			Timeout: deliveryTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: deliveryTimeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Deliver выполняет одну попытку доставки и возвращает запись для журнала.
+func (s *Sender) Deliver(ctx context.Context, job models.WebhookJob) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		WebhookID: job.WebhookID,
+		JobID:     job.ID,
+		EventID:   job.Event.ID,
+		EventType: job.Event.Type,
+		NoteID:    job.Event.NoteID,
+		Attempt:   job.Attempt,
+	}
+
+	started := time.Now()
+	statusCode, err := s.send(ctx, job)
+	delivery.DurationMS = time.Since(started).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	delivery.Success = true
+	return delivery
+}
+
+func (s *Sender) send(ctx context.Context, job models.WebhookJob) (int, error) {
+	body, err := json.Marshal(payload{DeliveryID: job.ID, WebhookID: job.WebhookID, Event: job.Event})
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, job.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "notes-webhooks/1")
+	request.Header.Set(HeaderEvent, job.Event.Type)
+	request.Header.Set(HeaderDelivery, job.ID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(job.Webhook.Secret, timestamp, body))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("адрес недоступен: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBytes))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("адрес ответил %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign считает подпись "sha256=<hex>" от строки "<timestamp>.<тело>":
+// получатель сверяет её и отбрасывает запросы со старым timestamp, чтобы
+// перехваченную доставку нельзя было повторить.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff — пауза перед попыткой attempt+1: 10с, 20с, 40с… не больше часа,
+// с разбросом до 20%, чтобы повторы к одному адресу не шли пачкой.
+func Backoff(attempt int) time.Duration {
+	delay := backoffMax
+	if attempt >= 1 && attempt <= 12 {
+		delay = min(backoffBase<<(attempt-1), backoffMax)
+	}
+	return delay + rand.N(delay/5+1)
+}